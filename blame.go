@@ -0,0 +1,134 @@
+package recovercheck
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlameSource reports when a line of a file was last changed
+type BlameSource interface {
+	LineTime(filename string, line int) (time.Time, error)
+}
+
+// GitBlame is a BlameSource backed by git blame. Each file is blamed once
+// and the result is cached for the lifetime of the GitBlame. Files are
+// blamed outside the shared lock, so that passes blaming different files
+// don't wait for each other's git processes.
+type GitBlame struct {
+	mu    sync.Mutex
+	files map[string]*blamedFile
+}
+
+// blamedFile is the cached git blame of a file
+type blamedFile struct {
+	once  sync.Once // blames the file, outside the shared lock
+	lines map[int]time.Time
+	err   error
+}
+
+// defaultBlame is shared by all passes when no BlameSource is configured
+var defaultBlame = &GitBlame{}
+
+// LineTime returns the committer time of the commit that last changed line.
+// Uncommitted lines are reported by git with the current time.
+func (g *GitBlame) LineTime(filename string, line int) (time.Time, error) {
+	g.mu.Lock()
+	if g.files == nil {
+		g.files = make(map[string]*blamedFile)
+	}
+	file, ok := g.files[filename]
+	if !ok {
+		file = &blamedFile{}
+		g.files[filename] = file
+	}
+	g.mu.Unlock()
+
+	file.once.Do(func() {
+		file.lines, file.err = blameFile(filename)
+	})
+	if file.err != nil {
+		return time.Time{}, file.err
+	}
+
+	t, ok := file.lines[line]
+	if !ok {
+		return time.Time{}, fmt.Errorf("no blame information for %s:%d", filename, line)
+	}
+	return t, nil
+}
+
+// blameFile runs git blame on filename and maps each line to its commit time
+func blameFile(filename string) (map[int]time.Time, error) {
+	cmd := exec.Command("git", "blame", "--porcelain", "--", filepath.Base(filename))
+	cmd.Dir = filepath.Dir(filename)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s: %w", filename, err)
+	}
+	return parseBlamePorcelain(out)
+}
+
+// parseBlamePorcelain parses git blame --porcelain output. Each group of
+// lines starts with "<sha> <orig> <final> [<count>]"; commit headers such as
+// committer-time are only printed the first time a commit appears.
+func parseBlamePorcelain(out []byte) (map[int]time.Time, error) {
+	lines := make(map[int]time.Time)
+	commitTimes := make(map[string]time.Time)
+	lineCommits := make(map[int]string)
+
+	var sha string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			// Line content
+		case strings.HasPrefix(text, "committer-time "):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(text, "committer-time "), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid committer-time %q: %w", text, err)
+			}
+			commitTimes[sha] = time.Unix(seconds, 0)
+		default:
+			fields := strings.Fields(text)
+			if len(fields) >= 3 && isCommitHash(fields[0]) {
+				final, err := strconv.Atoi(fields[2])
+				if err != nil {
+					continue
+				}
+				sha = fields[0]
+				lineCommits[final] = sha
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for line, commit := range lineCommits {
+		lines[line] = commitTimes[commit]
+	}
+	return lines, nil
+}
+
+// isCommitHash checks if s looks like a full SHA-1 or SHA-256 commit hash
+func isCommitHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package recovercheck
+
+import (
+	"go/ast"
+	"go/build"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// buildDecls drops the declarations of functions declared more than once
+// whose files are excluded from the build, such as handlePanic in both
+// recover_linux.go and recover_windows.go. Drivers that load packages for a
+// build never pass both, but those that hand over every file of a directory
+// would otherwise key whichever declaration came last. Functions declared
+// once, and those with no declaration in the build, are kept as they are.
+func (r *Analyzer) buildDecls(functions []*ast.FuncDecl) []*ast.FuncDecl {
+	byKey := make(map[string][]*ast.FuncDecl)
+	for _, funcDecl := range functions {
+		if funcDecl.Name != nil {
+			key := funcDeclKey(funcDecl)
+			byKey[key] = append(byKey[key], funcDecl)
+		}
+	}
+
+	excluded := make(map[*ast.FuncDecl]bool)
+	for _, decls := range byKey {
+		if len(decls) < 2 {
+			continue
+		}
+		var out []*ast.FuncDecl
+		for _, funcDecl := range decls {
+			if !r.inBuild(funcDecl) {
+				out = append(out, funcDecl)
+			}
+		}
+		if len(out) == len(decls) {
+			continue
+		}
+		for _, funcDecl := range out {
+			excluded[funcDecl] = true
+		}
+	}
+
+	if len(excluded) == 0 {
+		return functions
+	}
+	var kept []*ast.FuncDecl
+	for _, funcDecl := range functions {
+		if !excluded[funcDecl] {
+			kept = append(kept, funcDecl)
+		}
+	}
+	return kept
+}
+
+// inBuild checks if the file declaring funcDecl is included in a build for
+// the default build context: the GOOS, GOARCH and cgo setting of the
+// environment. Both _GOOS and _GOARCH file name suffixes and //go:build
+// lines are honored. Declarations whose file isn't known are included.
+func (r *Analyzer) inBuild(funcDecl *ast.FuncDecl) bool {
+	var file *ast.File
+	for _, f := range r.Pass.Files {
+		if f.FileStart <= funcDecl.Pos() && funcDecl.Pos() <= f.FileEnd {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return true
+	}
+
+	// MatchFile only reads the header before the package clause, so give it
+	// the file's leading comments instead of reading the file from disk
+	var header strings.Builder
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			header.WriteString(comment.Text + "\n")
+		}
+		header.WriteString("\n")
+	}
+	header.WriteString("package " + file.Name.Name + "\n")
+
+	ctxt := build.Default
+	ctxt.OpenFile = func(string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(header.String())), nil
+	}
+	filename := r.Pass.Fset.Position(file.Package).Filename
+	match, err := ctxt.MatchFile(filepath.Dir(filename), filepath.Base(filename))
+	return err != nil || match
+}
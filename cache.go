@@ -0,0 +1,222 @@
+package recovercheck
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// cacheVersion is part of every cache key, so results written by a version
+// of the analyzer that classified functions differently aren't reused
+const cacheVersion = 2
+
+// cachedFile holds the results cached on disk for a file of another
+// package, see CacheDir. A nil cachedFile records that the file can't be
+// cached, because it can't be read or differs from the loaded source.
+type cachedFile struct {
+	path    string          // cache file the results are stored in
+	results map[string]bool // funcName@offset -> recovers
+}
+
+// recoversAt checks if the function fn contains recovery logic, consulting
+// the results cache for files of other packages. found is false if its
+// declaration can't be found or has no body. Only results decided by the
+// function's own body are cached, see containsRecoverLexically.
+func (r *Analyzer) recoversAt(fn *types.Func) (recovers, found bool) {
+	funcName, pos := fn.Name(), fn.Pos()
+	cached := r.cachedFileAt(pos)
+	key := fmt.Sprintf("%s@%d", funcName, r.Pass.Fset.Position(pos).Offset)
+	if cached != nil {
+		r.lockShared()
+		recovers, found = cached.results[key]
+		r.unlockShared()
+		if found {
+			r.debugf(token.NoPos, "%s resolved from cache: recovers=%v", funcName, recovers)
+			return recovers, true
+		}
+	}
+
+	funcDecl := r.findFuncDecl(funcName, pos)
+	if funcDecl == nil || funcDecl.Body == nil {
+		return false, false
+	}
+	recovers, lexical := r.containsRecoverLexically(funcDecl.Body, fn.Pkg())
+	if cached != nil && lexical {
+		r.storeCachedFile(cached, key, recovers)
+	}
+	return recovers, true
+}
+
+// containsRecoverLexically is containsRecover for the body of a function of
+// package pkg, also reporting if the result was decided by body alone. A
+// result that resolved a deferred helper or a helper that re-panics is not:
+// the helper may be declared in another file, or be resolved through the
+// state of the analyzing package, so that the result depends on the package
+// that computed it.
+func (r *Analyzer) containsRecoverLexically(body *ast.BlockStmt, pkg *types.Package) (recovers, lexical bool) {
+	finder := r.recoverFinder()
+	if pkg != nil && pkg != r.Pass.Pkg {
+		finder.resolve = r.packageFuncResolver(pkg, make(map[*types.Func]bool))
+	}
+	lexical = true
+	resolve, rethrows := finder.resolve, finder.rethrows
+	finder.resolve = func(fun ast.Expr) bool {
+		lexical = false
+		return resolve(fun)
+	}
+	finder.rethrows = func(call *ast.CallExpr) string {
+		lexical = false
+		return rethrows(call)
+	}
+	return finder.containsRecover(body), lexical
+}
+
+// cachedFileAt returns the cached results for the file of another package
+// containing pos, loading them from CacheDir once per pass. It returns nil
+// without CacheDir, for files of the pass and for files that can't be
+// cached.
+func (r *Analyzer) cachedFileAt(pos token.Pos) *cachedFile {
+	if r.Settings == nil || r.Settings.CacheDir == "" {
+		return nil
+	}
+	for _, file := range r.Pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return nil
+		}
+	}
+	position := r.Pass.Fset.Position(pos)
+	if !position.IsValid() || position.Filename == "" {
+		return nil
+	}
+
+	r.lockShared()
+	defer r.unlockShared()
+
+	if cached, ok := r.cachedFiles[position.Filename]; ok {
+		return cached
+	}
+	if r.cachedFiles == nil {
+		r.cachedFiles = make(map[string]*cachedFile)
+	}
+	cached := r.loadCachedFile(position.Filename, r.Pass.Fset.File(pos))
+	r.cachedFiles[position.Filename] = cached
+	return cached
+}
+
+// loadCachedFile reads the results cached for filename, keyed by a hash of
+// its contents and of the settings, see writeCacheSettings, so editing the
+// file or changing a setting invalidates them. Results depending on other
+// files are never cached, see containsRecoverLexically.
+func (r *Analyzer) loadCachedFile(filename string, loaded *token.File) *cachedFile {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		r.debugf(token.NoPos, "%s can't be cached: %v", filename, err)
+		return nil
+	}
+	// As in syntaxAt, a file on disk differing from the loaded source, as
+	// with an overlay, has offsets that don't match
+	if loaded != nil && loaded.Size() != len(src) {
+		r.debugf(token.NoPos, "%s on disk differs from the loaded source", filename)
+		return nil
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "recovercheck %d\n", cacheVersion)
+	writeCacheSettings(hash, r.Settings)
+	hash.Write(src)
+
+	cached := &cachedFile{
+		path:    filepath.Join(r.Settings.CacheDir, hex.EncodeToString(hash.Sum(nil))+".json"),
+		results: make(map[string]bool),
+	}
+	data, err := os.ReadFile(cached.path)
+	if err != nil {
+		return cached
+	}
+	if err := json.Unmarshal(data, &cached.results); err != nil {
+		// A corrupt entry is rewritten with the next result
+		r.debugf(token.NoPos, "ignoring cache entry %s: %v", cached.path, err)
+		cached.results = make(map[string]bool)
+	}
+	return cached
+}
+
+// uncachedSettings names the settings that can't affect how a function is
+// classified, since they only configure output or how the analysis runs
+var uncachedSettings = map[string]bool{
+	"Blame":       true,
+	"CacheDir":    true,
+	"Coverage":    true,
+	"Debug":       true,
+	"DebugOutput": true,
+	"Summary":     true,
+	"Workers":     true,
+}
+
+// writeCacheSettings writes every setting but the uncachedSettings to w, so
+// a setting added later invalidates the cache unless it is listed there
+func writeCacheSettings(w io.Writer, s *RecovercheckSettings) {
+	if s == nil {
+		s = &RecovercheckSettings{}
+	}
+	v := reflect.ValueOf(*s)
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if field.IsExported() && !uncachedSettings[field.Name] {
+			fmt.Fprintf(w, "%s=%#v\n", field.Name, v.Field(i).Interface())
+		}
+	}
+}
+
+// storeCachedFile records a result and writes the file's results to the
+// cache. The file is replaced atomically, so concurrent runs sharing
+// CacheDir read either version; one of them may lose the other's results,
+// which are then computed again. Errors are logged and otherwise ignored.
+func (r *Analyzer) storeCachedFile(cached *cachedFile, key string, recovers bool) {
+	r.lockShared()
+	defer r.unlockShared()
+
+	cached.results[key] = recovers
+	data, err := json.Marshal(cached.results)
+	if err == nil {
+		err = writeFileAtomic(cached.path, data)
+	}
+	if err != nil {
+		r.debugf(token.NoPos, "writing cache entry %s: %v", cached.path, err)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+// baselineHeader starts every baseline file written by -write-baseline, as
+// a comment in either format
+const baselineHeader = "# recovercheck baseline: known findings suppressed by -baseline\n"
+
+// diagnosticKey identifies a diagnostic across the actions of a graph
+type diagnosticKey struct {
+	pos     token.Position
+	message string
+}
+
+// finding is a diagnostic identified independently of its line, so that a
+// baseline keeps matching it when code above it moves
+type finding struct {
+	key      diagnosticKey
+	file     string // path relative to the baseline's directory, with forward slashes
+	function string // enclosing function declaration, such as Func or Type.Method
+	category string // kind of goroutine reported, such as goroutine or errgroup
+	message  string
+	fixes    []analysis.SuggestedFix
+	fset     *token.FileSet // positions of the fixes
+}
+
+// hash returns the baseline key of the finding: a digest of its file,
+// enclosing function and message
+func (f finding) hash() string {
+	sum := sha256.Sum256([]byte(f.file + "\x00" + f.function + "\x00" + f.message))
+	return hex.EncodeToString(sum[:8])
+}
+
+// baselineFindings returns the diagnostics of graph as findings in file and
+// line order, with paths relative to dir. Diagnostics shared by a package and
+// its test variant are returned once.
+func baselineFindings(graph *checker.Graph, dir string) []finding {
+	var findings []finding
+	seen := make(map[diagnosticKey]bool)
+	for _, act := range graph.Roots {
+		for _, diagnostic := range act.Diagnostics {
+			key := diagnosticKey{act.Package.Fset.Position(diagnostic.Pos), diagnostic.Message}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			findings = append(findings, finding{
+				key:      key,
+				file:     relativePath(dir, key.pos.Filename),
+				function: baselineFuncName(act.Package.Syntax, diagnostic.Pos),
+				category: diagnostic.Category,
+				message:  diagnostic.Message,
+				fixes:    diagnostic.SuggestedFixes,
+				fset:     act.Package.Fset,
+			})
+		}
+	}
+
+	slices.SortFunc(findings, func(a, b finding) int {
+		return cmp.Or(cmp.Compare(a.file, b.file), cmp.Compare(a.key.pos.Offset, b.key.pos.Offset), strings.Compare(a.message, b.message))
+	})
+	return findings
+}
+
+// relativePath returns the path of file relative to dir, with forward
+// slashes, or file itself if it can't be made relative
+func relativePath(dir, file string) string {
+	if rel, err := filepath.Rel(dir, file); err == nil {
+		file = rel
+	}
+	return filepath.ToSlash(file)
+}
+
+// baselineFuncName names the function declaration in files containing pos:
+// Func for functions and Type.Method for methods. It returns "" at package
+// level. Unlike the full names -include-func-regex matches, the package
+// path is left out: the finding's file already locates the package, and
+// the key then survives a change of module path or a vendored copy.
+func baselineFuncName(files []*ast.File, pos token.Pos) string {
+	for _, file := range files {
+		if pos < file.FileStart || pos > file.FileEnd {
+			continue
+		}
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || pos < funcDecl.Pos() || pos >= funcDecl.End() {
+				continue
+			}
+			if funcDecl.Recv == nil || len(funcDecl.Recv.List) != 1 {
+				return funcDecl.Name.Name
+			}
+			return receiverTypeName(funcDecl.Recv.List[0].Type) + "." + funcDecl.Name.Name
+		}
+	}
+	return ""
+}
+
+// receiverTypeName returns the name of a method's receiver type, without
+// pointer or type parameters
+func receiverTypeName(recv ast.Expr) string {
+	for {
+		switch e := recv.(type) {
+		case *ast.ParenExpr:
+			recv = e.X
+		case *ast.StarExpr:
+			recv = e.X
+		case *ast.IndexExpr:
+			recv = e.X
+		case *ast.IndexListExpr:
+			recv = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// Baseline file formats accepted by -baseline-format
+const (
+	// baselineNative lists the hash of each finding, see finding.hash
+	baselineNative = "native"
+	// baselineGolangci holds golangci-lint exclusion rules matching the
+	// file and message of each finding, which can be merged into the
+	// linters.exclusions.rules of a golangci-lint configuration
+	baselineGolangci = "golangci"
+)
+
+// knownFindings are the findings listed in a baseline file
+type knownFindings interface {
+	// suppress checks if f is a known finding, consuming the entry it
+	// matches if entries match a single finding each
+	suppress(f finding) bool
+}
+
+// readBaseline reads the baseline file at path in the given format
+func readBaseline(path, format string) (knownFindings, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if format == baselineGolangci {
+		return readExclusionRules(f)
+	}
+	return readHashes(f)
+}
+
+// writeBaseline writes the findings of graph to path in the given format
+func writeBaseline(path, format string, graph *checker.Graph) error {
+	dir, err := baselineDir(path)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(baselineHeader)
+	findings := baselineFindings(graph, dir)
+	if format == baselineGolangci {
+		writeExclusionRules(&b, findings)
+	} else {
+		writeHashes(&b, findings)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// baselineHashes are the findings of a native baseline file, counting
+// repeats so that a function with two known findings of the same message
+// only suppresses two
+type baselineHashes map[string]int
+
+func (h baselineHashes) suppress(f finding) bool {
+	hash := f.hash()
+	if h[hash] == 0 {
+		return false
+	}
+	h[hash]--
+	return true
+}
+
+// readHashes reads the hashes of a native baseline file
+func readHashes(r io.Reader) (baselineHashes, error) {
+	hashes := make(baselineHashes)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(line, " ")
+		hashes[hash]++
+	}
+	return hashes, scanner.Err()
+}
+
+// writeHashes writes findings one per line as the hash followed by the file,
+// line and message for readers; only the hash is matched
+func writeHashes(w io.Writer, findings []finding) {
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s %s:%d: %s\n", f.hash(), f.file, f.key.pos.Line, f.message)
+	}
+}
+
+// exclusionRule is a golangci-lint exclusion rule: it matches the findings
+// whose file matches path and whose message matches text, a nil pattern
+// matching any
+type exclusionRule struct {
+	path, text *regexp.Regexp
+	linters    []string // linters the rule applies to, all if empty
+}
+
+// exclusionRules are the findings of a golangci baseline file. As with
+// golangci-lint, a rule suppresses every finding it matches, so findings
+// are only told apart by file and message.
+type exclusionRules []exclusionRule
+
+func (rules exclusionRules) suppress(f finding) bool {
+	for _, rule := range rules {
+		if len(rule.linters) > 0 && !slices.Contains(rule.linters, "recovercheck") {
+			continue
+		}
+		if (rule.path == nil || rule.path.MatchString(f.file)) && (rule.text == nil || rule.text.MatchString(f.message)) {
+			return true
+		}
+	}
+	return false
+}
+
+// readExclusionRules reads the rules of a golangci baseline file. It accepts
+// the subset of YAML golangci-lint configurations use for the rules: list
+// items under a rules key, as in linters.exclusions.rules, or under
+// issues.exclude-rules for configurations of golangci-lint v1, with path,
+// text and linters keys. Other keys are ignored.
+func readExclusionRules(r io.Reader) (exclusionRules, error) {
+	var rules exclusionRules
+	inRules := false
+	rulesIndent, listKey := 0, ""
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		text := scanner.Text()
+		line := strings.TrimSpace(text)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+
+		if line == "rules:" || line == "exclude-rules:" {
+			inRules, rulesIndent, listKey = true, indent, ""
+			continue
+		}
+		if !inRules {
+			continue
+		}
+		if indent <= rulesIndent && !strings.HasPrefix(line, "- ") {
+			inRules = false
+			continue
+		}
+
+		item, isItem := strings.CutPrefix(line, "- ")
+		key, value, isKey := strings.Cut(item, ":")
+		switch {
+		case isItem && !isKey:
+			// An element of the list under listKey, such as a linter
+			if len(rules) == 0 || listKey != "linters" {
+				continue
+			}
+			rule := &rules[len(rules)-1]
+			rule.linters = append(rule.linters, yamlScalar(item))
+			continue
+		case !isKey:
+			return nil, fmt.Errorf("line %d: expected a key or list item, got %q", lineNum, line)
+		case isItem:
+			rules = append(rules, exclusionRule{})
+		case len(rules) == 0:
+			return nil, fmt.Errorf("line %d: %s outside of a rule", lineNum, key)
+		}
+
+		rule := &rules[len(rules)-1]
+		key, value = strings.TrimSpace(key), yamlScalar(value)
+		listKey = ""
+		var err error
+		switch key {
+		case "path":
+			rule.path, err = regexp.Compile(value)
+		case "text":
+			rule.text, err = regexp.Compile(value)
+		case "linters":
+			if value != "" {
+				// A flow sequence such as [recovercheck]
+				for linter := range strings.SplitSeq(strings.Trim(value, "[]"), ",") {
+					rule.linters = append(rule.linters, yamlScalar(linter))
+				}
+			}
+			listKey = key
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid %s: %w", lineNum, key, err)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// yamlScalar returns the value of a YAML scalar written plain or quoted
+func yamlScalar(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	if strings.HasPrefix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+	}
+	return value
+}
+
+// writeExclusionRules writes a golangci-lint exclusion rule for each file
+// and message of findings, anchored so that they only match them
+func writeExclusionRules(w io.Writer, findings []finding) {
+	fmt.Fprint(w, "linters:\n  exclusions:\n    rules:\n")
+	seen := make(map[[2]string]bool)
+	for _, f := range findings {
+		if seen[[2]string{f.file, f.message}] {
+			continue
+		}
+		seen[[2]string{f.file, f.message}] = true
+		fmt.Fprintf(w, "      - path: %s\n        linters:\n          - recovercheck\n        text: %s\n",
+			yamlQuote("^"+regexp.QuoteMeta(f.file)+"$"), yamlQuote("^"+regexp.QuoteMeta(f.message)+"$"))
+	}
+}
+
+// yamlQuote writes value as a single-quoted YAML scalar, in which
+// backslashes aren't escapes
+func yamlQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// baselineDir returns the absolute directory of the baseline file at path,
+// which the paths of its findings are relative to
+func baselineDir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(abs), nil
+}
+
+// suppressBaseline removes the diagnostics of graph found in the baseline at
+// path, read in the given format. A diagnostic shared by a package and its
+// test variant is suppressed or kept in both.
+func suppressBaseline(graph *checker.Graph, path, format string) error {
+	known, err := readBaseline(path, format)
+	if err != nil {
+		return err
+	}
+	dir, err := baselineDir(path)
+	if err != nil {
+		return err
+	}
+
+	suppressed := make(map[diagnosticKey]bool)
+	for _, f := range baselineFindings(graph, dir) {
+		if known.suppress(f) {
+			suppressed[f.key] = true
+		}
+	}
+
+	for _, act := range graph.Roots {
+		act.Diagnostics = slices.DeleteFunc(act.Diagnostics, func(diagnostic analysis.Diagnostic) bool {
+			return suppressed[diagnosticKey{act.Package.Fset.Position(diagnostic.Pos), diagnostic.Message}]
+		})
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cksidharthan/recovercheck"
+)
+
+func TestBaselineFormatRoundTrip(t *testing.T) {
+	source := `package legacy
+
+func work() {}
+
+func Start() {
+	go work()
+}
+
+func (s *server) Serve() {
+	go work()
+}
+
+type server struct{}
+`
+
+	tests := []struct {
+		format   string
+		contains string // expected in the written baseline
+	}{
+		{format: baselineNative, contains: "legacy.go:6: goroutine created without panic recovery"},
+		{format: baselineGolangci, contains: `- path: '^legacy\.go$'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"go.mod":    "module example.com/legacy\n\ngo 1.24\n",
+				"legacy.go": source,
+			})
+			baseline := filepath.Join(dir, "recovercheck.baseline")
+			opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Dir: dir, Baseline: baseline, BaselineFormat: tt.format}
+
+			run := func(t *testing.T) (int, string) {
+				t.Helper()
+				var stdout, stderr bytes.Buffer
+				code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr)
+				return code, stderr.String()
+			}
+
+			opts.WriteBaseline = true
+			if code, stderr := run(t); code != 0 {
+				t.Fatalf("expected exit code 0 writing the baseline, got %d; stderr:\n%s", code, stderr)
+			}
+			opts.WriteBaseline = false
+			content, err := os.ReadFile(baseline)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), tt.contains) {
+				t.Errorf("expected the baseline to contain %q, got:\n%s", tt.contains, content)
+			}
+
+			if code, stderr := run(t); code != 0 || stderr != "" {
+				t.Errorf("expected the baseline to suppress every finding, got exit code %d; stderr:\n%s", code, stderr)
+			}
+
+			// A finding in another file was never known
+			writeFiles(t, dir, map[string]string{"other.go": "package legacy\n\nfunc Stop() {\n\tgo work()\n}\n"})
+			code, stderr := run(t)
+			if code != 3 || strings.Count(stderr, "goroutine created without panic recovery") != 1 || !strings.Contains(stderr, "other.go:4") {
+				t.Errorf("expected the finding in other.go to be reported, got exit code %d; stderr:\n%s", code, stderr)
+			}
+		})
+	}
+}
+
+func TestReadExclusionRules(t *testing.T) {
+	// golangci-lint v1 configurations list the rules under
+	// issues.exclude-rules, and rules may be written for other linters
+	config := `run:
+  timeout: 5m
+issues:
+  exclude-rules:
+  - path: legacy\.go
+    linters: [errcheck, recovercheck]
+    text: "goroutine created"
+  - path: '^vendor/'
+    text: without panic recovery
+  - path: other\.go
+    linters:
+      - errcheck
+linters:
+  enable:
+    - recovercheck
+`
+	rules, err := readExclusionRules(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("expected 3 rules, got %d", len(rules))
+	}
+
+	message := "goroutine created without panic recovery"
+	tests := []struct {
+		file     string
+		expected bool
+	}{
+		{file: "pkg/legacy.go", expected: true},
+		{file: "vendor/example.com/lib/lib.go", expected: true},
+		{file: "other.go"}, // the rule only applies to errcheck
+		{file: "new.go"},
+	}
+	for _, tt := range tests {
+		if got := rules.suppress(finding{file: tt.file, message: message}); got != tt.expected {
+			t.Errorf("expected suppress(%s) = %v, got %v", tt.file, tt.expected, got)
+		}
+	}
+}
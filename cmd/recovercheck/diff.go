@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffLine is a line of a diff: kind is ' ' for a line of both texts, '-'
+// for a line only in the old text and '+' for a line only in the new one
+type diffLine struct {
+	kind byte
+	text string // including its newline, if any
+}
+
+// writeUnifiedDiff writes the changes from old to new as a unified diff of
+// the file name, in the format of git diff so that it can be applied with
+// git apply or patch -p1. Nothing is written if the texts are equal.
+func writeUnifiedDiff(w io.Writer, name string, old, new []byte) error {
+	lines := diffLines(splitLines(string(old)), splitLines(string(new)))
+	if !slices.ContainsFunc(lines, func(l diffLine) bool { return l.kind != ' ' }) {
+		return nil
+	}
+
+	// oldLine[i] and newLine[i] count the lines of each text before lines[i]
+	oldLine := make([]int, len(lines)+1)
+	newLine := make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if l.kind != '+' {
+			oldLine[i+1]++
+		}
+		if l.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(lines); {
+		if lines[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Changes separated by at most twice the context share a hunk, as
+		// with diff -u
+		start, end := max(0, i-diffContext), i+1
+		for j := i + 1; j < len(lines) && j-end <= 2*diffContext; j++ {
+			if lines[j].kind != ' ' {
+				end = j + 1
+			}
+		}
+		end = min(len(lines), end+diffContext)
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[end]-oldLine[start]),
+			hunkRange(newLine[start], newLine[end]-newLine[start]))
+		for _, l := range lines[start:end] {
+			b.WriteByte(l.kind)
+			b.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// hunkRange formats the range of count lines following the first lines of a
+// text as in a hunk header: an empty range starts at the line before it, and
+// the count of a single line is omitted
+func hunkRange(first, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", first)
+	case 1:
+		return fmt.Sprintf("%d", first+1)
+	}
+	return fmt.Sprintf("%d,%d", first+1, count)
+}
+
+// splitLines splits text after each newline; the last line lacks one if the
+// text doesn't end with a newline
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script turning a into b with Myers'
+// algorithm, which takes time proportional to the size of the texts times
+// the number of changed lines, so the few lines a fix changes in a large
+// file are diffed quickly
+func diffLines(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	// v[offset+k] is the furthest x reached on diagonal k = x - y, and
+	// trace[d] is v before looking for scripts of d edits
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // insertion
+			} else {
+				x = v[offset+k-1] + 1 // deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end of both texts to recover the edits
+	var lines []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			lines = append(lines, diffLine{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				lines = append(lines, diffLine{'+', b[y-1]})
+			} else {
+				lines = append(lines, diffLine{'-', a[x-1]})
+			}
+			x, y = prevX, prevY
+		}
+	}
+	slices.Reverse(lines)
+	return lines
+}
@@ -1,12 +1,124 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/cksidharthan/recovercheck"
-	"golang.org/x/tools/go/analysis/singlechecker"
+	"golang.org/x/tools/go/analysis/unitchecker"
 )
 
 func main() {
 	settings := &recovercheck.RecovercheckSettings{}
+	analyzer := recovercheck.New(settings)
+	if isVetTool(os.Args[1:]) {
+		// Analyze a single package for go vet -vettool, with the analyzer's
+		// flags prefixed by its name, as in -recovercheck.strict-main
+		unitchecker.Main(analyzer)
+	}
+	opts := options{
+		Severity:       severityError,
+		ErrorExitCode:  3,
+		BaselineFormat: baselineNative,
+	}
+
+	flag.Func("json-summary", "write a JSON summary of all unsafe goroutines to `file`", func(path string) error {
+		settings.Summary = &recovercheck.SummaryCollector{Path: path}
+		return nil
+	})
+	flag.Func("coverage", "write per-package recovery coverage as JSON to `file`", func(path string) error {
+		settings.Coverage = &recovercheck.CoverageCollector{Path: path}
+		return nil
+	})
+	flag.Func("severity", "report diagnostics as `level` error (non-zero exit) or warning (exit 0)", func(level string) error {
+		switch level {
+		case severityError, severityWarning:
+			opts.Severity = level
+			return nil
+		}
+		return fmt.Errorf("must be %q or %q", severityError, severityWarning)
+	})
+	flag.IntVar(&opts.ErrorExitCode, "error-exit-code", opts.ErrorExitCode, "exit code when diagnostics are found at error severity")
+	flag.BoolVar(&opts.Summary, "summary", false, "print a count of unsafe goroutines after analysis")
+	flag.BoolVar(&opts.Fix, "fix", false, "apply all suggested fixes")
+	flag.BoolVar(&opts.ShowFixes, "show-fixes", false, "print the changes suggested fixes would make as a unified diff instead of applying them")
+	flag.StringVar(&opts.Baseline, "baseline", "", "suppress the known findings listed in `file`")
+	flag.Func("baseline-format", "read and write the -baseline file as `format` native (default) or golangci exclusion rules", func(format string) error {
+		switch format {
+		case baselineNative, baselineGolangci:
+			opts.BaselineFormat = format
+			return nil
+		}
+		return fmt.Errorf("must be %q or %q", baselineNative, baselineGolangci)
+	})
+	flag.BoolVar(&opts.WriteBaseline, "write-baseline", false, "write the current findings to the -baseline file instead of reporting them")
+	flag.StringVar(&opts.SARIF, "sarif", "", "write a SARIF 2.1.0 report of the findings to `file`")
+	flag.BoolVar(&opts.ListRecovery, "list-recovery-functions", false, "print whether each function classified by the analyzer recovers to stderr")
+	flag.BoolVar(&opts.JSON, "json", false, "emit JSON output")
+	flag.IntVar(&opts.ContextLines, "c", -1, "display offending line with this many lines of context")
+	flag.BoolVar(&opts.Tests, "test", true, "indicates whether test files should be analyzed, too")
+
+	analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flag.Var(f.Value, f.Name, f.Usage)
+	})
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s: %s\n\nUsage: %s [-flag] [package]\n\nFlags:\n", analyzer.Name, analyzer.Doc, analyzer.Name)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if opts.WriteBaseline && opts.Baseline == "" {
+		fmt.Fprintln(os.Stderr, "-write-baseline requires -baseline")
+		os.Exit(1)
+	}
+	if opts.WriteBaseline && opts.Fix {
+		// The baseline would record findings that are fixed
+		fmt.Fprintln(os.Stderr, "-write-baseline can't be combined with -fix")
+		os.Exit(1)
+	}
+
+	if opts.ShowFixes && (opts.Fix || opts.JSON || opts.WriteBaseline) {
+		fmt.Fprintln(os.Stderr, "-show-fixes can't be combined with -fix, -json or -write-baseline")
+		os.Exit(1)
+	}
+
+	os.Exit(run(analyzer, args, opts, os.Stdout, os.Stderr))
+}
+
+// isVetTool checks if the command is run by go vet -vettool, which describes
+// the tool with -V=full and -flags, then runs it once per package with the
+// analyzer's flags followed by the path of a vet configuration file
+// describing the package
+func isVetTool(args []string) bool {
+	if len(args) == 1 && (args[0] == "-V=full" || args[0] == "-flags") {
+		return true
+	}
+	return len(args) > 0 && isVetConfig(args[len(args)-1])
+}
 
-	singlechecker.Main(recovercheck.New(settings))
+// isVetConfig checks if path names a vet configuration file: JSON written by
+// go vet naming at least the package's import path and compiler, so that a
+// package directory or other file named like one isn't mistaken for it
+func isVetConfig(path string) bool {
+	if !strings.HasSuffix(path, ".cfg") {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var config struct {
+		ImportPath string
+		Compiler   string
+	}
+	return json.Unmarshal(data, &config) == nil && config.ImportPath != "" && config.Compiler != ""
 }
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cksidharthan/recovercheck"
+)
+
+func TestIsVetTool(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"vet.cfg":         `{"ID": "example.com/vet", "Compiler": "gc", "Dir": "/src/vet", "ImportPath": "example.com/vet", "GoFiles": ["/src/vet/vet.go"]}`,
+		"other.cfg":       "key = value\n",
+		"pkg.cfg/work.go": "package work\n",
+	})
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{name: "version", args: []string{"-V=full"}, expected: true},
+		{name: "flags", args: []string{"-flags"}, expected: true},
+		{name: "package config", args: []string{"-json", "-recovercheck.strict-main", filepath.Join(dir, "vet.cfg")}, expected: true},
+		{name: "patterns", args: []string{"-strict-main", "./..."}},
+		{name: "flags with patterns", args: []string{"-flags", "./..."}},
+		{name: "package directory named like a config", args: []string{filepath.Join(dir, "pkg.cfg")}},
+		{name: "other config file", args: []string{filepath.Join(dir, "other.cfg")}},
+		{name: "missing config file", args: []string{filepath.Join(dir, "missing.cfg")}},
+		{name: "no arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isVetTool(tt.args); got != tt.expected {
+				t.Errorf("expected isVetTool(%q) = %v, got %v", tt.args, tt.expected, got)
+			}
+		})
+	}
+}
+
+// buildCommand builds the command into a temporary directory and returns
+// its path
+func buildCommand(t *testing.T) string {
+	t.Helper()
+	tool := filepath.Join(t.TempDir(), "recovercheck")
+	if out, err := exec.Command("go", "build", "-o", tool, ".").CombinedOutput(); err != nil {
+		t.Fatalf("building the command: %v\n%s", err, out)
+	}
+	return tool
+}
+
+// uniquePackage writes a module whose package starts an unrecovered
+// goroutine at line 7. go vet caches its results by the source of each
+// package, so the source is unique for the diagnostics to be reported again.
+func uniquePackage(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/vet\n\ngo 1.24\n",
+		"work.go": fmt.Sprintf(`package vet
+
+// run %d
+
+// Start runs a goroutine without recovery
+func Start() {
+	go func() {
+		panic("oh no")
+	}()
+}
+`, time.Now().UnixNano()),
+	})
+	return dir
+}
+
+func TestVetTool(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the command and runs go vet")
+	}
+
+	tool := buildCommand(t)
+	cmd := exec.Command("go", "vet", "-vettool="+tool, "-recovercheck.report-at=body", "./...")
+	cmd.Dir = uniquePackage(t)
+	out, _ := cmd.CombinedOutput()
+
+	// Depending on the Go version, go vet prints diagnostics as text or JSON
+	if !strings.Contains(string(out), "work.go:7:12") || !strings.Contains(string(out), "goroutine created without panic recovery") {
+		t.Errorf("expected go vet to report the unsafe goroutine at the function literal body, got:\n%s", out)
+	}
+}
+
+func TestAnalyzerFlags(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the command and runs go vet")
+	}
+
+	// Set every analyzer flag to a valid value: boolean flags are enabled,
+	// except -debug, which only adds output
+	values := map[string]string{
+		"cache-dir":          t.TempDir(),
+		"debug":              "false",
+		"exclude-func-regex": "^$",
+		"include-func-regex": ".",
+		"max-findings":       "10",
+		"package-kind":       "library",
+		"report-at":          "go",
+		"safe-func":          "example.com/pool.Recover",
+		"since":              "0s",
+		"spawn-func":         "example.com/pool.Pool.Go",
+		"trusted-spawner":    "example.com/pool.Safe",
+	}
+	var flags []string
+	recovercheck.New(&recovercheck.RecovercheckSettings{}).Flags.VisitAll(func(f *flag.Flag) {
+		value, ok := values[f.Name]
+		if !ok {
+			if b, isBool := f.Value.(interface{ IsBoolFlag() bool }); !isBool || !b.IsBoolFlag() {
+				t.Fatalf("no value to test flag -%s with", f.Name)
+			}
+			value = "true"
+		}
+		flags = append(flags, f.Name+"="+value)
+	})
+
+	tool := buildCommand(t)
+	tests := []struct {
+		name string
+		args func(flags []string) []string
+	}{
+		{
+			name: "command",
+			args: func(flags []string) []string {
+				var args []string
+				for _, f := range flags {
+					args = append(args, "-"+f)
+				}
+				return append(args, "./...")
+			},
+		},
+		{
+			name: "go vet",
+			args: func(flags []string) []string {
+				args := []string{"vet", "-vettool=" + tool}
+				for _, f := range flags {
+					args = append(args, "-recovercheck."+f)
+				}
+				return append(args, "./...")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args(flags)
+			cmd := exec.Command(tool, args...)
+			if args[0] == "vet" {
+				cmd = exec.Command("go", args...)
+			}
+			cmd.Dir = uniquePackage(t)
+			out, _ := cmd.CombinedOutput()
+			if !strings.Contains(string(out), "work.go:7:2") || !strings.Contains(string(out), "goroutine created without panic recovery") {
+				t.Errorf("expected every flag to be accepted and the unsafe goroutine reported, got:\n%s", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/cksidharthan/recovercheck"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// Severity levels accepted by -severity
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// options configures a single run of the analyzer
+type options struct {
+	Severity       string // severityError or severityWarning
+	ErrorExitCode  int    // exit code when diagnostics are found at error severity
+	JSON           bool   // print diagnostics as JSON to stdout
+	ContextLines   int    // lines of context to print around each diagnostic
+	Tests          bool   // also analyze test packages
+	Dir            string // directory in which to resolve patterns
+	Summary        bool   // print a tally of the diagnostics after analysis
+	Fix            bool   // apply suggested fixes to the files on disk
+	ShowFixes      bool   // print suggested fixes as a diff to stdout instead
+	Baseline       string // file of known findings to suppress, if set
+	BaselineFormat string // format of the Baseline file, baselineNative or baselineGolangci
+	WriteBaseline  bool   // write the findings to Baseline instead of reporting them
+	SARIF          string // file to write a SARIF report of the findings to, if set
+	ListRecovery   bool   // print the recovery classification of each function to stderr
+}
+
+// run loads the packages matching patterns, applies analyzer to them and
+// prints its diagnostics. It returns the exit code of the process: 1 if the
+// packages could not be loaded or analyzed, ErrorExitCode if diagnostics
+// other than notes were found at error severity or goroutines in main() were
+// reported by -strict-main, and 0 otherwise. As with
+// go vet, JSON output always exits 0 once analysis succeeds. Findings listed
+// in the Baseline file are dropped before printing them and writing the
+// SARIF report, if any, and before applying or showing suggested fixes; with
+// WriteBaseline, the findings are written to it instead and nothing is
+// printed or fixed.
+func run(analyzer *analysis.Analyzer, patterns []string, opts options, stdout, stderr io.Writer) int {
+	cfg := &packages.Config{
+		Mode:  packages.LoadAllSyntax,
+		Tests: opts.Tests,
+		Dir:   opts.Dir,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if len(pkgs) == 0 {
+		fmt.Fprintf(stderr, "%v matched no packages\n", patterns)
+		return 1
+	}
+	if !analyzer.RunDespiteErrors && packages.PrintErrors(pkgs) > 0 {
+		return 1
+	}
+
+	graph, err := checker.Analyze([]*analysis.Analyzer{analyzer}, pkgs, nil)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if opts.ListRecovery {
+		printRecoveryFunctions(stderr, graph)
+	}
+
+	if opts.WriteBaseline {
+		if err := writeBaseline(opts.Baseline, opts.BaselineFormat, graph); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	}
+	if opts.Baseline != "" {
+		if err := suppressBaseline(graph, opts.Baseline, opts.BaselineFormat); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+
+	// Only fix the findings that are reported
+	if opts.Fix {
+		if err := applyFixes(graph); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	if opts.ShowFixes {
+		if err := showFixes(stdout, graph, opts.Dir); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+
+	if opts.SARIF != "" {
+		if err := writeSARIF(opts.SARIF, graph, analyzer.Name, opts.Dir, opts.Severity); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+
+	if opts.JSON {
+		if err := graph.PrintJSON(stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		// Keep stdout valid JSON
+		if opts.Summary {
+			printSummary(stderr, analyzer.Name, graph)
+		}
+		return 0
+	}
+
+	if err := graph.PrintText(stderr, opts.ContextLines); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	found, strict := false, false
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			return 1
+		}
+		for _, diagnostic := range act.Diagnostics {
+			// Notes, such as goroutines in tests with -note-test-goroutines
+			// or recovery swallowing the recovered value, are printed but
+			// don't fail the command
+			switch {
+			case diagnostic.Category == recovercheck.KindMain:
+				// Goroutines in main() reported by -strict-main fail the
+				// command whatever the severity
+				found, strict = true, true
+			case recovercheck.IsFinding(diagnostic.Category):
+				found = true
+			}
+		}
+	}
+	if opts.Summary {
+		printSummary(stdout, analyzer.Name, graph)
+	}
+	if strict || (found && opts.Severity == severityError) {
+		return opts.ErrorExitCode
+	}
+	return 0
+}
+
+// printSummary prints a single line tallying the diagnostics of graph by
+// category and the number of files analyzed, such as
+// "recovercheck: 12 unsafe goroutines, 3 unsafe errgroup callbacks across 40 files".
+// Diagnostics and files shared by a package and its test variant are counted
+// once, and generated files, such as the test main of a test variant, aren't
+// counted.
+func printSummary(w io.Writer, name string, graph *checker.Graph) {
+	seen := make(map[diagnosticKey]bool)
+	counts := make(map[string]int)
+	files := make(map[string]bool)
+
+	for _, act := range graph.Roots {
+		for _, file := range act.Package.Syntax {
+			if !ast.IsGenerated(file) {
+				files[act.Package.Fset.File(file.FileStart).Name()] = true
+			}
+		}
+		for _, diagnostic := range act.Diagnostics {
+			k := diagnosticKey{act.Package.Fset.Position(diagnostic.Pos), diagnostic.Message}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			counts[diagnostic.Category]++
+		}
+	}
+
+	parts := []string{
+		plural(counts[recovercheck.KindGoroutine]+counts[recovercheck.KindMain], "unsafe goroutine", "unsafe goroutines"),
+		plural(counts[recovercheck.KindErrgroup], "unsafe errgroup callback", "unsafe errgroup callbacks"),
+	}
+	if counts[recovercheck.KindSpawn] > 0 {
+		parts = append(parts, plural(counts[recovercheck.KindSpawn], "unsafe spawned goroutine", "unsafe spawned goroutines"))
+	}
+	fmt.Fprintf(w, "%s: %s across %s\n", name, strings.Join(parts, ", "), plural(len(files), "file", "files"))
+}
+
+// printRecoveryFunctions prints the RecoverFunctions of each analyzed
+// package, in package order, as a "# package" line followed by one
+// "name\ttrue" or "name\tfalse" line per function, sorted by name
+func printRecoveryFunctions(w io.Writer, graph *checker.Graph) {
+	roots := slices.Clone(graph.Roots)
+	slices.SortFunc(roots, func(a, b *checker.Action) int {
+		return strings.Compare(a.Package.ID, b.Package.ID)
+	})
+	for _, act := range roots {
+		result, ok := act.Result.(*recovercheck.RecoverResult)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "# %s\n", act.Package.ID)
+		for _, name := range slices.Sorted(maps.Keys(result.RecoverFunctions)) {
+			fmt.Fprintf(w, "%s\t%v\n", name, result.RecoverFunctions[name])
+		}
+	}
+}
+
+// plural formats n with the singular or plural form of a noun
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// fileEdit is a suggested text edit, in byte offsets of its file
+type fileEdit struct {
+	start, end int
+	text       string
+}
+
+// fixEdits collects the suggested edits of every diagnostic in graph by
+// file name. Identical edits, as suggested for a package and its test variant
+// or for two go statements running the same function, are collected once.
+func fixEdits(graph *checker.Graph) map[string][]fileEdit {
+	edits := make(map[string]map[fileEdit]bool)
+	for _, act := range graph.Roots {
+		for _, diagnostic := range act.Diagnostics {
+			for _, fix := range diagnostic.SuggestedFixes {
+				for _, edit := range fix.TextEdits {
+					file := act.Package.Fset.File(edit.Pos)
+					if file == nil {
+						continue
+					}
+					if edits[file.Name()] == nil {
+						edits[file.Name()] = make(map[fileEdit]bool)
+					}
+					edits[file.Name()][fileEdit{file.Offset(edit.Pos), file.Offset(edit.End), string(edit.NewText)}] = true
+				}
+			}
+		}
+	}
+
+	files := make(map[string][]fileEdit, len(edits))
+	for filename, set := range edits {
+		files[filename] = slices.Collect(maps.Keys(set))
+	}
+	return files
+}
+
+// applyFixes applies the suggested fixes of every diagnostic in graph to the
+// files on disk and formats the result, see fixEdits and fixedSource
+func applyFixes(graph *checker.Graph) error {
+	edits := fixEdits(graph)
+	for _, filename := range slices.Sorted(maps.Keys(edits)) {
+		_, fixed, err := fixedSource(filename, edits[filename])
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filename, fixed, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// showFixes writes what applyFixes would change as a unified diff per file,
+// in file name order, leaving the files on disk untouched. File names are
+// relative to dir, the source root.
+func showFixes(w io.Writer, graph *checker.Graph, dir string) error {
+	root, err := filepath.Abs(cmp.Or(dir, "."))
+	if err != nil {
+		return err
+	}
+
+	edits := fixEdits(graph)
+	for _, filename := range slices.Sorted(maps.Keys(edits)) {
+		src, fixed, err := fixedSource(filename, edits[filename])
+		if err != nil {
+			return err
+		}
+		name := filename
+		if rel, err := filepath.Rel(root, filename); err == nil {
+			name = rel
+		}
+		if err := writeUnifiedDiff(w, filepath.ToSlash(name), src, fixed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fixedSource reads the named file and returns its source along with the
+// source with edits applied and formatted. An edit overlapping an earlier one
+// is dropped.
+func fixedSource(filename string, edits []fileEdit) (src, fixed []byte, err error) {
+	src, err = os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slices.SortFunc(edits, func(a, b fileEdit) int {
+		return cmp.Or(cmp.Compare(a.start, b.start), cmp.Compare(a.end, b.end), strings.Compare(a.text, b.text))
+	})
+
+	var out []byte
+	last := 0
+	for _, edit := range edits {
+		if edit.start < last || edit.end > len(src) {
+			continue
+		}
+		out = append(out, src[last:edit.start]...)
+		out = append(out, edit.text...)
+		last = edit.end
+	}
+	out = append(out, src[last:]...)
+
+	fixed, err = format.Source(out)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: formatting fixed source: %v", filename, err)
+	}
+	return src, fixed, nil
+}
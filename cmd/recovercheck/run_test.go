@@ -0,0 +1,936 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cksidharthan/recovercheck"
+)
+
+func TestRunSeverity(t *testing.T) {
+	dir := filepath.Join("..", "..", "testdata", "crossmodule")
+
+	tests := []struct {
+		name         string
+		pattern      string
+		opts         options
+		expectedCode int
+	}{
+		{
+			name:         "error severity with findings",
+			pattern:      "./app",
+			opts:         options{Severity: severityError, ErrorExitCode: 3},
+			expectedCode: 3,
+		},
+		{
+			name:         "custom error exit code",
+			pattern:      "./app",
+			opts:         options{Severity: severityError, ErrorExitCode: 7},
+			expectedCode: 7,
+		},
+		{
+			name:         "warning severity with findings",
+			pattern:      "./app",
+			opts:         options{Severity: severityWarning, ErrorExitCode: 3},
+			expectedCode: 0,
+		},
+		{
+			name:         "error severity without findings",
+			pattern:      "example.com/recoverlib",
+			opts:         options{Severity: severityError, ErrorExitCode: 3},
+			expectedCode: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Dir = dir
+			tt.opts.ContextLines = -1
+
+			var stdout, stderr bytes.Buffer
+			analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{})
+			code := run(analyzer, []string{tt.pattern}, tt.opts, &stdout, &stderr)
+
+			if code != tt.expectedCode {
+				t.Errorf("expected exit code %d, got %d; stderr:\n%s", tt.expectedCode, code, stderr.String())
+			}
+
+			// Diagnostics are printed regardless of severity
+			reported := strings.Contains(stderr.String(), "goroutine created without panic recovery")
+			if expected := tt.pattern == "./app"; reported != expected {
+				t.Errorf("expected diagnostics printed = %v, got stderr:\n%s", expected, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	dir := filepath.Join("..", "..", "testdata", "crossmodule")
+	expected := "recovercheck: 1 unsafe goroutine, 0 unsafe errgroup callbacks across 1 file\n"
+
+	tests := []struct {
+		name string
+		json bool
+	}{
+		{name: "text output"},
+		{name: "json output", json: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options{
+				Severity:      severityWarning,
+				ErrorExitCode: 3,
+				JSON:          tt.json,
+				ContextLines:  -1,
+				Tests:         true,
+				Dir:           dir,
+				Summary:       true,
+			}
+
+			var stdout, stderr bytes.Buffer
+			analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{})
+			if code := run(analyzer, []string{"./app"}, opts, &stdout, &stderr); code != 0 {
+				t.Fatalf("expected exit code 0, got %d; stderr:\n%s", code, stderr.String())
+			}
+
+			// With -json the summary moves to stderr so stdout stays valid JSON
+			summary := stdout.String()
+			if tt.json {
+				if !json.Valid(stdout.Bytes()) {
+					t.Errorf("expected valid JSON on stdout, got:\n%s", stdout.String())
+				}
+				summary = stderr.String()
+			}
+			if !strings.HasSuffix(summary, expected) {
+				t.Errorf("expected summary %q, got:\n%s", expected, summary)
+			}
+		})
+	}
+}
+
+func TestRunSummaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/files\n\ngo 1.24\n",
+		"work.go": `package files
+
+func work() {}
+
+// Start runs work without recovery
+func Start() {
+	go work()
+}
+`,
+		// The test variant adds its generated test main, which isn't counted
+		"work_test.go": `package files
+
+import "testing"
+
+func TestStart(t *testing.T) {
+	Start()
+}
+`,
+	})
+
+	opts := options{Severity: severityWarning, ErrorExitCode: 3, ContextLines: -1, Tests: true, Dir: dir, Summary: true}
+	var stdout, stderr bytes.Buffer
+	if code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr:\n%s", code, stderr.String())
+	}
+	if expected := "recovercheck: 1 unsafe goroutine, 0 unsafe errgroup callbacks across 2 files\n"; stdout.String() != expected {
+		t.Errorf("expected summary %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestRunJSONSummary(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/summary\n\ngo 1.24\n",
+		"work.go": `package summary
+
+func work() {}
+
+// Start runs work twice without recovery
+func Start() {
+	go work()
+	go work()
+}
+`,
+		// A test file makes the package analyzed again as its test variant
+		"work_test.go": `package summary
+
+import "testing"
+
+func TestStart(t *testing.T) {
+	Start()
+}
+`,
+	})
+
+	path := filepath.Join(dir, "summary.json")
+	settings := &recovercheck.RecovercheckSettings{Summary: &recovercheck.SummaryCollector{Path: path}}
+	opts := options{Severity: severityWarning, ErrorExitCode: 3, ContextLines: -1, Tests: true, Dir: dir}
+	var stdout, stderr bytes.Buffer
+	if code := run(recovercheck.New(settings), []string{"./..."}, opts, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var findings []recovercheck.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, finding := range findings {
+		lines = append(lines, finding.Line)
+	}
+	if expected := []int{7, 8}; !slices.Equal(lines, expected) {
+		t.Errorf("expected findings on lines %v, got %v", expected, lines)
+	}
+}
+
+func TestRunCoverage(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/coverage\n\ngo 1.24\n",
+		"work.go": `package coverage
+
+func work() {}
+
+func safeWork() {
+	defer func() { recover() }()
+}
+
+// Start runs work with and without recovery
+func Start() {
+	go work()
+	go safeWork()
+}
+`,
+		// Test files make the package analyzed again as its test variant, and
+		// the external test package and generated test main analyzed too
+		"work_test.go": `package coverage
+
+import "testing"
+
+func TestStart(t *testing.T) {
+	Start()
+}
+`,
+		"example_test.go": `package coverage_test
+
+import "example.com/coverage"
+
+func ExampleStart() {
+	go coverage.Start()
+}
+`,
+	})
+
+	path := filepath.Join(dir, "coverage.json")
+	settings := &recovercheck.RecovercheckSettings{Coverage: &recovercheck.CoverageCollector{Path: path}}
+	opts := options{Severity: severityWarning, ErrorExitCode: 3, ContextLines: -1, Tests: true, Dir: dir}
+	var stdout, stderr bytes.Buffer
+	if code := run(recovercheck.New(settings), []string{"./..."}, opts, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var packages []recovercheck.PackageCoverage
+	if err := json.Unmarshal(data, &packages); err != nil {
+		t.Fatal(err)
+	}
+	expected := []recovercheck.PackageCoverage{
+		{Package: "example.com/coverage", Total: 3, Recovered: 1, Coverage: 1.0 / 3},
+	}
+	if !slices.Equal(packages, expected) {
+		t.Errorf("expected coverage %+v, got %+v", expected, packages)
+	}
+}
+
+func TestRunFix(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/fixme\n\ngo 1.24\n",
+		"work.go": `package fixme
+
+func work() {
+	println("working")
+}
+
+// Start runs work twice, so both go statements suggest the same fix
+func Start() {
+	go work()
+	go work()
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Tests: true, Dir: dir, Fix: true}
+	var stdout, stderr bytes.Buffer
+	if code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr); code != 3 {
+		t.Fatalf("expected exit code 3 for the diagnostics found before fixing, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	expected := `package fixme
+
+import "log"
+
+func work() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("recovered from panic:", r)
+		}
+	}()
+	println("working")
+}
+
+// Start runs work twice, so both go statements suggest the same fix
+func Start() {
+	go work()
+	go work()
+}
+`
+	fixed, err := os.ReadFile(filepath.Join(dir, "work.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fixed) != expected {
+		t.Errorf("expected fixed source:\n%s\ngot:\n%s", expected, fixed)
+	}
+
+	// The fixed package has nothing left to report
+	stderr.Reset()
+	opts.Fix = false
+	if code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr); code != 0 {
+		t.Errorf("expected exit code 0 after fixing, got %d; stderr:\n%s", code, stderr.String())
+	}
+}
+
+func TestRunFixBaseline(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `package fixme
+
+func legacy() {}
+
+// StartLegacy runs legacy, a known finding of the baseline
+func StartLegacy() {
+	go legacy()
+}
+`
+	writeFiles(t, dir, map[string]string{
+		"go.mod":    "module example.com/fixme\n\ngo 1.24\n",
+		"legacy.go": legacy,
+	})
+
+	baseline := filepath.Join(dir, "recovercheck.baseline")
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Dir: dir, Baseline: baseline, WriteBaseline: true}
+	var stdout, stderr bytes.Buffer
+	if code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0 writing the baseline, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	writeFiles(t, dir, map[string]string{
+		"work.go": `package fixme
+
+func work() {}
+
+// Start runs work, a new finding
+func Start() {
+	go work()
+}
+`,
+	})
+	opts.WriteBaseline, opts.Fix = false, true
+	if code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr); code != 3 {
+		t.Fatalf("expected exit code 3 for the new finding, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	// Only the new finding is fixed
+	content, err := os.ReadFile(filepath.Join(dir, "legacy.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != legacy {
+		t.Errorf("expected legacy.go to be left unchanged, got:\n%s", content)
+	}
+	content, err = os.ReadFile(filepath.Join(dir, "work.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "recover()") {
+		t.Errorf("expected work.go to be fixed, got:\n%s", content)
+	}
+}
+
+func TestRunShowFixes(t *testing.T) {
+	dir := filepath.Join("..", "..", "testdata", "showfixes")
+	src, err := os.ReadFile(filepath.Join(dir, "work.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile(filepath.Join(dir, "work.diff.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Dir: dir, ShowFixes: true}
+	var stdout, stderr bytes.Buffer
+	if code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr); code != 3 {
+		t.Fatalf("expected exit code 3 for the diagnostics found, got %d; stderr:\n%s", code, stderr.String())
+	}
+	if stdout.String() != string(golden) {
+		t.Errorf("expected diff:\n%s\ngot:\n%s", golden, stdout.String())
+	}
+
+	// The fixes are only shown
+	after, err := os.ReadFile(filepath.Join(dir, "work.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(after, src) {
+		t.Errorf("expected work.go to be left unchanged, got:\n%s", after)
+	}
+}
+
+func TestRunShowFixesBaseline(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/fixme\n\ngo 1.24\n",
+		"legacy.go": `package fixme
+
+func legacy() {}
+
+// StartLegacy runs legacy, a known finding of the baseline
+func StartLegacy() {
+	go legacy()
+}
+`,
+	})
+
+	baseline := filepath.Join(dir, "recovercheck.baseline")
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Dir: dir, Baseline: baseline, WriteBaseline: true}
+	var stdout, stderr bytes.Buffer
+	if code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0 writing the baseline, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	writeFiles(t, dir, map[string]string{
+		"work.go": `package fixme
+
+func work() {}
+
+// Start runs work, a new finding
+func Start() {
+	go work()
+}
+`,
+	})
+	opts.WriteBaseline, opts.ShowFixes = false, true
+	stdout.Reset()
+	if code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr); code != 3 {
+		t.Fatalf("expected exit code 3 for the new finding, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	// Only the fix for the reported finding is shown
+	if !strings.Contains(stdout.String(), "--- a/work.go\n") {
+		t.Errorf("expected a diff of work.go, got:\n%s", stdout.String())
+	}
+	if strings.Contains(stdout.String(), "legacy.go") {
+		t.Errorf("expected no diff of legacy.go, whose finding is in the baseline, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunBaseline(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/legacy\n\ngo 1.24\n",
+		"legacy.go": `package legacy
+
+func work() {}
+
+func Start() {
+	go work()
+	go work()
+}
+
+func (s *server) Serve() {
+	go work()
+}
+
+type server struct{}
+`,
+	})
+	baseline := filepath.Join(dir, "recovercheck.baseline")
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Tests: true, Dir: dir, Baseline: baseline}
+
+	run := func(t *testing.T) (int, string) {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		code := run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr)
+		return code, stderr.String()
+	}
+
+	t.Run("missing baseline", func(t *testing.T) {
+		if code, stderr := run(t); code != 1 {
+			t.Errorf("expected exit code 1 without a baseline file, got %d; stderr:\n%s", code, stderr)
+		}
+	})
+
+	t.Run("write baseline", func(t *testing.T) {
+		opts.WriteBaseline = true
+		defer func() { opts.WriteBaseline = false }()
+		if code, stderr := run(t); code != 0 {
+			t.Fatalf("expected exit code 0 writing the baseline, got %d; stderr:\n%s", code, stderr)
+		}
+
+		content, err := os.ReadFile(baseline)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		expected := []string{
+			"legacy.go:6: goroutine created without panic recovery",
+			"legacy.go:7: goroutine created without panic recovery",
+			"legacy.go:11: goroutine created without panic recovery",
+		}
+		if len(lines) != len(expected)+1 || !strings.HasPrefix(lines[0], "#") {
+			t.Fatalf("expected a header and %d findings, got:\n%s", len(expected), content)
+		}
+		for i, line := range lines[1:] {
+			if _, finding, _ := strings.Cut(line, " "); finding != expected[i] {
+				t.Errorf("expected finding %q, got %q", expected[i], finding)
+			}
+		}
+	})
+
+	t.Run("suppress baseline", func(t *testing.T) {
+		if code, stderr := run(t); code != 0 || stderr != "" {
+			t.Errorf("expected the baseline to suppress every finding, got exit code %d; stderr:\n%s", code, stderr)
+		}
+	})
+
+	t.Run("line shifts", func(t *testing.T) {
+		// Move every finding down; they stay in the same functions
+		source, err := os.ReadFile(filepath.Join(dir, "legacy.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		shifted := strings.Replace(string(source), "func work() {}", "// work does nothing\n\nfunc work() {}", 1)
+		writeFiles(t, dir, map[string]string{"legacy.go": shifted})
+
+		if code, stderr := run(t); code != 0 || stderr != "" {
+			t.Errorf("expected shifted findings to stay suppressed, got exit code %d; stderr:\n%s", code, stderr)
+		}
+	})
+
+	t.Run("new findings", func(t *testing.T) {
+		// A third go statement in Start exceeds the two known ones, and one
+		// in a new function was never known
+		source, err := os.ReadFile(filepath.Join(dir, "legacy.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		added := strings.Replace(string(source), "\tgo work()\n}", "\tgo work()\n\tgo work()\n}\n\nfunc Stop() {\n\tgo work()\n}", 1)
+		writeFiles(t, dir, map[string]string{"legacy.go": added})
+
+		code, stderr := run(t)
+		if code != 3 {
+			t.Errorf("expected exit code 3 for new findings, got %d; stderr:\n%s", code, stderr)
+		}
+		if count := strings.Count(stderr, "goroutine created without panic recovery"); count != 2 {
+			t.Errorf("expected 2 new findings, got %d; stderr:\n%s", count, stderr)
+		}
+		if !strings.Contains(stderr, "legacy.go:14:") {
+			t.Errorf("expected the finding in Stop to be reported, got stderr:\n%s", stderr)
+		}
+	})
+}
+
+func TestRunNotes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/notes\n\ngo 1.24\n",
+		"work.go": `package notes
+
+func work() {}
+`,
+		"work_test.go": `package notes
+
+import "testing"
+
+func TestWork(t *testing.T) {
+	go work()
+}
+`,
+	})
+
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Tests: true, Dir: dir}
+	var stdout, stderr bytes.Buffer
+	analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{NoteTestGoroutines: true})
+	if code := run(analyzer, []string{"./..."}, opts, &stdout, &stderr); code != 0 {
+		t.Errorf("expected exit code 0 with only notes, got %d; stderr:\n%s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "note: goroutine created without panic recovery") {
+		t.Errorf("expected the note to be printed, got stderr:\n%s", stderr.String())
+	}
+}
+
+func TestRunStrictMain(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/server\n\ngo 1.24\n",
+		"main.go": `package main
+
+func serve() {}
+
+func start() {
+	go serve()
+}
+
+func main() {
+	go serve()
+	start()
+}
+`,
+	})
+
+	tests := []struct {
+		name         string
+		strictMain   bool
+		expectedCode int
+	}{
+		{name: "warning severity", expectedCode: 0},
+		{name: "strict main overrides warning severity", strictMain: true, expectedCode: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options{Severity: severityWarning, ErrorExitCode: 3, ContextLines: -1, Dir: dir, Summary: true}
+			var stdout, stderr bytes.Buffer
+			analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{StrictMain: tt.strictMain})
+			if code := run(analyzer, []string{"./..."}, opts, &stdout, &stderr); code != tt.expectedCode {
+				t.Errorf("expected exit code %d, got %d; stderr:\n%s", tt.expectedCode, code, stderr.String())
+			}
+			// Goroutines in main are still counted as unsafe goroutines
+			if expected := "recovercheck: 2 unsafe goroutines"; !strings.HasPrefix(stdout.String(), expected) {
+				t.Errorf("expected summary %q, got:\n%s", expected, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunSARIF(t *testing.T) {
+	dir := filepath.Join("..", "..", "testdata", "crossmodule")
+	sarif := filepath.Join(t.TempDir(), "recovercheck.sarif")
+
+	tests := []struct {
+		name     string
+		severity string
+	}{
+		{name: "error severity", severity: severityError},
+		{name: "warning severity", severity: severityWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options{Severity: tt.severity, ErrorExitCode: 3, ContextLines: -1, Dir: dir, SARIF: sarif}
+			var stdout, stderr bytes.Buffer
+			run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./app"}, opts, &stdout, &stderr)
+
+			content, err := os.ReadFile(sarif)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var report struct {
+				Schema  string `json:"$schema"`
+				Version string `json:"version"`
+				Runs    []struct {
+					Tool struct {
+						Driver struct {
+							Name  string `json:"name"`
+							Rules []struct {
+								ID string `json:"id"`
+							} `json:"rules"`
+						} `json:"driver"`
+					} `json:"tool"`
+					Results []struct {
+						RuleID    string `json:"ruleId"`
+						RuleIndex int    `json:"ruleIndex"`
+						Level     string `json:"level"`
+						Message   struct {
+							Text string `json:"text"`
+						} `json:"message"`
+						Locations []struct {
+							PhysicalLocation struct {
+								ArtifactLocation struct {
+									URI       string `json:"uri"`
+									URIBaseID string `json:"uriBaseId"`
+								} `json:"artifactLocation"`
+								Region struct {
+									StartLine   int `json:"startLine"`
+									StartColumn int `json:"startColumn"`
+								} `json:"region"`
+							} `json:"physicalLocation"`
+						} `json:"locations"`
+					} `json:"results"`
+				} `json:"runs"`
+			}
+			if err := json.Unmarshal(content, &report); err != nil {
+				t.Fatalf("expected valid JSON, got %v:\n%s", err, content)
+			}
+
+			if report.Version != "2.1.0" || report.Schema != "https://json.schemastore.org/sarif-2.1.0.json" {
+				t.Errorf("expected SARIF 2.1.0, got version %q and schema %q", report.Version, report.Schema)
+			}
+			if len(report.Runs) != 1 || report.Runs[0].Tool.Driver.Name != "recovercheck" {
+				t.Fatalf("expected a single recovercheck run, got:\n%s", content)
+			}
+			run := report.Runs[0]
+			if len(run.Results) != 1 {
+				t.Fatalf("expected 1 result, got %d:\n%s", len(run.Results), content)
+			}
+
+			result := run.Results[0]
+			if result.RuleID != "recovercheck/goroutine" || run.Tool.Driver.Rules[result.RuleIndex].ID != result.RuleID {
+				t.Errorf("expected rule recovercheck/goroutine at its index, got %q at %d", result.RuleID, result.RuleIndex)
+			}
+			if result.Level != tt.severity {
+				t.Errorf("expected level %q, got %q", tt.severity, result.Level)
+			}
+			if result.Message.Text != "goroutine created without panic recovery" {
+				t.Errorf("expected the diagnostic message, got %q", result.Message.Text)
+			}
+			if len(result.Locations) != 1 {
+				t.Fatalf("expected 1 location, got %d", len(result.Locations))
+			}
+			location := result.Locations[0].PhysicalLocation
+			if location.ArtifactLocation.URI != "app/app.go" || location.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+				t.Errorf("expected app/app.go relative to %%SRCROOT%%, got %q relative to %q", location.ArtifactLocation.URI, location.ArtifactLocation.URIBaseID)
+			}
+			if location.Region.StartLine != 15 || location.Region.StartColumn != 2 {
+				t.Errorf("expected region 15:2, got %d:%d", location.Region.StartLine, location.Region.StartColumn)
+			}
+		})
+	}
+}
+
+// writeFiles writes files, keyed by slash-separated path, to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunSARIFNotes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/notes\n\ngo 1.24\n",
+		"work.go": `package notes
+
+// Start runs a goroutine that recovers but discards the recovered value
+func Start() {
+	go func() {
+		defer func() { _ = recover() }()
+	}()
+}
+`,
+	})
+
+	sarif := filepath.Join(dir, "recovercheck.sarif")
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Dir: dir, SARIF: sarif}
+	var stdout, stderr bytes.Buffer
+	analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{WarnSwallowedRecover: true})
+	if code := run(analyzer, []string{"./..."}, opts, &stdout, &stderr); code != 0 {
+		t.Errorf("expected exit code 0 with only notes, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	content, err := os.ReadFile(sarif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID               string `json:"id"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatalf("expected valid JSON, got %v:\n%s", err, content)
+	}
+
+	results := report.Runs[0].Results
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %+v", results)
+	}
+	result := results[0]
+	if result.RuleID != "recovercheck/swallowed" || result.Level != "note" {
+		t.Errorf("expected a note of rule recovercheck/swallowed, got %+v", result)
+	}
+	rule := report.Runs[0].Tool.Driver.Rules[result.RuleIndex]
+	if expected := "goroutine recovery discards the recovered panic value"; rule.ID != result.RuleID || rule.ShortDescription.Text != expected {
+		t.Errorf("expected rule %s described as %q, got %+v", result.RuleID, expected, rule)
+	}
+}
+
+func TestRunSARIFFixes(t *testing.T) {
+	dir := t.TempDir()
+	source := `package fixes
+
+import "log"
+
+func work() {
+	log.Println("working")
+}
+
+// Start runs work without recovery
+func Start() {
+	go work()
+}
+`
+	writeFiles(t, dir, map[string]string{
+		"go.mod":   "module example.com/fixes\n\ngo 1.24\n",
+		"fixes.go": source,
+	})
+
+	sarif := filepath.Join(dir, "recovercheck.sarif")
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Dir: dir, SARIF: sarif}
+	var stdout, stderr bytes.Buffer
+	run(recovercheck.New(&recovercheck.RecovercheckSettings{}), []string{"./..."}, opts, &stdout, &stderr)
+
+	content, err := os.ReadFile(sarif)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Runs []struct {
+			Results []struct {
+				Fixes []struct {
+					Description struct {
+						Text string `json:"text"`
+					} `json:"description"`
+					ArtifactChanges []struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Replacements []struct {
+							DeletedRegion struct {
+								ByteOffset int `json:"byteOffset"`
+								ByteLength int `json:"byteLength"`
+							} `json:"deletedRegion"`
+							InsertedContent struct {
+								Text string `json:"text"`
+							} `json:"insertedContent"`
+						} `json:"replacements"`
+					} `json:"artifactChanges"`
+				} `json:"fixes"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatalf("expected valid JSON, got %v:\n%s", err, content)
+	}
+
+	results := report.Runs[0].Results
+	if len(results) != 1 || len(results[0].Fixes) != 1 {
+		t.Fatalf("expected 1 result with 1 fix, got:\n%s", content)
+	}
+	fix := results[0].Fixes[0]
+	if fix.Description.Text == "" || len(fix.ArtifactChanges) != 1 || fix.ArtifactChanges[0].ArtifactLocation.URI != "fixes.go" {
+		t.Fatalf("expected a described fix changing fixes.go, got %+v", fix)
+	}
+
+	// Applying the replacements, last first, defers recovery in work
+	fixed := source
+	replacements := fix.ArtifactChanges[0].Replacements
+	for i := len(replacements) - 1; i >= 0; i-- {
+		r := replacements[i]
+		fixed = fixed[:r.DeletedRegion.ByteOffset] + r.InsertedContent.Text + fixed[r.DeletedRegion.ByteOffset+r.DeletedRegion.ByteLength:]
+	}
+	if !strings.Contains(fixed, "func work() {\n\tdefer func() {\n\t\tif r := recover(); r != nil {") {
+		t.Errorf("expected the fix to defer recovery in work, got:\n%s", fixed)
+	}
+}
+
+func TestRunListRecoveryFunctions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/list\n\ngo 1.24\n",
+		"lib/lib.go": `package lib
+
+func Run() {
+	defer func() { recover() }()
+}
+`,
+		"app/app.go": `package app
+
+import "example.com/list/lib"
+
+type server struct{}
+
+func (s *server) handlePanic() {
+	recover()
+}
+
+func work() {}
+
+func Start() {
+	go lib.Run()
+	go work()
+}
+`,
+	})
+
+	opts := options{Severity: severityError, ErrorExitCode: 3, ContextLines: -1, Dir: dir, ListRecovery: true}
+	var stdout, stderr bytes.Buffer
+	analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{})
+	if code := run(analyzer, []string{"./app"}, opts, &stdout, &stderr); code != 3 {
+		t.Errorf("expected exit code 3, got %d; stderr:\n%s", code, stderr.String())
+	}
+
+	expected := "# example.com/list/app\n" +
+		"(*server).handlePanic\ttrue\n" +
+		"Start\tfalse\n" +
+		"example.com/list/lib.Run\ttrue\n" +
+		"work\tfalse\n"
+	if !strings.HasPrefix(stderr.String(), expected) {
+		t.Errorf("expected the recovery functions to be listed first as\n%s\ngot stderr:\n%s", expected, stderr.String())
+	}
+}
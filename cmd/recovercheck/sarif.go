@@ -0,0 +1,208 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/cksidharthan/recovercheck"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+// sarifSchema is the JSON schema of the SARIF version written by -sarif
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifRules describes the rules of the SARIF report, one per diagnostic
+// category of the analyzer
+var sarifRules = []sarifRule{
+	{ID: sarifRuleID(recovercheck.KindGoroutine), ShortDescription: sarifMessage{Text: "goroutine created without panic recovery"}},
+	{ID: sarifRuleID(recovercheck.KindErrgroup), ShortDescription: sarifMessage{Text: "errgroup callback without panic recovery"}},
+	{ID: sarifRuleID(recovercheck.KindSpawn), ShortDescription: sarifMessage{Text: "goroutine spawned by a worker pool without panic recovery"}},
+	{ID: sarifRuleID(recovercheck.KindMain), ShortDescription: sarifMessage{Text: "goroutine created in main() without panic recovery"}},
+	{ID: sarifRuleID(recovercheck.KindNote), ShortDescription: sarifMessage{Text: "goroutine without panic recovery in a test"}},
+	{ID: sarifRuleID(recovercheck.KindSwallowed), ShortDescription: sarifMessage{Text: "goroutine recovery discards the recovered panic value"}},
+	{ID: sarifRuleID(recovercheck.KindTruncated), ShortDescription: sarifMessage{Text: "further findings omitted after -max-findings was reached"}},
+}
+
+// sarifRuleID returns the ID of the rule of a diagnostic category
+func sarifRuleID(category string) string {
+	return "recovercheck/" + category
+}
+
+// sarifLog is the root of a SARIF 2.1.0 report. Only the properties
+// recovercheck fills in are declared.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+// sarifFix is a suggested fix of a result, as the changes it makes to each
+// file
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+// sarifReplacement replaces the bytes of a file in DeletedRegion with
+// InsertedContent
+type sarifReplacement struct {
+	DeletedRegion   sarifByteRegion `json:"deletedRegion"`
+	InsertedContent sarifMessage    `json:"insertedContent"`
+}
+
+// sarifByteRegion is a region of a file given by byte offsets, which unlike
+// columns don't depend on how a viewer counts characters
+type sarifByteRegion struct {
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// writeSARIF writes the diagnostics of graph to path as a SARIF 2.1.0
+// report, with their suggested fixes. File URIs are relative to dir, the
+// source root, and diagnostics are reported at level severity, except for
+// notes and, always at level error, goroutines in main() reported by
+// -strict-main.
+func writeSARIF(path string, graph *checker.Graph, name, dir, severity string) error {
+	root, err := filepath.Abs(cmp.Or(dir, "."))
+	if err != nil {
+		return err
+	}
+
+	results := []sarifResult{}
+	for _, f := range baselineFindings(graph, root) {
+		ruleIndex := 0
+		for i, rule := range sarifRules {
+			if rule.ID == sarifRuleID(f.category) {
+				ruleIndex = i
+			}
+		}
+		level := severity
+		switch {
+		case f.category == recovercheck.KindMain:
+			level = "error"
+		case !recovercheck.IsFinding(f.category):
+			level = "note"
+		}
+		results = append(results, sarifResult{
+			RuleID:    sarifRules[ruleIndex].ID,
+			RuleIndex: ruleIndex,
+			Level:     level,
+			Message:   sarifMessage{Text: f.message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: f.file, URIBaseID: "%SRCROOT%"},
+					Region:           sarifRegion{StartLine: f.key.pos.Line, StartColumn: f.key.pos.Column},
+				},
+			}},
+			Fixes: sarifFixes(f, root),
+		})
+	}
+
+	report := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           name,
+				InformationURI: "https://github.com/cksidharthan/recovercheck",
+				Rules:          sarifRules,
+			}},
+			Results: results,
+		}},
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// sarifFixes returns the suggested fixes of a finding, with the edits to
+// each file in the order they are suggested and file URIs relative to root
+func sarifFixes(f finding, root string) []sarifFix {
+	var fixes []sarifFix
+	for _, fix := range f.fixes {
+		var changes []sarifArtifactChange
+		for _, edit := range fix.TextEdits {
+			file := f.fset.File(edit.Pos)
+			if file == nil {
+				continue
+			}
+			uri := relativePath(root, file.Name())
+			i := slices.IndexFunc(changes, func(c sarifArtifactChange) bool { return c.ArtifactLocation.URI == uri })
+			if i < 0 {
+				changes = append(changes, sarifArtifactChange{ArtifactLocation: sarifArtifactLocation{URI: uri, URIBaseID: "%SRCROOT%"}})
+				i = len(changes) - 1
+			}
+			offset := file.Offset(edit.Pos)
+			changes[i].Replacements = append(changes[i].Replacements, sarifReplacement{
+				DeletedRegion:   sarifByteRegion{ByteOffset: offset, ByteLength: file.Offset(edit.End) - offset},
+				InsertedContent: sarifMessage{Text: string(edit.NewText)},
+			})
+		}
+		if len(changes) > 0 {
+			fixes = append(fixes, sarifFix{Description: sarifMessage{Text: fix.Message}, ArtifactChanges: changes})
+		}
+	}
+	return fixes
+}
@@ -0,0 +1,159 @@
+package recovercheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// funcCandidates returns the functions a goroutine may run when it calls an
+// element of a collection of functions: a range variable, as in
+// for _, fn := range handlers { go fn() }, or an index expression, as in
+// go handlers[i](). The collection must be a composite literal, written in
+// place or assigned once to a local variable whose elements are never
+// assigned. It returns nil if the candidates can't be determined, and
+// without type information.
+func (r *Analyzer) funcCandidates(fun ast.Expr) []ast.Expr {
+	if r.Pass == nil || r.Pass.TypesInfo == nil {
+		return nil
+	}
+
+	switch fun := astutil.Unparen(fun).(type) {
+	case *ast.Ident:
+		v, ok := r.Pass.TypesInfo.Uses[fun].(*types.Var)
+		if !ok || !isLocalVar(v) {
+			return nil
+		}
+		rangeStmt := r.rangeStmtDefining(v)
+		if rangeStmt == nil {
+			return nil
+		}
+		// A range variable reassigned in the loop body may hold anything
+		if _, assignments := r.assignmentsOf(v); assignments != 0 {
+			return nil
+		}
+		return r.collectionElements(rangeStmt.X)
+	case *ast.IndexExpr:
+		if r.instantiatedFunc(fun) != nil {
+			return nil
+		}
+		return r.collectionElements(fun.X)
+	}
+	return nil
+}
+
+// rangeStmtDefining returns the range statement whose value variable is v,
+// as in for _, v := range x, or nil if v isn't one
+func (r *Analyzer) rangeStmtDefining(v *types.Var) *ast.RangeStmt {
+	var found *ast.RangeStmt
+	for _, file := range r.Pass.Files {
+		if v.Pos() < file.Pos() || v.Pos() > file.End() {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			rangeStmt, ok := n.(*ast.RangeStmt)
+			if !ok || found != nil {
+				return found == nil
+			}
+			if value, ok := rangeStmt.Value.(*ast.Ident); ok && rangeStmt.Tok == token.DEFINE && r.Pass.TypesInfo.Defs[value] == v {
+				found = rangeStmt
+			}
+			return found == nil
+		})
+	}
+	return found
+}
+
+// collectionElements returns the elements of a slice, array or map of
+// functions written as a composite literal, either directly or through a
+// local variable assigned it once whose elements are never assigned
+func (r *Analyzer) collectionElements(x ast.Expr) []ast.Expr {
+	x = astutil.Unparen(x)
+	if ident, ok := x.(*ast.Ident); ok {
+		v, ok := r.Pass.TypesInfo.Uses[ident].(*types.Var)
+		if !ok || !isLocalVar(v) || r.elementsAssigned(v) {
+			return nil
+		}
+		x = astutil.Unparen(r.assignedOnce(v))
+	}
+
+	lit, ok := x.(*ast.CompositeLit)
+	if !ok || len(lit.Elts) == 0 {
+		return nil
+	}
+	var elem types.Type
+	switch t := types.Unalias(r.Pass.TypesInfo.TypeOf(lit)).Underlying().(type) {
+	case *types.Slice:
+		elem = t.Elem()
+	case *types.Array:
+		elem = t.Elem()
+	case *types.Map:
+		elem = t.Elem()
+	}
+	if elem == nil {
+		return nil
+	}
+	if _, ok := elem.Underlying().(*types.Signature); !ok {
+		return nil
+	}
+
+	elements := make([]ast.Expr, 0, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		elements = append(elements, elt)
+	}
+	return elements
+}
+
+// elementsAssigned checks if an element of the collection v holds is
+// assigned, as in handlers[0] = fn, or has its address taken
+func (r *Analyzer) elementsAssigned(v *types.Var) bool {
+	isElement := func(expr ast.Expr) bool {
+		index, ok := astutil.Unparen(expr).(*ast.IndexExpr)
+		if !ok {
+			return false
+		}
+		id, ok := astutil.Unparen(index.X).(*ast.Ident)
+		return ok && r.objectOf(id) == v
+	}
+
+	assigned := false
+	for _, file := range r.Pass.Files {
+		if v.Pos() < file.Pos() || v.Pos() > file.End() {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				for _, lhs := range n.Lhs {
+					assigned = assigned || isElement(lhs)
+				}
+			case *ast.UnaryExpr:
+				assigned = assigned || (n.Op == token.AND && isElement(n.X))
+			}
+			return !assigned
+		})
+	}
+	return assigned
+}
+
+// unrecoveredCandidate names the first function a goroutine running an
+// element of a collection may run without recovery, such as "risky" or
+// "function literal on line 12", or returns "" if there is none
+func (r *Analyzer) unrecoveredCandidate(fun ast.Expr) string {
+	for _, candidate := range r.funcCandidates(fun) {
+		if r.isRecoveringFuncValue(candidate) {
+			continue
+		}
+		if funcLit, ok := astutil.Unparen(candidate).(*ast.FuncLit); ok {
+			return fmt.Sprintf("function literal on line %d", r.Pass.Fset.Position(funcLit.Pos()).Line)
+		}
+		return types.ExprString(candidate)
+	}
+	return ""
+}
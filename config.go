@@ -0,0 +1,451 @@
+package recovercheck
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// configFileName is the per-directory configuration file. It is searched for
+// in the directory of each analyzed file and every parent directory.
+const configFileName = ".recovercheck.yaml"
+
+// configEntry is one key of a configuration file. Scalars have a single value.
+type configEntry struct {
+	key    string
+	values []string
+	list   bool
+	line   int
+}
+
+// configFile is a parsed configuration file
+type configFile struct {
+	path    string
+	entries []configEntry
+}
+
+// configSetting describes a key a configuration file may set: the flag that
+// takes precedence over it, if any, and how to apply its value
+type configSetting struct {
+	flag  string
+	apply func(settings *RecovercheckSettings, entry configEntry) error
+}
+
+// configSettings lists the keys accepted in configuration files
+var configSettings = map[string]configSetting{
+	"flagDetachedInMain":          boolSetting("flag-detached-in-main", func(s *RecovercheckSettings) *bool { return &s.FlagDetachedInMain }),
+	"assumeInterfaceMethodsSafe":  boolSetting("assume-interface-methods-safe", func(s *RecovercheckSettings) *bool { return &s.AssumeInterfaceMethodsSafe }),
+	"assumeExternalSafe":          boolSetting("assume-external-safe", func(s *RecovercheckSettings) *bool { return &s.AssumeExternalSafe }),
+	"httpHandlerSeverity":         boolSetting("http-handler-severity", func(s *RecovercheckSettings) *bool { return &s.HTTPHandlerSeverity }),
+	"flagGuardedRecover":          boolSetting("flag-guarded-recover", func(s *RecovercheckSettings) *bool { return &s.FlagGuardedRecover }),
+	"detectRepanic":               boolSetting("detect-repanic", func(s *RecovercheckSettings) *bool { return &s.DetectRepanic }),
+	"warnSwallowedRecover":        boolSetting("warn-swallowed-recover", func(s *RecovercheckSettings) *bool { return &s.WarnSwallowedRecover }),
+	"errgroupStrict":              boolSetting("errgroup-strict", func(s *RecovercheckSettings) *bool { return &s.ErrgroupStrict }),
+	"requireUnconditionalRecover": boolSetting("require-unconditional-recover", func(s *RecovercheckSettings) *bool { return &s.RequireUnconditionalRecover }),
+	"requireHandledRecover":       boolSetting("require-handled-recover", func(s *RecovercheckSettings) *bool { return &s.RequireHandledRecover }),
+	"flagMustCalls":               boolSetting("flag-must-calls", func(s *RecovercheckSettings) *bool { return &s.FlagMustCalls }),
+	"deepAnalysis":                boolSetting("deep-analysis", func(s *RecovercheckSettings) *bool { return &s.DeepAnalysis }),
+	"strictMain":                  boolSetting("strict-main", func(s *RecovercheckSettings) *bool { return &s.StrictMain }),
+	"noteTestGoroutines":          boolSetting("note-test-goroutines", func(s *RecovercheckSettings) *bool { return &s.NoteTestGoroutines }),
+	"skipGenerated":               boolSetting("skip-generated", func(s *RecovercheckSettings) *bool { return &s.SkipGenerated }),
+	"skipSelectLoops":             boolSetting("skip-select-loops", func(s *RecovercheckSettings) *bool { return &s.SkipSelectLoops }),
+	"skipTrivialBodies":           boolSetting("skip-trivial-bodies", func(s *RecovercheckSettings) *bool { return &s.SkipTrivialBodies }),
+	"flagGoroutinesInLoops":       boolSetting("flag-goroutines-in-loops", func(s *RecovercheckSettings) *bool { return &s.FlagGoroutinesInLoops }),
+	"skipTestFiles":               boolSetting("", func(s *RecovercheckSettings) *bool { return &s.SkipTestFiles }),
+	"includeFuncRegex":            stringSetting("include-func-regex", func(s *RecovercheckSettings) *string { return &s.IncludeFuncRegex }),
+	"excludeFuncRegex":            stringSetting("exclude-func-regex", func(s *RecovercheckSettings) *string { return &s.ExcludeFuncRegex }),
+	"packageKind":                 stringSetting("package-kind", func(s *RecovercheckSettings) *string { return &s.PackageKind }),
+	"reportAt":                    stringSetting("report-at", func(s *RecovercheckSettings) *string { return &s.ReportAt }),
+	"cacheDir":                    stringSetting("cache-dir", func(s *RecovercheckSettings) *string { return &s.CacheDir }),
+	"since": {
+		flag: "since",
+		apply: func(s *RecovercheckSettings, entry configEntry) error {
+			value, err := entry.scalar()
+			if err != nil {
+				return err
+			}
+			since, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			s.Since = since
+			return nil
+		},
+	},
+	"maxFindings": {
+		flag: "max-findings",
+		apply: func(s *RecovercheckSettings, entry configEntry) error {
+			value, err := entry.scalar()
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", value)
+			}
+			s.MaxFindings = n
+			return nil
+		},
+	},
+	"spawnFuncs": {
+		flag: "spawn-func",
+		apply: func(s *RecovercheckSettings, entry configEntry) error {
+			for _, spec := range entry.values {
+				if _, err := ParseSpawnFunc(spec); err != nil {
+					return err
+				}
+			}
+			s.SpawnFuncs = append([]string(nil), entry.values...)
+			return nil
+		},
+	},
+	"trustedSpawners": {
+		flag: "trusted-spawner",
+		apply: func(s *RecovercheckSettings, entry configEntry) error {
+			for _, spec := range entry.values {
+				if _, err := ParseSpawnFunc(spec); err != nil {
+					return err
+				}
+			}
+			s.TrustedSpawners = append([]string(nil), entry.values...)
+			return nil
+		},
+	},
+	"safeFuncs": {
+		flag: "safe-func",
+		apply: func(s *RecovercheckSettings, entry configEntry) error {
+			if _, err := parseSafeFuncs(entry.values); err != nil {
+				return err
+			}
+			s.SafeFuncs = append([]string(nil), entry.values...)
+			return nil
+		},
+	},
+}
+
+// boolSetting returns a configSetting for the boolean setting field points to
+func boolSetting(flagName string, field func(*RecovercheckSettings) *bool) configSetting {
+	return configSetting{
+		flag: flagName,
+		apply: func(s *RecovercheckSettings, entry configEntry) error {
+			value, err := entry.scalar()
+			if err != nil {
+				return err
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", value)
+			}
+			*field(s) = b
+			return nil
+		},
+	}
+}
+
+// stringSetting returns a configSetting for the string setting field points to
+func stringSetting(flagName string, field func(*RecovercheckSettings) *string) configSetting {
+	return configSetting{
+		flag: flagName,
+		apply: func(s *RecovercheckSettings, entry configEntry) error {
+			value, err := entry.scalar()
+			if err != nil {
+				return err
+			}
+			*field(s) = value
+			return nil
+		},
+	}
+}
+
+// scalar returns the entry's single value
+func (e configEntry) scalar() (string, error) {
+	if e.list || len(e.values) != 1 {
+		return "", errors.New("expected a single value, got a list")
+	}
+	return e.values[0], nil
+}
+
+// parseConfig parses a configuration file. Only a flat subset of YAML is
+// supported: top-level "key: value" pairs, whose value is a scalar, an inline
+// list such as [a, b] or a block list of "- item" lines, plus comments.
+func parseConfig(data []byte) ([]configEntry, error) {
+	var entries []configEntry
+	var current *configEntry
+
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNum := i + 1
+		line = strings.TrimRight(stripComment(line), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			item, ok := strings.CutPrefix(trimmed, "-")
+			if !ok || current == nil || (len(current.values) > 0 && !current.list) {
+				return nil, fmt.Errorf("line %d: unexpected indentation", lineNum)
+			}
+			current.list = true
+			current.values = append(current.values, unquote(strings.TrimSpace(item)))
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNum)
+		}
+		for _, entry := range entries {
+			if entry.key == key {
+				return nil, fmt.Errorf("line %d: duplicate key %q", lineNum, key)
+			}
+		}
+
+		entries = append(entries, configEntry{key: key, line: lineNum})
+		current = &entries[len(entries)-1]
+
+		value = strings.TrimSpace(value)
+		switch {
+		case value == "":
+			// A block list may follow
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			current.list = true
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					current.values = append(current.values, unquote(item))
+				}
+			}
+		default:
+			current.values = []string{unquote(value)}
+		}
+	}
+
+	for _, entry := range entries {
+		if len(entry.values) == 0 && !entry.list {
+			return nil, fmt.Errorf("line %d: missing value for %q", entry.line, entry.key)
+		}
+	}
+	return entries, nil
+}
+
+// stripComment removes a trailing # comment from line, ignoring # inside quotes
+func stripComment(line string) string {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote removes matching single or double quotes around value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// configLoader finds the configuration files that apply to each analyzed
+// package and caches them by directory. It also records which flags were set
+// explicitly, since those take precedence over every configuration file.
+type configLoader struct {
+	mu       sync.Mutex
+	files    map[string]*configFile // directory -> its configuration file, nil if none
+	explicit map[string]bool        // flag name -> set on the command line
+}
+
+func newConfigLoader() *configLoader {
+	return &configLoader{
+		files:    make(map[string]*configFile),
+		explicit: make(map[string]bool),
+	}
+}
+
+// trackFlags records when any of flags is set, so that configuration files
+// do not override it
+func (l *configLoader) trackFlags(flags *flag.FlagSet) {
+	flags.VisitAll(func(f *flag.Flag) {
+		tracked := &trackedFlag{Value: f.Value, name: f.Name, loader: l}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			f.Value = &trackedBoolFlag{tracked}
+			return
+		}
+		f.Value = tracked
+	})
+}
+
+// trackedFlag wraps a flag.Value to record that the flag was set
+type trackedFlag struct {
+	flag.Value
+	name   string
+	loader *configLoader
+}
+
+// String is nil-safe, since the flag package calls it on a zero value of
+// each flag to tell whether its default is worth printing
+func (f *trackedFlag) String() string {
+	if f.Value == nil {
+		return ""
+	}
+	return f.Value.String()
+}
+
+func (f *trackedFlag) Set(value string) error {
+	if err := f.Value.Set(value); err != nil {
+		return err
+	}
+	f.loader.mu.Lock()
+	f.loader.explicit[f.name] = true
+	f.loader.mu.Unlock()
+	return nil
+}
+
+// trackedBoolFlag is a trackedFlag for a boolean flag, which keeps accepting
+// -name without a value
+type trackedBoolFlag struct {
+	*trackedFlag
+}
+
+func (f *trackedBoolFlag) IsBoolFlag() bool {
+	return true
+}
+
+// String returns false for a zero trackedBoolFlag, so that a false default
+// isn't printed, as for other boolean flags
+func (f *trackedBoolFlag) String() string {
+	if f.trackedFlag == nil {
+		return "false"
+	}
+	return f.trackedFlag.String()
+}
+
+// settingsFor returns the settings for the package analyzed by pass: base,
+// overridden by every configuration file from the filesystem root down to
+// the directory of each analyzed file, so nearer files win. Files are
+// located by their position, which follows //line directives, since cgo
+// packages are analyzed from generated files in the build cache that point
+// back to their sources. Should the files of a package lie in several
+// directories, deeper configuration files win. Settings whose flag was set
+// explicitly keep their flag value. If no configuration file applies, base
+// is returned unchanged.
+func (l *configLoader) settingsFor(pass *analysis.Pass, base *RecovercheckSettings) (*RecovercheckSettings, error) {
+	if l == nil || base == nil {
+		return base, nil
+	}
+
+	dirs := make(map[string]bool)
+	for _, file := range pass.Files {
+		name := pass.Fset.Position(file.Package).Filename
+		if name == "" {
+			continue
+		}
+		if dir, err := filepath.Abs(filepath.Dir(name)); err == nil {
+			dirs[dir] = true
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var files []*configFile
+	for dir := range dirs {
+		for {
+			if seen[dir] {
+				break // so are its parents
+			}
+			seen[dir] = true
+			configFile, err := l.load(dir)
+			if err != nil {
+				return nil, err
+			}
+			if configFile != nil {
+				files = append(files, configFile)
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	// Order the files from the deepest, then by path so that the order
+	// doesn't depend on map iteration
+	slices.SortFunc(files, func(a, b *configFile) int {
+		return cmp.Or(
+			cmp.Compare(pathDepth(b.path), pathDepth(a.path)),
+			cmp.Compare(a.path, b.path),
+		)
+	})
+	if len(files) == 0 {
+		return base, nil
+	}
+
+	settings := *base
+	for i := len(files) - 1; i >= 0; i-- {
+		for _, entry := range files[i].entries {
+			setting, ok := configSettings[entry.key]
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: unknown key %q", files[i].path, entry.line, entry.key)
+			}
+			if setting.flag != "" && l.explicit[setting.flag] {
+				continue
+			}
+			if err := setting.apply(&settings, entry); err != nil {
+				return nil, fmt.Errorf("%s:%d: %s: %w", files[i].path, entry.line, entry.key, err)
+			}
+		}
+	}
+	return &settings, nil
+}
+
+// pathDepth counts the elements of the cleaned absolute path
+func pathDepth(path string) int {
+	return strings.Count(filepath.ToSlash(path), "/")
+}
+
+// load returns the parsed configuration file in dir, or nil if there is none.
+// l.mu must be held.
+func (l *configLoader) load(dir string) (*configFile, error) {
+	if configFile, ok := l.files[dir]; ok {
+		return configFile, nil
+	}
+
+	path := filepath.Join(dir, configFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		l.files[dir] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	configFile := &configFile{path: path, entries: entries}
+	l.files[dir] = configFile
+	return configFile, nil
+}
@@ -0,0 +1,125 @@
+package recovercheck
+
+import (
+	"cmp"
+	"go/token"
+	"go/types"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// PackageCoverage reports how many goroutines of a package recover from panics
+type PackageCoverage struct {
+	Package   string  `json:"package"`
+	Total     int     `json:"total"`
+	Recovered int     `json:"recovered"`
+	Coverage  float64 `json:"coverage"` // Recovered / Total, 1 when there are no goroutines
+}
+
+// CoverageCollector accumulates per-package recovery coverage across passes.
+// Like SummaryCollector, it rewrites Path after every pass when set.
+//
+// Goroutines are counted once by position, and test variants of a package
+// are folded into it, since a package with test files is analyzed again with
+// them, see coveragePackage.
+type CoverageCollector struct {
+	Path string
+
+	mu       sync.Mutex
+	packages map[string]map[token.Position]bool // package path -> goroutine position -> recovered
+}
+
+// AddPackage registers a package so it is reported even without goroutines
+func (c *CoverageCollector) AddPackage(pkgPath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packageLocked(pkgPath)
+}
+
+// Record counts a goroutine of a package started at position and whether it
+// recovers. A goroutine already recorded is counted once.
+func (c *CoverageCollector) Record(pkgPath string, position token.Position, recovered bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packageLocked(pkgPath)[position] = recovered
+}
+
+// Packages returns the coverage of every recorded package sorted by path
+func (c *CoverageCollector) Packages() []PackageCoverage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sortedPackages()
+}
+
+// WriteJSON writes the coverage of every recorded package as a JSON array
+func (c *CoverageCollector) WriteJSON(w io.Writer) error {
+	return writeJSON(w, nonNil(c.Packages()))
+}
+
+// Flush writes the coverage report to Path, if set
+func (c *CoverageCollector) Flush() error {
+	if c.Path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return writeJSONFile(c.Path, nonNil(c.sortedPackages()))
+}
+
+// packageLocked returns the goroutines recorded for pkgPath, creating the
+// entry if needed; the caller must hold c.mu
+func (c *CoverageCollector) packageLocked(pkgPath string) map[token.Position]bool {
+	if c.packages == nil {
+		c.packages = make(map[string]map[token.Position]bool)
+	}
+	goroutines, ok := c.packages[pkgPath]
+	if !ok {
+		goroutines = make(map[token.Position]bool)
+		c.packages[pkgPath] = goroutines
+	}
+	return goroutines
+}
+
+// sortedPackages returns the entries with coverage computed; the caller must
+// hold c.mu
+func (c *CoverageCollector) sortedPackages() []PackageCoverage {
+	packages := make([]PackageCoverage, 0, len(c.packages))
+	for pkgPath, goroutines := range c.packages {
+		entry := PackageCoverage{Package: pkgPath, Total: len(goroutines), Coverage: 1}
+		for _, recovered := range goroutines {
+			if recovered {
+				entry.Recovered++
+			}
+		}
+		if entry.Total > 0 {
+			entry.Coverage = float64(entry.Recovered) / float64(entry.Total)
+		}
+		packages = append(packages, entry)
+	}
+	slices.SortFunc(packages, func(a, b PackageCoverage) int {
+		return cmp.Compare(a.Package, b.Package)
+	})
+	return packages
+}
+
+// coveragePackage returns the path coverage of pkg is recorded under: the
+// path of the package under test for an external test package such as
+// example.com/m_test and for the generated test main package
+// example.com/m.test, and the path of pkg otherwise
+func coveragePackage(pkg *types.Package) string {
+	path := pkg.Path()
+	switch {
+	case strings.HasSuffix(pkg.Name(), "_test"):
+		return strings.TrimSuffix(path, "_test")
+	case pkg.Name() == "main" && strings.HasSuffix(path, ".test"):
+		return strings.TrimSuffix(path, ".test")
+	}
+	return path
+}
@@ -0,0 +1,34 @@
+package recovercheck
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"sync"
+)
+
+// debugMu serializes debug lines of passes running concurrently
+var debugMu sync.Mutex
+
+// debugf logs a step of a classification decision when Debug is set. The line
+// is prefixed with the position of pos, unless pos is token.NoPos.
+func (r *Analyzer) debugf(pos token.Pos, format string, args ...any) {
+	if r.Settings == nil || !r.Settings.Debug {
+		return
+	}
+
+	var w io.Writer = os.Stderr
+	if r.Settings.DebugOutput != nil {
+		w = r.Settings.DebugOutput
+	}
+
+	line := fmt.Sprintf(format, args...)
+	if pos.IsValid() && r.Pass != nil && r.Pass.Fset != nil {
+		line = r.Pass.Fset.Position(pos).String() + ": " + line
+	}
+
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	fmt.Fprintln(w, "recovercheck: debug: "+line)
+}
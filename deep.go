@@ -0,0 +1,92 @@
+package recovercheck
+
+import "go/ast"
+
+// deepAnalysisDepth bounds how many calls deep DeepAnalysis follows
+const deepAnalysisDepth = 5
+
+// hasDeepRecovery checks if every call path from a goroutine's function,
+// up to deepAnalysisDepth calls deep, passes through a function that
+// establishes recovery.
+//
+// Only calls to functions and methods declared in the current package are
+// followed; calls to anything else, such as function values or other
+// packages, are not considered paths. A path ends at a function that
+// recovers, at a function without such calls, at the depth limit or at a
+// recursive call; only the first counts as protected. Recovery in a callee
+// only protects panics raised within that callee, so this is a heuristic
+// that trades false negatives for fewer reports.
+func (r *Analyzer) hasDeepRecovery(fn ast.Expr) bool {
+	var body *ast.BlockStmt
+	switch fn := r.funcValue(fn).(type) {
+	case *ast.FuncLit:
+		body = fn.Body
+	case *ast.Ident, *ast.SelectorExpr:
+		if funcDecl := r.funcDeclOf(fn); funcDecl != nil {
+			body = funcDecl.Body
+		}
+	}
+	if body == nil {
+		return false
+	}
+
+	return r.deepRecovers(body, 0, make(map[*ast.FuncDecl]bool))
+}
+
+// deepRecovers checks if every call path from body is protected, per
+// hasDeepRecovery. visiting holds the functions on the current path.
+func (r *Analyzer) deepRecovers(body *ast.BlockStmt, depth int, visiting map[*ast.FuncDecl]bool) bool {
+	if r.containsRecover(body) {
+		return true
+	}
+	if depth >= deepAnalysisDepth {
+		return false
+	}
+
+	callees := r.staticCallees(body)
+	if len(callees) == 0 {
+		return false
+	}
+
+	for _, callee := range callees {
+		if visiting[callee] || callee.Body == nil {
+			return false
+		}
+		visiting[callee] = true
+		protected := r.deepRecovers(callee.Body, depth+1, visiting)
+		delete(visiting, callee)
+		if !protected {
+			return false
+		}
+	}
+	return true
+}
+
+// staticCallees returns the declarations of the functions and methods of the
+// current package that body calls directly. Calls inside nested function
+// literals are not included, since those literals may run elsewhere, such as
+// in another goroutine.
+func (r *Analyzer) staticCallees(body *ast.BlockStmt) []*ast.FuncDecl {
+	var callees []*ast.FuncDecl
+	seen := make(map[*ast.FuncDecl]bool)
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			return !isMalformedCall(n.Call)
+		case *ast.CallExpr:
+			fn := r.funcObjectOf(n.Fun)
+			if fn == nil || fn.Pkg() != r.Pass.Pkg || !fn.Pos().IsValid() {
+				break
+			}
+			if funcDecl := r.findFuncDecl(fn.Name(), fn.Pos()); funcDecl != nil && !seen[funcDecl] {
+				seen[funcDecl] = true
+				callees = append(callees, funcDecl)
+			}
+		}
+		return true
+	})
+	return callees
+}
@@ -0,0 +1,115 @@
+package recovercheck
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// returnsPanicAsError checks if an errgroup callback literal that recovers
+// turns the recovered panic into its error result, so that Wait reports it.
+// The result must be named, as in func() (err error), and either assigned
+// from the recovered value by a deferred function literal, or passed by
+// address to a deferred call such as defer recoverToError(&err). Callbacks
+// other than function literals aren't inspected.
+func (r *Analyzer) returnsPanicAsError(fn ast.Expr) bool {
+	funcLit, ok := r.funcValue(fn).(*ast.FuncLit)
+	if !ok {
+		return true
+	}
+	result := namedErrorResult(funcLit.Type)
+	if result == "" {
+		return false
+	}
+
+	finder := r.recoverFinder()
+	for _, stmt := range funcLit.Body.List {
+		deferStmt, ok := stmt.(*ast.DeferStmt)
+		if !ok || isMalformedCall(deferStmt.Call) {
+			continue
+		}
+		if passesAddressOf(deferStmt.Call, result) {
+			return true
+		}
+		if deferred, ok := r.funcValue(deferStmt.Call.Fun).(*ast.FuncLit); ok && finder.assignsRecovered(deferred.Body, result) {
+			return true
+		}
+	}
+	return false
+}
+
+// namedErrorResult returns the name of a function's only result, or "" if
+// the result is unnamed or blank
+func namedErrorResult(funcType *ast.FuncType) string {
+	if funcType.Results == nil || len(funcType.Results.List) != 1 {
+		return ""
+	}
+	names := funcType.Results.List[0].Names
+	if len(names) != 1 || names[0].Name == "_" {
+		return ""
+	}
+	return names[0].Name
+}
+
+// passesAddressOf checks if call passes &name as an argument
+func passesAddressOf(call *ast.CallExpr, name string) bool {
+	for _, arg := range call.Args {
+		unary, ok := arg.(*ast.UnaryExpr)
+		if !ok || unary.Op != token.AND {
+			continue
+		}
+		if ident, ok := unary.X.(*ast.Ident); ok && ident.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// assignsRecovered checks if a deferred function body assigns result from
+// the value it recovers: either recover() itself or a variable bound by
+// r := recover(), as in err = fmt.Errorf("panic: %v", r)
+func (f *recoverFinder) assignsRecovered(body *ast.BlockStmt, result string) bool {
+	recovered := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if assign, ok := n.(*ast.AssignStmt); ok {
+			if name := f.recoveredVar(assign); name != "" {
+				recovered[name] = true
+			}
+		}
+		return true
+	})
+
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || found {
+			return !found
+		}
+		for i, lhs := range assign.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok || ident.Name != result || i >= len(assign.Rhs) {
+				continue
+			}
+			if f.usesRecovered(assign.Rhs[i], recovered) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// usesRecovered checks if expr calls recover() or refers to one of the
+// recovered variables
+func (f *recoverFinder) usesRecovered(expr ast.Expr, recovered map[string]bool) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			found = f.isRecoverCall(n)
+		case *ast.Ident:
+			found = recovered[n.Name]
+		}
+		return !found
+	})
+	return found
+}
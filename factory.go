@@ -0,0 +1,68 @@
+package recovercheck
+
+import "go/ast"
+
+// returnsRecoveringFunc checks if a call such as factory() returns a function
+// with panic recovery, for go statements like go factory()(). The factory must
+// be declared where the analyzer can read its body, and every value it
+// returns must recover.
+func (r *Analyzer) returnsRecoveringFunc(call *ast.CallExpr) bool {
+	funcDecl := r.funcDeclOf(call.Fun)
+	if funcDecl == nil || funcDecl.Body == nil || r.tracingFactories[funcDecl] {
+		return false
+	}
+
+	results, ok := returnedValues(funcDecl.Body)
+	if !ok || len(results) == 0 {
+		return false
+	}
+
+	// Factories returning their own result, directly or through others,
+	// are not traced again
+	if r.tracingFactories == nil {
+		r.tracingFactories = make(map[*ast.FuncDecl]bool)
+	}
+	r.tracingFactories[funcDecl] = true
+	defer delete(r.tracingFactories, funcDecl)
+
+	for _, result := range results {
+		if !r.isRecoveringFuncValue(result) {
+			return false
+		}
+	}
+	return true
+}
+
+// returnedValues returns the values returned by a function body of a single
+// result. It reports false if a bare return hides a value behind a named
+// result. Returns inside function literals belong to the literals and are
+// not included.
+func returnedValues(body *ast.BlockStmt) ([]ast.Expr, bool) {
+	var results []ast.Expr
+	ok := true
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) != 1 {
+				ok = false
+				return false
+			}
+			results = append(results, n.Results[0])
+		}
+		return ok
+	})
+	return results, ok
+}
+
+// isUnresolvedFactoryCall checks if fun is a call, as in go factory()(), whose
+// function can't be traced to a declaration to find the value it returns
+func (r *Analyzer) isUnresolvedFactoryCall(fun ast.Expr) bool {
+	call, ok := r.funcValue(fun).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	funcDecl := r.funcDeclOf(call.Fun)
+	return funcDecl == nil || funcDecl.Body == nil
+}
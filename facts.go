@@ -0,0 +1,119 @@
+package recovercheck
+
+import (
+	"go/types"
+	"reflect"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// recoversFact records whether a function or method contains recovery logic,
+// and whether it stops a panic when deferred. It is exported for every
+// declared function of every analyzed package, so importers can classify
+// calls into a package without its syntax. Facts are computed from the
+// syntax the driver loaded, including overlays and unsaved edits, so they
+// are preferred over parsing the package's files from disk.
+type recoversFact struct {
+	Recovers bool
+	Handles  bool // calls recover itself, as a deferred function must
+}
+
+func (*recoversFact) AFact() {}
+
+func (f *recoversFact) String() string {
+	if f.Recovers {
+		return "recovers"
+	}
+	return "no recovery"
+}
+
+// recoverFacts is the result of the facts analyzer. It looks up the facts
+// exported for functions of the analyzed package's dependencies.
+type recoverFacts struct {
+	pass *analysis.Pass
+}
+
+// lookup returns the fact exported for fn, and false for ok if there is none
+func (f *recoverFacts) lookup(fn *types.Func) (fact recoversFact, ok bool) {
+	ok = f.pass.ImportObjectFact(fn.Origin(), &fact)
+	return fact, ok
+}
+
+// newFactsAnalyzer returns the analyzer that exports a recoversFact for each
+// function. It runs on every dependency of the checked packages, so it is
+// kept separate from the main analyzer, which only reports on the packages
+// being checked.
+func newFactsAnalyzer(settings *RecovercheckSettings, loader *configLoader) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:       "recovercheckfacts",
+		Doc:        "Records which functions have panic recovery logic",
+		Requires:   []*analysis.Analyzer{inspect.Analyzer},
+		FactTypes:  []analysis.Fact{(*recoversFact)(nil)},
+		ResultType: reflect.TypeOf((*recoverFacts)(nil)),
+		Run: func(pass *analysis.Pass) (any, error) {
+			// Dependencies are not checked, so a broken configuration file
+			// is only reported by the main analyzer
+			config, err := loader.settingsFor(pass, settings)
+			if err != nil {
+				config = settings
+			}
+			return runFacts(pass, config)
+		},
+	}
+}
+
+// runFacts exports a recoversFact for each function declared in the package
+func runFacts(pass *analysis.Pass, settings *RecovercheckSettings) (any, error) {
+	facts := &recoverFacts{pass: pass}
+	analyzer := &Analyzer{
+		Pass:             pass,
+		RecoverFunctions: make(map[string]bool),
+		Settings:         settings,
+		facts:            facts,
+	}
+	if settings != nil {
+		// Invalid safe functions are reported by the main analyzer
+		analyzer.safeFuncs, _ = parseSafeFuncs(settings.SafeFuncs)
+	}
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodes := CollectNodes(insp)
+	analyzer.AnalyzeFunctions(nodes.FunctionDecls)
+
+	for _, funcDecl := range nodes.FunctionDecls {
+		fn, ok := pass.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		pass.ExportObjectFact(fn, &recoversFact{
+			Recovers: analyzer.containsRecover(funcDecl.Body),
+			Handles:  analyzer.recoverFinder().handlesRecover(funcDecl.Body),
+		})
+	}
+
+	return facts, nil
+}
+
+// recoversByFact classifies a function of another package by the fact
+// exported for it, reporting false for ok if there is none
+func (r *Analyzer) recoversByFact(fn *types.Func) (recovers, ok bool) {
+	fact, ok := r.factOf(fn)
+	return fact.Recovers, ok
+}
+
+// handlesByFact classifies a deferred function of another package by the
+// fact exported for it, reporting false for ok if there is none
+func (r *Analyzer) handlesByFact(fn *types.Func) (handles, ok bool) {
+	fact, ok := r.factOf(fn)
+	return fact.Handles, ok
+}
+
+// factOf returns the fact exported for a function of another package
+func (r *Analyzer) factOf(fn *types.Func) (recoversFact, bool) {
+	if r.facts == nil || fn == nil || fn.Pkg() == nil || fn.Pkg() == r.Pass.Pkg {
+		return recoversFact{}, false
+	}
+	return r.facts.lookup(fn)
+}
@@ -0,0 +1,122 @@
+package recovercheck
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// recoveryHandler is deferred at the top of a function by the suggested fix
+// for go statements running it
+const recoveryHandler = `
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("recovered from panic:", r)
+		}
+	}()`
+
+// recoveryFixes suggests deferring recovery at the top of the named function
+// or method a go statement runs. It is only offered when the function is
+// declared in a file of the current pass and the log package can be referred
+// to as log, importing it if needed.
+func (r *Analyzer) recoveryFixes(fun ast.Expr) []analysis.SuggestedFix {
+	if r.Pass.TypesInfo == nil || r.Pass.Pkg == nil {
+		return nil
+	}
+	fun = r.funcValue(fun)
+	if generic := r.instantiatedFunc(fun); generic != nil {
+		fun = generic
+	}
+
+	fn := r.funcObjectOf(fun)
+	if fn == nil || fn.Pkg() != r.Pass.Pkg {
+		return nil
+	}
+
+	var file *ast.File
+	for _, f := range r.Pass.Files {
+		if f.FileStart <= fn.Pos() && fn.Pos() <= f.FileEnd {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return nil
+	}
+	funcDecl := funcDeclAt(file, fn.Name(), func(p token.Pos) bool { return p == fn.Pos() })
+	if funcDecl == nil || funcDecl.Body == nil {
+		return nil
+	}
+
+	importEdits, ok := r.logImportEdits(file, funcDecl)
+	if !ok {
+		return nil
+	}
+
+	body := funcDecl.Body
+	text := []byte(recoveryHandler)
+	if len(body.List) == 0 && r.Pass.Fset.Position(body.Lbrace).Line == r.Pass.Fset.Position(body.Rbrace).Line {
+		text = append(text, '\n')
+	}
+
+	return []analysis.SuggestedFix{{
+		Message: "Add deferred panic recovery to " + fn.Name(),
+		TextEdits: append(importEdits, analysis.TextEdit{
+			Pos:     body.Lbrace + 1,
+			End:     body.Lbrace + 1,
+			NewText: text,
+		}),
+	}}
+}
+
+// logImportEdits returns the edits that make the log package available as
+// log in file, none if it already is. It reports false if log can't be
+// used there: another import or declaration takes the name, log is imported
+// under another name, or funcDecl shadows it.
+func (r *Analyzer) logImportEdits(file *ast.File, funcDecl *ast.FuncDecl) ([]analysis.TextEdit, bool) {
+	if scope := r.Pass.TypesInfo.Scopes[funcDecl.Type]; scope != nil && scope.Lookup("log") != nil {
+		return nil, false
+	}
+	if r.Pass.Pkg.Scope().Lookup("log") != nil {
+		return nil, false
+	}
+
+	imported := false
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, false
+		}
+		name := packageName(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		switch {
+		case path == "log" && name == "log":
+			imported = true
+		case path == "log" && name != "_":
+			return nil, false
+		case name == "log":
+			return nil, false
+		}
+	}
+	if imported {
+		return nil, true
+	}
+
+	// Add log to the first import declaration, or start one after the
+	// package clause
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			return []analysis.TextEdit{{Pos: gen.Lparen + 1, End: gen.Lparen + 1, NewText: []byte("\n\t\"log\"")}}, true
+		}
+		return []analysis.TextEdit{{Pos: gen.End(), End: gen.End(), NewText: []byte("\nimport \"log\"")}}, true
+	}
+	return []analysis.TextEdit{{Pos: file.Name.End(), End: file.Name.End(), NewText: []byte("\n\nimport \"log\"")}}, true
+}
@@ -0,0 +1,91 @@
+package recovercheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"regexp"
+)
+
+// funcFilter selects the go statements and spawn calls to analyze by the
+// name of their enclosing function
+type funcFilter struct {
+	include *regexp.Regexp // nil includes every function
+	exclude *regexp.Regexp // nil excludes none
+}
+
+// newFuncFilter compiles the IncludeFuncRegex and ExcludeFuncRegex settings
+func newFuncFilter(settings *RecovercheckSettings) (*funcFilter, error) {
+	filter := &funcFilter{}
+	if settings == nil {
+		return filter, nil
+	}
+
+	var err error
+	if settings.IncludeFuncRegex != "" {
+		if filter.include, err = regexp.Compile(settings.IncludeFuncRegex); err != nil {
+			return nil, fmt.Errorf("invalid include-func-regex: %w", err)
+		}
+	}
+	if settings.ExcludeFuncRegex != "" {
+		if filter.exclude, err = regexp.Compile(settings.ExcludeFuncRegex); err != nil {
+			return nil, fmt.Errorf("invalid exclude-func-regex: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+// matches checks if goroutines in the function named name are analyzed
+func (f *funcFilter) matches(name string) bool {
+	if f.include != nil && !f.include.MatchString(name) {
+		return false
+	}
+	return f.exclude == nil || !f.exclude.MatchString(name)
+}
+
+// isFilteredGoroutine checks if the include and exclude patterns skip a go
+// statement
+func (r *Analyzer) isFilteredGoroutine(goStmt *ast.GoStmt) bool {
+	if r.funcFilter == nil {
+		return false
+	}
+	return !r.funcFilter.matches(r.enclosingFuncName(r.GoContexts[goStmt]))
+}
+
+// isFilteredSpawnCall checks if the include and exclude patterns skip a call
+// to a spawn function, such as errgroup.Group.Go
+func (r *Analyzer) isFilteredSpawnCall(call *ast.CallExpr) bool {
+	if r.funcFilter == nil {
+		return false
+	}
+	return !r.funcFilter.matches(r.enclosingFuncName(r.SpawnContexts[call]))
+}
+
+// enclosingFuncName returns the fully qualified name of the function
+// declaration enclosing a go statement or spawn call with context ctx, as
+// printed by types.Func.FullName: example.com/pkg.Func or
+// (*example.com/pkg.Type).Method. Those in package-level variable
+// initializers are named example.com/pkg.Var, and others outside any
+// function by the package path alone.
+func (r *Analyzer) enclosingFuncName(ctx *GoContext) string {
+	pkgPath := ""
+	if r.Pass.Pkg != nil {
+		pkgPath = r.Pass.Pkg.Path()
+	}
+
+	switch {
+	case ctx == nil:
+		return pkgPath
+	case ctx.FuncDecl == nil && ctx.Var != nil:
+		return pkgPath + "." + ctx.Var.Name
+	case ctx.FuncDecl == nil:
+		return pkgPath
+	}
+
+	if r.Pass.TypesInfo != nil {
+		if fn, ok := r.Pass.TypesInfo.Defs[ctx.FuncDecl.Name].(*types.Func); ok {
+			return fn.FullName()
+		}
+	}
+	return pkgPath + "." + ctx.FuncDecl.Name.Name
+}
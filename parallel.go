@@ -0,0 +1,102 @@
+package recovercheck
+
+import (
+	"fmt"
+	"sync"
+)
+
+// classifyConcurrently calls classify for each of n items on up to Workers
+// goroutines and returns the results by index. Each worker classifies with
+// its own copy of the analyzer, sharing RecoverFunctions and the files parsed
+// from other packages under a lock. Anything that depends on order, such as
+// reporting, is left to the caller, which gets the same results as from a
+// serial run. A panic in a worker is re-raised in the caller.
+func (r *Analyzer) classifyConcurrently(n int, classify func(worker *Analyzer, i int) bool) []bool {
+	results := make([]bool, n)
+	workers := 1
+	if r.Settings != nil && r.Settings.Workers > 0 {
+		workers = r.Settings.Workers
+	}
+	if workers = min(workers, n); workers <= 1 {
+		for i := range n {
+			results[i] = classify(r, i)
+		}
+		return results
+	}
+
+	if r.RecoverFunctions == nil {
+		r.RecoverFunctions = make(map[string]bool)
+	}
+	if r.parsedFiles == nil {
+		r.parsedFiles = make(map[string]*parsedFile)
+	}
+	if r.cachedFiles == nil {
+		r.cachedFiles = make(map[string]*cachedFile)
+	}
+	r.mu = &sync.Mutex{}
+	defer func() { r.mu = nil }()
+
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicked any
+	indexes := make(chan int)
+	for range workers {
+		worker := *r
+		worker.tracingFactories = nil
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					panicOnce.Do(func() { panicked = p })
+					// Keep draining so the producer doesn't block
+					for range indexes {
+					}
+				}
+			}()
+			for i := range indexes {
+				results[i] = classify(&worker, i)
+			}
+		}()
+	}
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if panicked != nil {
+		panic(fmt.Sprintf("recovercheck: classifying concurrently: %v", panicked))
+	}
+	return results
+}
+
+// lockShared locks the state shared by concurrent workers. It does nothing
+// outside classifyConcurrently.
+func (r *Analyzer) lockShared() {
+	if r.mu != nil {
+		r.mu.Lock()
+	}
+}
+
+// unlockShared unlocks the state locked by lockShared
+func (r *Analyzer) unlockShared() {
+	if r.mu != nil {
+		r.mu.Unlock()
+	}
+}
+
+// cachedRecovery looks up a function's classification in RecoverFunctions
+func (r *Analyzer) cachedRecovery(key string) (hasRecover, exists bool) {
+	r.lockShared()
+	defer r.unlockShared()
+	hasRecover, exists = r.RecoverFunctions[key]
+	return hasRecover, exists
+}
+
+// cacheRecovery records a function's classification in RecoverFunctions
+func (r *Analyzer) cacheRecovery(key string, hasRecover bool) {
+	r.lockShared()
+	defer r.unlockShared()
+	r.RecoverFunctions[key] = hasRecover
+}
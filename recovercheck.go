@@ -1,38 +1,369 @@
 package recovercheck
 
 import (
+	"cmp"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"go/types"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/ast/inspector"
 )
 
-// RecovercheckSettings holds configuration options for the analyzer
+// safeDirective marks an interface method as providing panic recovery
+const safeDirective = "//recovercheck:safe"
+
+// Positions ReportAt accepts
+const (
+	reportAtGo   = "go"   // the go keyword
+	reportAtBody = "body" // the opening brace of the goroutine's function literal
+)
+
+// Kinds of package PackageKind accepts
+const (
+	packageKindAuto    = "auto"    // main for package main, library otherwise
+	packageKindLibrary = "library" // stricter: trusts nothing the analyzer can't see
+	packageKindMain    = "main"    // lenient with goroutines started directly in main
+)
+
+// RecovercheckSettings holds configuration options for the analyzer. The
+// analyzer returned by New overrides them per package with any
+// .recovercheck.yaml files found in the package's directory or its parents,
+// except for settings whose flag was set explicitly.
 type RecovercheckSettings struct {
-	// to be used in future.
+	// FlagDetachedInMain reports goroutines started from package main's main
+	// function that have neither panic recovery nor any synchronization with
+	// the main goroutine.
+	FlagDetachedInMain bool
+
+	// AssumeInterfaceMethodsSafe treats calls to interface methods, including
+	// methods promoted from embedded interfaces, as providing recovery. Such
+	// methods have no body to inspect, so by default they are assumed unsafe.
+	AssumeInterfaceMethodsSafe bool
+
+	// AssumeExternalSafe treats functions of other packages whose source
+	// can't be found or parsed, such as functions implemented in assembly or
+	// packages loaded from export data only, as providing recovery. By
+	// default they are assumed unsafe.
+	AssumeExternalSafe bool
+
+	// HTTPHandlerSeverity upgrades the diagnostic for unrecovered goroutines
+	// started inside a func(http.ResponseWriter, *http.Request), where a
+	// panic bypasses the server's per-request recovery and crashes the process.
+	HTTPHandlerSeverity bool
+
+	// RequireUnconditionalRecover only accepts recovery deferred at the top
+	// level of the goroutine's function body. A defer inside an if or loop,
+	// such as if debug { defer recoverHandler() }, may never be registered,
+	// so by default it counts as recovery but in this mode it does not.
+	RequireUnconditionalRecover bool
+
+	// FlagMustCalls adds the first call to a Must-style function, such as
+	// regexp.MustCompile or mustLoad, in an unrecovered function literal to
+	// the diagnostic as the likely panic site.
+	FlagMustCalls bool
+
+	// DeepAnalysis follows calls from a goroutine's function, up to five
+	// levels deep, and accepts the goroutine if every path reaches a function
+	// that recovers. A callee's recovery only protects panics within that
+	// callee, so this mode can miss unsafe goroutines.
+	DeepAnalysis bool
+
+	// RequireHandledRecover only accepts deferred recovery that uses the
+	// recovered value, for example by logging it. A bare recover() silently
+	// swallows the panic, which can be worse than crashing.
+	RequireHandledRecover bool
+
+	// SkipTestFiles ignores goroutines in _test.go files. Drivers that load
+	// packages themselves can usually exclude tests instead, as with the
+	// command's -test=false; this covers drivers that always include them.
+	SkipTestFiles bool
+
+	// StrictMain reports unrecovered goroutines started in func main of
+	// package main, the classic cause of a crashed server, with a dedicated
+	// message and the Category KindMain. The command fails on them even with
+	// -severity warning.
+	StrictMain bool
+
+	// PackageKind adapts the analysis to the kind of package: "library"
+	// ignores AssumeExternalSafe, AssumeInterfaceMethodsSafe,
+	// SkipSelectLoops and SkipTrivialBodies, since a panic in a library
+	// crashes every program using it; "main" doesn't report go statements
+	// directly in func main, whose panic crashes the command like a panic in
+	// main itself would, unless StrictMain or FlagDetachedInMain is set;
+	// "auto" picks main for package main and library otherwise. The
+	// default, "", applies neither.
+	PackageKind string
+
+	// NoteTestGoroutines downgrades the report of unrecovered goroutines
+	// started directly in functions taking a *testing.T in _test.go files
+	// to a note, with the Category KindNote: a panic there fails the test
+	// rather than crashing a production process.
+	NoteTestGoroutines bool
+
+	// SkipGenerated ignores goroutines in generated files, those with a
+	// "// Code generated ... DO NOT EDIT." header, which can't be fixed by
+	// hand.
+	SkipGenerated bool
+
+	// SkipSelectLoops suppresses reports for goroutines whose function is a
+	// worker loop, for { select { ... } } with a case <-ctx.Done(). Such
+	// loops can still panic, but they are common infrastructure whose
+	// lifetime is usually managed deliberately.
+	SkipSelectLoops bool
+
+	// SkipTrivialBodies, an experimental mode, suppresses reports for
+	// goroutines running a function literal with no operation that can
+	// panic: no calls, index, slice or type assertion expressions, pointer
+	// dereferences or integer divisions by a variable, as in
+	// go func() { done <- struct{}{} }(). A send on a closed channel still
+	// panics, so this mode can miss unsafe goroutines.
+	SkipTrivialBodies bool
+
+	// FlagGoroutinesInLoops adds a warning to the diagnostic of unrecovered
+	// go statements inside a for or range body, where every iteration starts
+	// another goroutine that can crash the process.
+	FlagGoroutinesInLoops bool
+
+	// IncludeFuncRegex, when set, only analyzes go statements and spawn
+	// calls whose enclosing function's fully qualified name matches it, such
+	// as example.com/pkg.Func or (*example.com/pkg.Type).Method.
+	IncludeFuncRegex string
+
+	// ExcludeFuncRegex, when set, skips go statements and spawn calls whose
+	// enclosing function's fully qualified name matches it. It applies after
+	// IncludeFuncRegex.
+	ExcludeFuncRegex string
+
+	// Since, when positive, only reports goroutines on lines changed within
+	// this duration according to Blame; older findings are suppressed.
+	Since time.Duration
+
+	// Blame reports line ages for Since. It defaults to git blame.
+	Blame BlameSource
+
+	// FlagGuardedRecover reports goroutines whose deferred function literal
+	// can return before reaching its only recover() call, for example
+	// defer func() { if cond { return }; recover() }().
+	FlagGuardedRecover bool
+
+	// SpawnFuncs lists functions and methods that run their function argument
+	// in a new goroutine, written as pkg.Func or pkg.Type.Method with a full
+	// import path and an optional :N argument index. Calls to them are
+	// checked like errgroup.Group.Go, in addition to the well-known pools
+	// recognized by default.
+	SpawnFuncs []string
+
+	// TrustedSpawners lists functions and methods, written like SpawnFuncs,
+	// that run their function argument with recovery the analyzer can't see,
+	// such as a library's safego.Run. Goroutines started by calling them,
+	// as in go safego.Run(fn), and their callbacks are not checked. A
+	// function listed in both SpawnFuncs and TrustedSpawners is trusted.
+	TrustedSpawners []string
+
+	// SafeFuncs lists functions and methods, written as pkg.Func or
+	// pkg.Type.Method with a full import path, that recover panics in their
+	// own body where the analyzer can't see it, such as a vendored
+	// safelib.Recover. Goroutines running them, as in go safelib.Run(fn),
+	// and defers of them, as in defer safelib.Recover(), count as
+	// recovery. Unlike TrustedSpawners, calls to them aren't taken to start
+	// goroutines. They are only matched with type information.
+	SafeFuncs []string
+
+	// DetectRepanic reports goroutines whose deferred function literal
+	// re-panics every value it recovers, for example
+	// defer func() { if r := recover(); r != nil { panic(r) } }(). Re-panics
+	// behind any other condition are treated as filtering and allowed.
+	DetectRepanic bool
+
+	// WarnSwallowedRecover reports a note for recovered goroutines whose
+	// deferred function literal discards the recovered value, assigning it
+	// to the blank identifier, as in defer func() { _ = recover() }(), or to
+	// a variable it never reads. Such goroutines still count as recovered,
+	// so the notes, of Category KindSwallowed, are left out of the result and
+	// the summary and don't count toward MaxFindings.
+	WarnSwallowedRecover bool
+
+	// ErrgroupStrict reports errgroup.Group.Go callbacks that recover a
+	// panic without returning it as the callback's error, so Wait reports
+	// success for a goroutine that failed.
+	ErrgroupStrict bool
+
+	// Workers bounds how many go statements and spawned callbacks of a
+	// package are classified concurrently. Zero or one classifies them
+	// serially, the default since drivers already analyze packages in
+	// parallel; more helps with few, large packages. Diagnostics are the same
+	// either way.
+	Workers int
+
+	// MaxFindings, when positive, caps the diagnostics reported per
+	// package. Once it is reached, a single note "additional findings
+	// truncated (N reached)" is reported at the first finding dropped, and
+	// later findings are neither reported nor summarized.
+	MaxFindings int
+
+	// ReportAt chooses where diagnostics for go statements are reported:
+	// "go", the default, reports at the go keyword; "body" reports at the
+	// opening brace of the function literal the goroutine runs, falling back
+	// to the go keyword when it runs anything else.
+	ReportAt string
+
+	// CacheDir, when set, caches on disk whether functions of other packages
+	// whose source is parsed from disk recover, keyed by a hash of the
+	// declaring file's contents and of the settings. Repeated runs over
+	// unchanged dependencies, as by an editor integration on each save, then
+	// skip parsing them. The directory is created if needed and may be
+	// shared by concurrent runs.
+	CacheDir string
+
+	// Debug logs how each goroutine is classified: the kind of function it
+	// runs, how calls to other packages are resolved and the RecoverFunctions
+	// keys consulted.
+	Debug bool
+
+	// DebugOutput receives the Debug log. It defaults to os.Stderr.
+	DebugOutput io.Writer
+
+	// Summary, when set, accumulates every reported goroutine across passes
+	Summary *SummaryCollector
+
+	// Coverage, when set, accumulates the share of recovered goroutines per package
+	Coverage *CoverageCollector
+}
+
+// Validate checks that the settings can be used for analysis: the function
+// regexps compile, the spawn functions, trusted spawners and safe functions
+// parse, neither Workers nor MaxFindings is negative and ReportAt is a known
+// position. The error names the first invalid setting.
+func (s *RecovercheckSettings) Validate() error {
+	if _, err := newFuncFilter(s); err != nil {
+		return err
+	}
+	if _, err := parseSpawnFuncs(s.SpawnFuncs); err != nil {
+		return fmt.Errorf("invalid spawn-func: %w", err)
+	}
+	if _, err := parseSpawnFuncs(s.TrustedSpawners); err != nil {
+		return fmt.Errorf("invalid trusted-spawner: %w", err)
+	}
+	if _, err := parseSafeFuncs(s.SafeFuncs); err != nil {
+		return fmt.Errorf("invalid safe-func: %w", err)
+	}
+	if s.Workers < 0 {
+		return fmt.Errorf("invalid workers %d: must not be negative", s.Workers)
+	}
+	if s.MaxFindings < 0 {
+		return fmt.Errorf("invalid max-findings %d: must not be negative", s.MaxFindings)
+	}
+	switch s.ReportAt {
+	case "", reportAtGo, reportAtBody:
+	default:
+		return fmt.Errorf("invalid report-at %q: want %s or %s", s.ReportAt, reportAtGo, reportAtBody)
+	}
+	switch s.PackageKind {
+	case "", packageKindAuto, packageKindLibrary, packageKindMain:
+	default:
+		return fmt.Errorf("invalid package-kind %q: want %s, %s or %s", s.PackageKind, packageKindAuto, packageKindLibrary, packageKindMain)
+	}
+	return nil
+}
+
+// forPackage returns the settings to analyze pkg with, according to
+// PackageKind: auto is resolved to the kind pkg is, and a library's
+// settings have the options trusting code the analyzer can't see turned off
+func (s *RecovercheckSettings) forPackage(pkg *types.Package) *RecovercheckSettings {
+	kind := s.PackageKind
+	if kind == packageKindAuto {
+		kind = packageKindLibrary
+		if pkg != nil && pkg.Name() == "main" {
+			kind = packageKindMain
+		}
+	}
+	if kind == s.PackageKind && kind != packageKindLibrary {
+		return s
+	}
+
+	adjusted := *s
+	adjusted.PackageKind = kind
+	if kind == packageKindLibrary {
+		adjusted.AssumeExternalSafe = false
+		adjusted.AssumeInterfaceMethodsSafe = false
+		adjusted.SkipSelectLoops = false
+		adjusted.SkipTrivialBodies = false
+	}
+	return &adjusted
 }
 
 // Analyzer holds the state and methods for analyzing recover patterns
 type Analyzer struct {
 	Pass             *analysis.Pass
-	RecoverFunctions map[string]bool // funcName -> hasRecover
+	RecoverFunctions map[string]bool // funcName or (*Type).method -> hasRecover
 	Settings         *RecovercheckSettings
+	GoContexts       map[*ast.GoStmt]*GoContext
+	SpawnContexts    map[*ast.CallExpr]*GoContext
+
+	flaggedGoroutines map[*ast.GoStmt]bool
+	trustedSpawners   []SpawnFunc
+	safeFuncs         []SpawnFunc            // functions trusted to recover, see SafeFuncs
+	funcFilter        *funcFilter            // selects go statements by enclosing function
+	parsedFiles       map[string]*parsedFile // filename -> syntax of files outside the pass
+	cachedFiles       map[string]*cachedFile // filename -> results cached on disk, see CacheDir
+	facts             *recoverFacts          // facts exported for dependencies, if available
+	tracingFactories  map[*ast.FuncDecl]bool // factories whose returned values are being classified
+	mu                *sync.Mutex            // guards shared state while classifying concurrently
+	unsafe            []UnsafeGoroutine      // findings reported so far, for the pass's result
+	generatedFiles    map[*token.File]bool   // files of the pass with a generated-code header
+	reported          int                    // diagnostics reported so far, for MaxFindings
+	truncated         bool                   // the MaxFindings note has been reported
+	totalGoroutines   int                    // go statements analyzed, for the pass's result
+	safeGoroutines    int                    // of which recover or are exempt
+}
+
+// parsedFile is a file of another package parsed from disk. A nil file
+// records that parsing failed.
+type parsedFile struct {
+	once sync.Once // parses the file, outside the shared lock
+	fset *token.FileSet
+	file *ast.File
 }
 
 // NodeCollector collects AST nodes for analysis
 type NodeCollector struct {
 	FunctionDecls []*ast.FuncDecl
 	GoStatements  []*ast.GoStmt
-	ErrgroupCalls []*ast.CallExpr // errgroup.Group.Go() calls
+	GoContexts    map[*ast.GoStmt]*GoContext
+	SpawnCalls    []*ast.CallExpr // calls that might be to a registered spawn function
+	SpawnContexts map[*ast.CallExpr]*GoContext
+}
+
+// GoContext describes where a go statement or spawn call appears in the
+// source
+type GoContext struct {
+	FuncDecl *ast.FuncDecl // enclosing function declaration, nil at package level
+	Func     ast.Node      // innermost enclosing *ast.FuncDecl or *ast.FuncLit
+	Parent   *ast.GoStmt   // enclosing go statement, nil unless nested
+	Var      *ast.Ident    // package-level variable whose initializer holds the go statement
+	Loop     ast.Stmt      // innermost *ast.ForStmt or *ast.RangeStmt whose body holds the go statement
+	InMain   bool          // the go statement is in func main of package main
 }
 
 // CollectNodes extracts relevant nodes from the AST for analysis
-func CollectNodes(insp *inspector.Inspector) *NodeCollector {
-	collector := &NodeCollector{}
+func CollectNodes(insp *inspector.Inspector, spawnFuncs ...SpawnFunc) *NodeCollector {
+	collector := &NodeCollector{
+		GoContexts:    make(map[*ast.GoStmt]*GoContext),
+		SpawnContexts: make(map[*ast.CallExpr]*GoContext),
+	}
 
 	// Collect function declarations
 	insp.Nodes([]ast.Node{(*ast.FuncDecl)(nil)}, func(node ast.Node, push bool) bool {
@@ -42,20 +373,24 @@ func CollectNodes(insp *inspector.Inspector) *NodeCollector {
 		return false
 	})
 
-	// Collect go statements
-	insp.Nodes([]ast.Node{(*ast.GoStmt)(nil)}, func(node ast.Node, push bool) bool {
+	// Collect go statements along with their enclosing context
+	insp.WithStack([]ast.Node{(*ast.GoStmt)(nil)}, func(node ast.Node, push bool, stack []ast.Node) bool {
 		if push {
-			collector.GoStatements = append(collector.GoStatements, node.(*ast.GoStmt))
+			goStmt := node.(*ast.GoStmt)
+			collector.GoStatements = append(collector.GoStatements, goStmt)
+			collector.GoContexts[goStmt] = goContextFor(stack)
 		}
-		return false
+		return true // nested go statements are analyzed too
 	})
 
-	// Collect errgroup calls (method calls that might be errgroup.Group.Go())
-	insp.Nodes([]ast.Node{(*ast.CallExpr)(nil)}, func(node ast.Node, push bool) bool {
+	// Collect calls that might be to a spawn function, errgroup.Group.Go
+	// among them
+	insp.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(node ast.Node, push bool, stack []ast.Node) bool {
 		if push {
 			call := node.(*ast.CallExpr)
-			if isErrgroupGoCall(call) {
-				collector.ErrgroupCalls = append(collector.ErrgroupCalls, call)
+			if isSpawnCallCandidate(call, spawnFuncs) {
+				collector.SpawnCalls = append(collector.SpawnCalls, call)
+				collector.SpawnContexts[call] = goContextFor(stack)
 			}
 		}
 		return false
@@ -64,297 +399,2804 @@ func CollectNodes(insp *inspector.Inspector) *NodeCollector {
 	return collector
 }
 
-// isErrgroupGoCall checks if a call expression is an errgroup.Group.Go() call
-func isErrgroupGoCall(call *ast.CallExpr) bool {
-	// Look for method calls like g.Go() where g might be an errgroup.Group
-	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
-		// Check if the method name is "Go"
-		if sel.Sel.Name == "Go" {
-			// We'll do more sophisticated type checking later
-			// For now, assume any .Go() call might be errgroup
-			return true
+// goContextFor builds the context of a go statement or spawn call from its
+// ancestor stack
+func goContextFor(stack []ast.Node) *GoContext {
+	ctx := &GoContext{}
+	// The last element of the stack is the node itself
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch node := stack[i].(type) {
+		case *ast.FuncDecl:
+			ctx.FuncDecl = node
+			if ctx.Func == nil {
+				ctx.Func = node
+			}
+			ctx.Var = nil // local variables are not reported
+			if file, ok := stack[0].(*ast.File); ok {
+				ctx.InMain = file.Name.Name == "main" && node.Recv == nil && node.Name.Name == "main"
+			}
+			return ctx
+		case *ast.FuncLit:
+			if ctx.Func == nil {
+				ctx.Func = node
+			}
+		case *ast.GoStmt:
+			if ctx.Parent == nil {
+				ctx.Parent = node
+			}
+		case *ast.ValueSpec:
+			// Walking outwards, the last spec seen is the outermost one
+			ctx.Var = valueSpecName(node, stack[i+1])
+		case *ast.ForStmt:
+			// Statements in the init, condition and post run at most once
+			// per iteration too, but only the body starts goroutines
+			if ctx.Loop == nil && stack[i+1] == node.Body {
+				ctx.Loop = node
+			}
+		case *ast.RangeStmt:
+			if ctx.Loop == nil && stack[i+1] == node.Body {
+				ctx.Loop = node
+			}
 		}
 	}
-	return false
+	return ctx
+}
+
+// valueSpecName returns the name a value spec binds to the given value
+func valueSpecName(spec *ast.ValueSpec, value ast.Node) *ast.Ident {
+	for i, v := range spec.Values {
+		if v == value && i < len(spec.Names) {
+			return spec.Names[i]
+		}
+	}
+	if len(spec.Names) == 1 {
+		return spec.Names[0]
+	}
+	return nil
 }
 
 // New returns new recovercheck analyzer.
 func New(settings *RecovercheckSettings) *analysis.Analyzer {
+	if settings == nil {
+		settings = &RecovercheckSettings{}
+	}
+
+	loader := newConfigLoader()
+	factsAnalyzer := newFactsAnalyzer(settings, loader)
 	analyzer := &analysis.Analyzer{
 		Name:     "recovercheck",
 		Doc:      "Checks that goroutines have panic recovery logic",
-		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Requires: []*analysis.Analyzer{inspect.Analyzer, factsAnalyzer},
+		// Dependent analyzers receive a *RecoverResult
+		ResultType: reflect.TypeOf((*RecoverResult)(nil)),
+	}
+
+	analyzer.Flags.BoolVar(&settings.FlagDetachedInMain, "flag-detached-in-main", settings.FlagDetachedInMain,
+		"report unsynchronized goroutines without recovery started from main()")
+	analyzer.Flags.BoolVar(&settings.AssumeInterfaceMethodsSafe, "assume-interface-methods-safe", settings.AssumeInterfaceMethodsSafe,
+		"treat interface method calls as providing panic recovery")
+	analyzer.Flags.BoolVar(&settings.AssumeExternalSafe, "assume-external-safe", settings.AssumeExternalSafe,
+		"treat functions of other packages whose source can't be analyzed as providing panic recovery")
+	analyzer.Flags.BoolVar(&settings.HTTPHandlerSeverity, "http-handler-severity", settings.HTTPHandlerSeverity,
+		"report unrecovered goroutines started inside HTTP handlers with a higher-risk message")
+	analyzer.Flags.BoolVar(&settings.FlagGuardedRecover, "flag-guarded-recover", settings.FlagGuardedRecover,
+		"report deferred recovery that can return before calling recover()")
+	analyzer.Flags.BoolVar(&settings.DetectRepanic, "detect-repanic", settings.DetectRepanic,
+		"report deferred recovery that unconditionally re-panics the recovered value")
+	analyzer.Flags.BoolVar(&settings.WarnSwallowedRecover, "warn-swallowed-recover", settings.WarnSwallowedRecover,
+		"report a note for recovered goroutines that assign the recovered value to _ or to a variable never read")
+	analyzer.Flags.BoolVar(&settings.ErrgroupStrict, "errgroup-strict", settings.ErrgroupStrict,
+		"report errgroup callbacks that recover a panic without returning it as an error")
+	analyzer.Flags.BoolVar(&settings.RequireUnconditionalRecover, "require-unconditional-recover", settings.RequireUnconditionalRecover,
+		"only accept recovery deferred at the top level of the goroutine body, not inside an if or loop")
+	analyzer.Flags.BoolVar(&settings.RequireHandledRecover, "require-handled-recover", settings.RequireHandledRecover,
+		"only accept deferred recovery that uses the recovered value, not a bare recover()")
+	analyzer.Flags.BoolVar(&settings.FlagMustCalls, "flag-must-calls", settings.FlagMustCalls,
+		"point unrecovered goroutines at their first Must-style call as the likely panic site")
+	analyzer.Flags.BoolVar(&settings.DeepAnalysis, "deep-analysis", settings.DeepAnalysis,
+		"accept goroutines whose every call path, up to 5 calls deep, reaches a recovering function")
+	analyzer.Flags.BoolVar(&settings.StrictMain, "strict-main", settings.StrictMain,
+		"report unrecovered goroutines started in main() at error severity, even with -severity warning")
+	analyzer.Flags.StringVar(&settings.PackageKind, "package-kind", settings.PackageKind,
+		"analyze packages as a `kind` of package: library (stricter), main (go statements directly in main allowed) or auto (by package name)")
+	analyzer.Flags.BoolVar(&settings.NoteTestGoroutines, "note-test-goroutines", settings.NoteTestGoroutines,
+		"report unrecovered goroutines started in tests as notes, which don't fail the command")
+	analyzer.Flags.BoolVar(&settings.SkipGenerated, "skip-generated", settings.SkipGenerated,
+		"don't report goroutines in generated files (// Code generated ... DO NOT EDIT.)")
+	analyzer.Flags.BoolVar(&settings.SkipSelectLoops, "skip-select-loops", settings.SkipSelectLoops,
+		"don't report goroutines running a for { select { ... } } loop with a case <-ctx.Done()")
+	analyzer.Flags.BoolVar(&settings.SkipTrivialBodies, "skip-trivial-bodies", settings.SkipTrivialBodies,
+		"experimental: don't report goroutines running a function literal with no operation that can panic")
+	analyzer.Flags.BoolVar(&settings.FlagGoroutinesInLoops, "flag-goroutines-in-loops", settings.FlagGoroutinesInLoops,
+		"warn when an unrecovered goroutine is started inside a for or range body")
+	analyzer.Flags.StringVar(&settings.IncludeFuncRegex, "include-func-regex", settings.IncludeFuncRegex,
+		"only analyze go statements and spawn calls in functions whose fully qualified name matches this `regexp`")
+	analyzer.Flags.StringVar(&settings.ExcludeFuncRegex, "exclude-func-regex", settings.ExcludeFuncRegex,
+		"skip go statements and spawn calls in functions whose fully qualified name matches this `regexp`")
+	analyzer.Flags.Func("spawn-func", "treat `pkg.Func or pkg.Type.Method[:N]` as starting a goroutine running its function argument, or argument N (repeatable)",
+		func(spec string) error {
+			if _, err := ParseSpawnFunc(spec); err != nil {
+				return err
+			}
+			settings.SpawnFuncs = append(settings.SpawnFuncs, spec)
+			return nil
+		})
+	analyzer.Flags.Func("trusted-spawner", "trust `pkg.Func or pkg.Type.Method` to recover for the function it runs, skipping its callbacks (repeatable)",
+		func(spec string) error {
+			if _, err := ParseSpawnFunc(spec); err != nil {
+				return err
+			}
+			settings.TrustedSpawners = append(settings.TrustedSpawners, spec)
+			return nil
+		})
+	analyzer.Flags.Func("safe-func", "trust `pkg.Func or pkg.Type.Method` to recover panics in its own body, when run as a goroutine or deferred (repeatable)",
+		func(spec string) error {
+			if _, err := parseSafeFuncs([]string{spec}); err != nil {
+				return err
+			}
+			settings.SafeFuncs = append(settings.SafeFuncs, spec)
+			return nil
+		})
+	analyzer.Flags.IntVar(&settings.MaxFindings, "max-findings", settings.MaxFindings,
+		"stop reporting after `N` diagnostics per package, with a note that the rest were truncated (0 is unlimited)")
+	analyzer.Flags.StringVar(&settings.ReportAt, "report-at", settings.ReportAt,
+		"report each goroutine at `position` go, its go keyword, or body, its function literal's opening brace")
+	analyzer.Flags.StringVar(&settings.CacheDir, "cache-dir", settings.CacheDir,
+		"cache whether functions of other packages recover in `dir`, to skip parsing unchanged files on later runs")
+	analyzer.Flags.BoolVar(&settings.Debug, "debug", settings.Debug,
+		"log to stderr how each goroutine is classified")
+	analyzer.Flags.DurationVar(&settings.Since, "since", settings.Since,
+		"only report goroutines on lines changed within this `duration`, according to git blame")
+	loader.trackFlags(&analyzer.Flags)
+
+	// Settings given to New are checked now, so that a caller's mistake
+	// fails the first pass instead of surfacing deep in the analysis.
+	// Flags and configuration files are checked by each pass.
+	if err := settings.Validate(); err != nil {
+		analyzer.Run = func(*analysis.Pass) (any, error) {
+			return nil, err
+		}
+		return analyzer
 	}
 
 	analyzer.Run = func(pass *analysis.Pass) (any, error) {
-		return run(pass, settings)
+		config, err := loader.settingsFor(pass, settings)
+		if err != nil {
+			return nil, err
+		}
+		return run(pass, config, pass.ResultOf[factsAnalyzer].(*recoverFacts))
 	}
 
 	return analyzer
 }
 
-func run(pass *analysis.Pass, config *RecovercheckSettings) (any, error) {
+func run(pass *analysis.Pass, config *RecovercheckSettings, facts *recoverFacts) (any, error) {
 	analyzer := &Analyzer{
 		Pass:             pass,
 		RecoverFunctions: make(map[string]bool),
 		Settings:         config,
+		facts:            facts,
+	}
+
+	spawnFuncs := slices.Clone(knownSpawnFuncs)
+	if config != nil {
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+		config = config.forPackage(pass.Pkg)
+		analyzer.Settings = config
+		configured, err := parseSpawnFuncs(config.SpawnFuncs)
+		if err != nil {
+			return nil, err
+		}
+		spawnFuncs = append(spawnFuncs, configured...)
+		if analyzer.trustedSpawners, err = parseSpawnFuncs(config.TrustedSpawners); err != nil {
+			return nil, err
+		}
+		if analyzer.safeFuncs, err = parseSafeFuncs(config.SafeFuncs); err != nil {
+			return nil, err
+		}
+		if analyzer.funcFilter, err = newFuncFilter(config); err != nil {
+			return nil, err
+		}
 	}
 
 	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
 	// Collect all relevant nodes
-	nodes := CollectNodes(insp)
+	nodes := CollectNodes(insp, spawnFuncs...)
+	analyzer.GoContexts = nodes.GoContexts
+	analyzer.SpawnContexts = nodes.SpawnContexts
 
 	analyzer.AnalyzeFunctions(nodes.FunctionDecls)
 	analyzer.AnalyzeGoroutines(nodes.GoStatements)
-	analyzer.AnalyzeErrgroupCalls(nodes.ErrgroupCalls)
+	// errgroup.Group.Go is the first entry of the registry, so its calls
+	// are analyzed with the other spawn calls
+	analyzer.AnalyzeSpawnCalls(nodes.SpawnCalls, spawnFuncs)
 
-	return nil, nil
-}
+	if config != nil && config.Summary != nil {
+		if err := config.Summary.Flush(); err != nil {
+			return nil, err
+		}
+	}
 
-// AnalyzeFunctions processes all function declarations
-func (r *Analyzer) AnalyzeFunctions(functions []*ast.FuncDecl) {
-	for _, funcDecl := range functions {
-		r.analyzeFunction(funcDecl)
+	if config != nil && config.Coverage != nil {
+		config.Coverage.AddPackage(coveragePackage(pass.Pkg))
+		if err := config.Coverage.Flush(); err != nil {
+			return nil, err
+		}
 	}
+
+	return analyzer.Result(), nil
 }
 
-// AnalyzeGoroutines processes all go statements
-func (r *Analyzer) AnalyzeGoroutines(goStmts []*ast.GoStmt) {
-	for _, goStmt := range goStmts {
-		r.analyzeGoroutine(goStmt)
+// recordCoverage counts an analyzed goroutine started at pos when coverage is
+// being collected
+func (r *Analyzer) recordCoverage(pos token.Pos, recovered bool) {
+	if r.Settings != nil && r.Settings.Coverage != nil && r.Pass.Pkg != nil {
+		r.Settings.Coverage.Record(coveragePackage(r.Pass.Pkg), r.Pass.Fset.Position(pos), recovered)
 	}
 }
 
-// AnalyzeErrgroupCalls processes all errgroup.Group.Go() calls
-func (r *Analyzer) AnalyzeErrgroupCalls(calls []*ast.CallExpr) {
-	for _, call := range calls {
-		r.analyzeErrgroupCall(call)
-	}
+// report emits a diagnostic and records it in the summary when one is configured
+func (r *Analyzer) report(pos token.Pos, kind, message string, related ...analysis.RelatedInformation) {
+	r.reportDiagnostic(analysis.Diagnostic{Pos: pos, Category: kind, Message: message, Related: related})
 }
 
-// analyzeFunction processes a single function declaration
-func (r *Analyzer) analyzeFunction(funcDecl *ast.FuncDecl) {
-	if funcDecl.Name == nil || funcDecl.Body == nil {
+// reportDiagnostic emits a diagnostic whose Category is its kind of
+// goroutine, and records it in the summary when one is configured. Past
+// MaxFindings diagnostics, only the truncation note is emitted.
+func (r *Analyzer) reportDiagnostic(diagnostic analysis.Diagnostic) {
+	pos, kind, message := diagnostic.Pos, diagnostic.Category, diagnostic.Message
+	position := r.Pass.Fset.Position(pos)
+	if r.isOlderThanSince(position) {
 		return
 	}
-
-	funcName := funcDecl.Name.Name
-	hasRecover := r.containsRecover(funcDecl.Body)
-	r.RecoverFunctions[funcName] = hasRecover
-}
-
-// analyzeGoroutine processes a single go statement
-func (r *Analyzer) analyzeGoroutine(goStmt *ast.GoStmt) {
-	if goStmt.Call == nil {
-		r.Pass.Reportf(goStmt.Pos(), "go statement without call expression")
+	if r.Settings != nil && r.Settings.MaxFindings > 0 && r.reported >= r.Settings.MaxFindings {
+		if !r.truncated {
+			r.truncated = true
+			r.Pass.Report(analysis.Diagnostic{
+				Pos:      pos,
+				Category: KindTruncated,
+				Message:  fmt.Sprintf("additional findings truncated (%d reached)", r.Settings.MaxFindings),
+			})
+		}
 		return
 	}
+	r.reported++
 
-	if !r.hasRecoveryLogic(goStmt.Call) {
-		r.Pass.Reportf(goStmt.Pos(), "goroutine created without panic recovery")
+	r.Pass.Report(diagnostic)
+	r.unsafe = append(r.unsafe, UnsafeGoroutine{Pos: pos, Kind: kind, Message: message})
+
+	if r.Settings != nil && r.Settings.Summary != nil {
+		r.Settings.Summary.Add(Finding{
+			File:    position.Filename,
+			Line:    position.Line,
+			Column:  position.Column,
+			Kind:    kind,
+			Message: message,
+		})
 	}
 }
 
-// analyzeErrgroupCall processes a single errgroup.Group.Go() call
-func (r *Analyzer) analyzeErrgroupCall(call *ast.CallExpr) {
-	// Errgroup.Go() calls take a function as their first argument
-	if len(call.Args) == 0 {
+// reportNote emits a note about something other than an unsafe goroutine,
+// such as a recovered goroutine swallowing the recovered value. Notes are
+// left out of the result and the summary and don't count toward MaxFindings.
+func (r *Analyzer) reportNote(pos token.Pos, kind, message string) {
+	if r.isOlderThanSince(r.Pass.Fset.Position(pos)) {
 		return
 	}
+	r.Pass.Report(analysis.Diagnostic{Pos: pos, Category: kind, Message: message})
+}
+
+// isSkippedFile checks if pos is in a _test.go file that SkipTestFiles
+// excludes or a generated file that SkipGenerated excludes
+func (r *Analyzer) isSkippedFile(pos token.Pos) bool {
+	if r.Settings == nil {
+		return false
+	}
+	if r.Settings.SkipTestFiles && strings.HasSuffix(r.Pass.Fset.Position(pos).Filename, "_test.go") {
+		return true
+	}
+	return r.Settings.SkipGenerated && r.isGeneratedFile(pos)
+}
 
-	// The first argument should be a function literal that will be executed in a goroutine
-	if funcLit, ok := call.Args[0].(*ast.FuncLit); ok {
-		if !r.containsRecover(funcLit.Body) {
-			r.Pass.Reportf(call.Pos(), "errgroup goroutine created without panic recovery")
-		}
-	} else {
-		// If it's not a function literal, it might be a function reference
-		// We need to check if that function has recovery logic
-		if !r.hasRecoveryLogic(&ast.CallExpr{Fun: call.Args[0]}) {
-			r.Pass.Reportf(call.Pos(), "errgroup goroutine created without panic recovery")
+// isGeneratedFile checks if pos is in a file of the pass with a
+// generated-code header. Each file is checked once per pass.
+func (r *Analyzer) isGeneratedFile(pos token.Pos) bool {
+	if r.generatedFiles == nil {
+		r.generatedFiles = make(map[*token.File]bool)
+		for _, file := range r.Pass.Files {
+			r.generatedFiles[r.Pass.Fset.File(file.FileStart)] = ast.IsGenerated(file)
 		}
 	}
+	return r.generatedFiles[r.Pass.Fset.File(pos)]
 }
 
-// hasRecoveryLogic determines if a function call includes panic recovery
-func (r *Analyzer) hasRecoveryLogic(call *ast.CallExpr) bool {
-	switch fun := call.Fun.(type) {
-	case *ast.FuncLit:
-		return r.containsRecover(fun.Body)
-	case *ast.Ident:
-		return r.isRecoveryFunction(fun.Name)
-	case *ast.SelectorExpr:
-		return r.isCrossPackageRecoveryFunction(fun)
+// isOlderThanSince checks if the line at position was last changed before the
+// Since window. Lines whose age can't be determined are never suppressed.
+func (r *Analyzer) isOlderThanSince(position token.Position) bool {
+	if r.Settings == nil || r.Settings.Since <= 0 {
+		return false
 	}
-	return false
+
+	var blame BlameSource = defaultBlame
+	if r.Settings.Blame != nil {
+		blame = r.Settings.Blame
+	}
+
+	changed, err := blame.LineTime(position.Filename, position.Line)
+	if err != nil {
+		return false
+	}
+	return time.Since(changed) > r.Settings.Since
 }
 
-// isRecoveryFunction checks if a named function contains recovery logic
-func (r *Analyzer) isRecoveryFunction(funcName string) bool {
-	if hasRecover, exists := r.RecoverFunctions[funcName]; exists {
-		return hasRecover
+// AnalyzeFunctions processes all function declarations. Of a function
+// declared in several build-tagged files, only the declarations in the build
+// are used.
+func (r *Analyzer) AnalyzeFunctions(functions []*ast.FuncDecl) {
+	for _, funcDecl := range r.buildDecls(functions) {
+		r.analyzeFunction(funcDecl)
 	}
-	// Unknown functions are assumed unsafe
-	return false
 }
 
-// isCrossPackageRecoveryFunction handles pkg.Function() calls
-func (r *Analyzer) isCrossPackageRecoveryFunction(sel *ast.SelectorExpr) bool {
-	funcName := sel.Sel.Name
+// AnalyzeGoroutines processes all go statements. Their recovery is
+// classified concurrently, then they are reported in position order.
+// Enclosing go statements start before the ones nested inside them, so by
+// the time a nested goroutine is reported we know whether its parent was
+// already flagged. The goroutines analyzed and those that are safe are
+// counted for Result.
+func (r *Analyzer) AnalyzeGoroutines(goStmts []*ast.GoStmt) {
+	r.flaggedGoroutines = make(map[*ast.GoStmt]bool)
 
-	// Check if we have explicit knowledge of this cross-package function
-	if pkgIdent, ok := sel.X.(*ast.Ident); ok {
-		key := pkgIdent.Name + "." + funcName
-		if hasRecover, exists := r.RecoverFunctions[key]; exists {
-			return hasRecover
+	var candidates []*ast.GoStmt
+	for _, goStmt := range goStmts {
+		if r.isSkippedFile(goStmt.Pos()) || r.isFilteredGoroutine(goStmt) {
+			r.debugf(goStmt.Pos(), "goroutine skipped by file or function filters")
+			continue
 		}
-
-		// Use type information to resolve the actual package (handles both regular and aliased imports)
-		var hasRecovery bool
-		if r.Pass.TypesInfo != nil {
-			if obj, ok := r.Pass.TypesInfo.Uses[pkgIdent]; ok {
-				if pkgName, ok := obj.(*types.PkgName); ok {
-					hasRecovery = r.analyzeCrossPackageFunction(pkgName.Imported(), funcName)
-				}
-			}
+		if !r.isExemptGoroutine(goStmt) {
+			candidates = append(candidates, goStmt)
+		} else if !hasMalformedCall(goStmt.Call) {
+			r.totalGoroutines++
+			r.safeGoroutines++
 		}
-
-		r.RecoverFunctions[key] = hasRecovery
-		return hasRecovery
 	}
 
-	return false
-}
-
-// analyzeCrossPackageFunction analyzes a function from an imported package
-func (r *Analyzer) analyzeCrossPackageFunction(pkg *types.Package, funcName string) bool {
-	// Look for the function in the package scope
-	if obj := pkg.Scope().Lookup(funcName); obj != nil {
-		// Try to get the function declaration from the object
-		if funcObj, ok := obj.(*types.Func); ok {
-			// Get the position of the function to find its AST
-			pos := funcObj.Pos()
-			if pos.IsValid() {
-				// Find the function declaration in the imported package's files
-				return r.analyzeFunctionFromPosition(funcName, pos)
-			}
+	slices.SortStableFunc(candidates, func(a, b *ast.GoStmt) int {
+		return cmp.Compare(a.Pos(), b.Pos())
+	})
+	recovered := r.classifyConcurrently(len(candidates), func(worker *Analyzer, i int) bool {
+		return worker.hasGoroutineRecovery(candidates[i])
+	})
+	for i, goStmt := range candidates {
+		r.totalGoroutines++
+		if recovered[i] {
+			r.safeGoroutines++
 		}
+		r.analyzeGoroutine(goStmt, recovered[i])
 	}
-	return false
 }
 
-// analyzeFunctionFromPosition finds and analyzes a function from an imported package
-func (r *Analyzer) analyzeFunctionFromPosition(funcName string, pos token.Pos) bool {
-	// Get the file set from the analysis pass
-	fset := r.Pass.Fset
+// analyzeFunction processes a single function declaration. A key declared
+// again with a conflicting classification, as by two init functions or by
+// build-tagged files that are all passed in, is logged and kept as not
+// recovering, since the analyzer can't tell which declaration a call runs.
+func (r *Analyzer) analyzeFunction(funcDecl *ast.FuncDecl) {
+	if funcDecl.Name == nil || funcDecl.Body == nil {
+		return
+	}
 
-	// Get the position information
-	position := fset.Position(pos)
-	if !position.IsValid() {
-		return false
+	key := funcDeclKey(funcDecl)
+	hasRecover := r.containsRecover(funcDecl.Body)
+	if previous, exists := r.RecoverFunctions[key]; exists && previous != hasRecover {
+		r.debugf(funcDecl.Pos(), "RecoverFunctions[%q] declared again with recovers=%v, conflicting with %v: unsafe", key, hasRecover, previous)
+		hasRecover = false
 	}
+	r.RecoverFunctions[key] = hasRecover
+}
 
-	// Parse the file containing the function
-	file, err := parser.ParseFile(fset, position.Filename, nil, parser.ParseComments)
-	if err != nil {
-		// If we can't parse the file, assume it's unsafe
-		return false
+// funcDeclKey returns the RecoverFunctions key of a declaration: the name of
+// a function, or the receiver-qualified name of a method such as
+// (*svc).recover or svc.recover, so that same-named methods of different
+// types and functions don't overwrite each other
+func funcDeclKey(funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) != 1 {
+		return funcDecl.Name.Name
 	}
 
-	// Find the function declaration in the parsed file
-	var funcDecl *ast.FuncDecl
-	ast.Inspect(file, func(n ast.Node) bool {
-		if fn, ok := n.(*ast.FuncDecl); ok {
-			if fn.Name != nil && fn.Name.Name == funcName {
-				funcDecl = fn
-				return false // Stop searching
-			}
-		}
-		return true
-	})
+	recv := funcDecl.Recv.List[0].Type
+	if paren, ok := recv.(*ast.ParenExpr); ok {
+		recv = paren.X
+	}
+	pointer := false
+	if star, ok := recv.(*ast.StarExpr); ok {
+		pointer = true
+		recv = star.X
+	}
+	// Generic receivers such as List[T] are keyed by the type name
+	switch generic := recv.(type) {
+	case *ast.IndexExpr:
+		recv = generic.X
+	case *ast.IndexListExpr:
+		recv = generic.X
+	}
 
-	// If we found the function, analyze it for recovery logic
-	if funcDecl != nil && funcDecl.Body != nil {
-		return r.containsRecover(funcDecl.Body)
+	typeName, ok := recv.(*ast.Ident)
+	if !ok {
+		return funcDecl.Name.Name
+	}
+	if pointer {
+		return "(*" + typeName.Name + ")." + funcDecl.Name.Name
 	}
+	return typeName.Name + "." + funcDecl.Name.Name
+}
 
-	// If we can't find the function, assume it's unsafe
+// isExemptGoroutine checks if a go statement needs no classification: it
+// runs a trusted spawner, is directly in main with PackageKind main or, with
+// SkipSelectLoops, runs a context select loop, or with SkipTrivialBodies, a
+// function literal that can't panic. Exempt goroutines count as recovered in
+// the coverage, whatever the exemption, like in the result's SafeGoroutines.
+func (r *Analyzer) isExemptGoroutine(goStmt *ast.GoStmt) bool {
+	// The parser never produces a go statement without a call: go f is a
+	// syntax error and becomes an *ast.BadStmt. Only hand-built or pruned
+	// syntax trees can lack one, and there is nothing to check then.
+	if hasMalformedCall(goStmt.Call) {
+		r.debugf(goStmt.Pos(), "malformed go statement: skipped")
+		return true
+	}
+	if r.isTrustedSpawnerCall(goStmt.Call) {
+		r.debugf(goStmt.Pos(), "goroutine runs a trusted spawner: safe")
+		r.recordCoverage(goStmt.Pos(), true)
+		return true
+	}
+	if r.Settings != nil && r.Settings.PackageKind == packageKindMain && !r.Settings.StrictMain && !r.Settings.FlagDetachedInMain && r.isDirectlyInMain(goStmt) {
+		r.debugf(goStmt.Pos(), "goroutine started directly in main of a command: skipped")
+		r.recordCoverage(goStmt.Pos(), true)
+		return true
+	}
+	if r.Settings != nil && r.Settings.SkipSelectLoops && r.isContextSelectLoop(goStmt.Call.Fun) {
+		r.debugf(goStmt.Pos(), "goroutine runs a context select loop: skipped")
+		r.recordCoverage(goStmt.Pos(), true)
+		return true
+	}
+	if r.Settings != nil && r.Settings.SkipTrivialBodies && r.isTrivialBody(goStmt.Call.Fun) {
+		r.debugf(goStmt.Pos(), "goroutine body can't panic: skipped")
+		r.recordCoverage(goStmt.Pos(), true)
+		return true
+	}
 	return false
 }
 
-// containsRecover performs a deep search for recover() calls in any AST node
-func (r *Analyzer) containsRecover(node ast.Node) bool {
-	return r.findRecoverCall(node)
+// hasGoroutineRecovery classifies a go statement. It is safe to call from
+// concurrent workers of classifyConcurrently.
+func (r *Analyzer) hasGoroutineRecovery(goStmt *ast.GoStmt) bool {
+	recovered := r.hasRecoveryLogic(goStmt.Call)
+	if !recovered && r.Settings != nil && r.Settings.DeepAnalysis {
+		recovered = r.hasDeepRecovery(goStmt.Call.Fun)
+		r.debugf(goStmt.Pos(), "deep analysis of call paths: recovers=%v", recovered)
+	}
+	return recovered
 }
 
-// findRecoverCall recursively searches for recover() calls
-func (r *Analyzer) findRecoverCall(node ast.Node) bool {
-	found := false
-
-	ast.Inspect(node, func(n ast.Node) bool {
-		if found {
-			return false // Stop traversal once found
+// analyzeGoroutine reports a classified go statement unless it recovers
+func (r *Analyzer) analyzeGoroutine(goStmt *ast.GoStmt, recovered bool) {
+	r.recordCoverage(goStmt.Pos(), recovered)
+	if recovered {
+		r.debugf(goStmt.Pos(), "goroutine has recovery: safe")
+		if r.Settings != nil && r.Settings.WarnSwallowedRecover && r.hasSwallowedRecover(goStmt.Call) {
+			r.reportNote(r.goroutinePos(goStmt), KindSwallowed, "note: goroutine recovery swallows the recovered value")
 		}
+		return
+	}
+	r.debugf(goStmt.Pos(), "goroutine has no recovery: reported")
+	r.flaggedGoroutines[goStmt] = true
 
-		switch node := n.(type) {
+	kind, message := KindGoroutine, r.goroutineMessage(goStmt)
+	if r.isUnresolvedFuncField(goStmt.Call.Fun) {
+		message += " (could not resolve function value)"
+	}
+	if r.isUnresolvedFactoryCall(goStmt.Call.Fun) {
+		message += " (could not resolve function returned by call)"
+	}
+	if candidate := r.unrecoveredCandidate(goStmt.Call.Fun); candidate != "" {
+		message += fmt.Sprintf(" (may run %s, which has no recovery)", candidate)
+	}
+	if ctx := r.GoContexts[goStmt]; ctx != nil && ctx.Loop != nil && r.Settings != nil && r.Settings.FlagGoroutinesInLoops {
+		message += " (inside loop — panic will crash process on any iteration)"
+	}
+	if r.Settings != nil && r.Settings.StrictMain && r.isInMain(goStmt) {
+		kind = KindMain
+	}
+	if r.Settings != nil && r.Settings.NoteTestGoroutines && r.isInTestFunc(goStmt) {
+		kind, message = KindNote, "note: "+message+" (in test: a panic fails the test instead of crashing the process)"
+	}
+	r.reportDiagnostic(analysis.Diagnostic{
+		Pos:            r.goroutinePos(goStmt),
+		Category:       kind,
+		Message:        message,
+		Related:        r.goroutineRelated(goStmt),
+		SuggestedFixes: r.recoveryFixes(goStmt.Call.Fun),
+	})
+}
+
+// isMalformedCall checks if a call of a go or defer statement lacks a node
+// the parser always sets: the call itself, its function, the operand of a
+// parenthesized or selector expression, or the type or body of a function
+// literal. The parser represents syntax errors with Bad nodes instead, but
+// drivers may pass hand-built or partially pruned syntax trees, whose
+// malformed calls are skipped rather than analyzed.
+func isMalformedCall(call *ast.CallExpr) bool {
+	if call == nil || call.Fun == nil {
+		return true
+	}
+	switch fun := astutil.Unparen(call.Fun).(type) {
+	case nil:
+		return true
+	case *ast.SelectorExpr:
+		return fun.X == nil || fun.Sel == nil
+	case *ast.FuncLit:
+		return fun.Type == nil || fun.Body == nil
+	}
+	return false
+}
+
+// hasMalformedCall checks if call, or a call or go or defer statement
+// nested in it, such as in the body of a function literal, is malformed. The
+// check stops at the first malformed node, before visiting its missing
+// children.
+func hasMalformedCall(call *ast.CallExpr) bool {
+	if isMalformedCall(call) {
+		return true
+	}
+	malformed := false
+	ast.Inspect(call, func(n ast.Node) bool {
+		switch n := n.(type) {
 		case *ast.CallExpr:
-			if r.isRecoverCall(node) {
-				found = true
-				return false
-			}
+			malformed = isMalformedCall(n)
+		case *ast.GoStmt:
+			malformed = n.Call == nil
 		case *ast.DeferStmt:
-			if r.isDeferredRecovery(node) {
-				found = true
-				return false
-			}
-		case *ast.BlockStmt:
-			// search for CallExpr and DeferStmt within the block statements
-			for _, stmt := range node.List {
-				if r.findRecoverCall(stmt) {
-					found = true
-					return false
-				}
-			}
+			malformed = n.Call == nil
 		}
-		return true
+		return !malformed
 	})
-
-	return found
+	return malformed
 }
 
-// isRecoverCall checks if a call expression is a direct recover() call
-func (r *Analyzer) isRecoverCall(call *ast.CallExpr) bool {
-	if ident, ok := call.Fun.(*ast.Ident); ok {
-		return ident.Name == "recover"
+// goroutinePos returns the position a go statement is reported at: the go
+// keyword or, with ReportAt "body", the opening brace of the function
+// literal it runs
+func (r *Analyzer) goroutinePos(goStmt *ast.GoStmt) token.Pos {
+	if r.Settings != nil && r.Settings.ReportAt == reportAtBody {
+		if funcLit, ok := r.funcValue(goStmt.Call.Fun).(*ast.FuncLit); ok {
+			return funcLit.Body.Lbrace
+		}
 	}
-	return false
+	return goStmt.Pos()
 }
 
-// isDeferredRecovery checks if a defer statement contains recovery logic
-func (r *Analyzer) isDeferredRecovery(deferStmt *ast.DeferStmt) bool {
-	if deferStmt.Call == nil {
-		return false
+// goroutineMessage picks the diagnostic message for an unrecovered go statement
+func (r *Analyzer) goroutineMessage(goStmt *ast.GoStmt) string {
+	ctx := r.GoContexts[goStmt]
+	settings := r.Settings
+	if settings == nil {
+		settings = &RecovercheckSettings{}
 	}
 
-	// Check for direct defer recover()
-	if r.isRecoverCall(deferStmt.Call) {
-		return true
+	switch {
+	case ctx != nil && ctx.Parent != nil && r.flaggedGoroutines[ctx.Parent]:
+		return "nested goroutine created without panic recovery inside an unrecovered goroutine"
+	case settings.HTTPHandlerSeverity && r.isInHTTPHandler(goStmt):
+		return "goroutine created without panic recovery in HTTP handler; a panic bypasses the server's recovery and crashes the process"
+	case settings.FlagDetachedInMain && r.isInMain(goStmt) && !r.isSynchronized(goStmt.Call):
+		return "detached goroutine in main created without panic recovery or synchronization"
+	case settings.FlagGuardedRecover && r.hasGuardedRecover(goStmt.Call):
+		return "goroutine recovery can be skipped: deferred function may return before calling recover"
+	case settings.DetectRepanic && r.hasRepanic(goStmt.Call):
+		if via := r.rethrowHelper(goStmt.Call); via != "" {
+			return fmt.Sprintf("recovered panic is rethrown via %s", via)
+		}
+		return "recover immediately re-panics"
+	case settings.RequireHandledRecover && r.hasUnhandledRecover(goStmt.Call):
+		return "goroutine recovery discards the recovered value"
+	case settings.RequireUnconditionalRecover && r.hasConditionalRecover(goStmt.Call):
+		return "goroutine recovery is deferred conditionally and may never be registered"
+	case settings.StrictMain && r.isInMain(goStmt):
+		return "goroutine created without panic recovery in main; a panic crashes the program"
+	case r.isDynamicTarget(goStmt.Call.Fun):
+		return "goroutine target could not be analyzed; ensure recovery"
 	}
+	return "goroutine created without panic recovery"
+}
+
+// hasGuardedRecover checks if a goroutine literal defers a function literal
+// whose recover() call can be skipped by an earlier return
+func (r *Analyzer) hasGuardedRecover(call *ast.CallExpr) bool {
+	return r.defersFuncLit(call, r.recoverFinder().isGuardedRecover)
+}
+
+// hasRepanic checks if a goroutine literal defers a function literal that
+// re-panics every value it recovers
+func (r *Analyzer) hasRepanic(call *ast.CallExpr) bool {
+	return r.defersFuncLit(call, r.recoverFinder().isRepanic)
+}
 
-	// Check for defer func() { ... recover() ... }()
-	if funcLit, ok := deferStmt.Call.Fun.(*ast.FuncLit); ok {
-		return r.containsRecover(funcLit.Body)
+// rethrowHelper returns the name of the helper through which a goroutine
+// literal's deferred function literal re-panics every value it recovers, or
+// "" if it doesn't re-panic through a helper
+func (r *Analyzer) rethrowHelper(call *ast.CallExpr) string {
+	finder := r.recoverFinder()
+	via := ""
+	r.defersFuncLit(call, func(body *ast.BlockStmt) bool {
+		var ok bool
+		via, ok = finder.repanic(body)
+		return ok && via != ""
+	})
+	return via
+}
+
+// panickingHelper returns the name of the function of the current package
+// called by call if it unconditionally panics: a panic() call at the top
+// level of its body, before any statement that can return. Functions of
+// other packages aren't followed.
+func (r *Analyzer) panickingHelper(call *ast.CallExpr) string {
+	var funcDecl *ast.FuncDecl
+	if r.Pass.TypesInfo != nil {
+		fn := r.funcObjectOf(call.Fun)
+		if fn == nil || fn.Pkg() == nil || fn.Pkg() != r.Pass.Pkg {
+			return ""
+		}
+		funcDecl = r.funcDeclOf(call.Fun)
+	} else if ident, ok := call.Fun.(*ast.Ident); ok {
+		funcDecl = r.funcDeclByName(ident.Name)
+	}
+	if funcDecl == nil || funcDecl.Body == nil {
+		return ""
+	}
+
+	finder := r.recoverFinder()
+	for _, stmt := range funcDecl.Body.List {
+		if exprStmt, ok := stmt.(*ast.ExprStmt); ok {
+			if call, ok := exprStmt.X.(*ast.CallExpr); ok && finder.isPanicCall(call) {
+				return funcDeclKey(funcDecl)
+			}
+		}
+		if containsReturn(stmt) {
+			return ""
+		}
+	}
+	return ""
+}
+
+// funcDeclByName returns the function, not method, of the pass's files
+// with the given name, for lookups without type information
+func (r *Analyzer) funcDeclByName(name string) *ast.FuncDecl {
+	for _, file := range r.Pass.Files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Recv == nil && funcDecl.Name.Name == name {
+				return funcDecl
+			}
+		}
+	}
+	return nil
+}
+
+// hasSwallowedRecover checks if a goroutine literal defers a function literal
+// that recovers and discards the recovered value
+func (r *Analyzer) hasSwallowedRecover(call *ast.CallExpr) bool {
+	finder := r.recoverFinder()
+	return r.defersFuncLit(call, func(body *ast.BlockStmt) bool {
+		return finder.callsRecover(body) && finder.isSwallowedRecover(body)
+	})
+}
+
+// hasUnhandledRecover checks if a goroutine literal defers recover() itself
+// or a function literal that calls recover() without using its value
+func (r *Analyzer) hasUnhandledRecover(call *ast.CallExpr) bool {
+	funcLit, ok := r.funcValue(call.Fun).(*ast.FuncLit)
+	if !ok {
+		return false
+	}
+
+	finder := r.recoverFinder()
+	for _, stmt := range funcLit.Body.List {
+		if deferStmt, ok := stmt.(*ast.DeferStmt); ok && !isMalformedCall(deferStmt.Call) && finder.isRecoverCall(deferStmt.Call) {
+			return true
+		}
+	}
+	return r.defersFuncLit(call, func(body *ast.BlockStmt) bool {
+		return finder.callsRecover(body) && !finder.isHandledRecover(body)
+	})
+}
+
+// hasConditionalRecover checks if a goroutine literal defers recovery only
+// inside a conditional, loop or block
+func (r *Analyzer) hasConditionalRecover(call *ast.CallExpr) bool {
+	funcLit, ok := r.funcValue(call.Fun).(*ast.FuncLit)
+	if !ok {
+		return false
+	}
+
+	finder := r.recoverFinder()
+	for deferStmt := range conditionalDefers(funcLit.Body) {
+		if finder.isDeferredRecovery(deferStmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// defersFuncLit checks if a goroutine literal directly defers a function
+// literal whose body matches
+func (r *Analyzer) defersFuncLit(call *ast.CallExpr, match func(body *ast.BlockStmt) bool) bool {
+	funcLit, ok := r.funcValue(call.Fun).(*ast.FuncLit)
+	if !ok {
+		return false
+	}
+
+	for _, stmt := range funcLit.Body.List {
+		deferStmt, ok := stmt.(*ast.DeferStmt)
+		if !ok || isMalformedCall(deferStmt.Call) {
+			continue
+		}
+		if deferred, ok := astutil.Unparen(deferStmt.Call.Fun).(*ast.FuncLit); ok && match(deferred.Body) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutineRelated points at the function declaration or package-level
+// function value enclosing a go statement, for navigating large files and
+// for diagnostics that would otherwise point at an anonymous function
+func (r *Analyzer) goroutineRelated(goStmt *ast.GoStmt) []analysis.RelatedInformation {
+	var related []analysis.RelatedInformation
+	if ctx := r.GoContexts[goStmt]; ctx != nil && ctx.FuncDecl != nil {
+		related = append(related, analysis.RelatedInformation{
+			Pos:     ctx.FuncDecl.Pos(),
+			Message: "in function " + funcDeclKey(ctx.FuncDecl),
+		})
+	} else if ctx != nil && ctx.Var != nil {
+		related = append(related, analysis.RelatedInformation{
+			Pos:     ctx.Var.Pos(),
+			Message: "in function value " + ctx.Var.Name,
+		})
+	}
+	return append(related, r.mustCallRelated(goStmt.Call.Fun)...)
+}
+
+// mustCallRelated points at the first Must-style call in a flagged function
+// literal, when FlagMustCalls is set. By convention such functions panic
+// instead of returning an error, making them the likely source of a crash.
+func (r *Analyzer) mustCallRelated(fn ast.Expr) []analysis.RelatedInformation {
+	funcLit, ok := r.funcValue(fn).(*ast.FuncLit)
+	if r.Settings == nil || !r.Settings.FlagMustCalls || !ok {
+		return nil
+	}
+
+	var mustCall *ast.CallExpr
+	ast.Inspect(funcLit.Body, func(n ast.Node) bool {
+		if mustCall != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if isMustName(calleeName(n)) {
+				mustCall = n
+				return false
+			}
+		}
+		return true
+	})
+	if mustCall == nil {
+		return nil
+	}
+
+	return []analysis.RelatedInformation{{
+		Pos:     mustCall.Pos(),
+		Message: types.ExprString(mustCall.Fun) + " may panic",
+	}}
+}
+
+// isMustName checks if name follows the Must convention: Must or must,
+// alone or followed by an upper-case letter, as in MustCompile or mustParse
+func isMustName(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Must")
+	if !ok {
+		if rest, ok = strings.CutPrefix(name, "must"); !ok {
+			return false
+		}
+	}
+	return rest == "" || unicode.IsUpper([]rune(rest)[0])
+}
+
+// isInMain checks if a go statement is lexically inside package main's main function
+func (r *Analyzer) isInMain(goStmt *ast.GoStmt) bool {
+	ctx := r.GoContexts[goStmt]
+	return ctx != nil && ctx.InMain
+}
+
+// isDirectlyInMain checks if a go statement is in the body of package
+// main's main function itself, not in a function literal or goroutine there
+func (r *Analyzer) isDirectlyInMain(goStmt *ast.GoStmt) bool {
+	ctx := r.GoContexts[goStmt]
+	return ctx != nil && ctx.InMain && ctx.Parent == nil && ctx.Func == ast.Node(ctx.FuncDecl)
+}
+
+// isInHTTPHandler checks if the function enclosing a go statement has the
+// signature func(http.ResponseWriter, *http.Request)
+func (r *Analyzer) isInHTTPHandler(goStmt *ast.GoStmt) bool {
+	ctx := r.GoContexts[goStmt]
+	if ctx == nil || ctx.Func == nil || r.Pass.TypesInfo == nil {
+		return false
+	}
+
+	var sig *types.Signature
+	switch fn := ctx.Func.(type) {
+	case *ast.FuncDecl:
+		if obj := r.Pass.TypesInfo.Defs[fn.Name]; obj != nil {
+			sig, _ = obj.Type().(*types.Signature)
+		}
+	case *ast.FuncLit:
+		sig, _ = r.Pass.TypesInfo.TypeOf(fn).(*types.Signature)
+	}
+	if sig == nil || sig.Params().Len() != 2 || sig.Results().Len() != 0 {
+		return false
+	}
+
+	request, ok := sig.Params().At(1).Type().(*types.Pointer)
+	return ok &&
+		isNamedType(sig.Params().At(0).Type(), "net/http", "ResponseWriter") &&
+		isNamedType(request.Elem(), "net/http", "Request")
+}
+
+// isInTestFunc checks if a go statement is in a _test.go file, directly
+// inside a function taking a *testing.T, such as a test or a t.Run subtest
+func (r *Analyzer) isInTestFunc(goStmt *ast.GoStmt) bool {
+	ctx := r.GoContexts[goStmt]
+	if ctx == nil || ctx.Func == nil || r.Pass.TypesInfo == nil {
+		return false
+	}
+	if !strings.HasSuffix(r.Pass.Fset.Position(goStmt.Pos()).Filename, "_test.go") {
+		return false
+	}
+
+	var sig *types.Signature
+	switch fn := ctx.Func.(type) {
+	case *ast.FuncDecl:
+		if obj := r.Pass.TypesInfo.Defs[fn.Name]; obj != nil {
+			sig, _ = obj.Type().(*types.Signature)
+		}
+	case *ast.FuncLit:
+		sig, _ = r.Pass.TypesInfo.TypeOf(fn).(*types.Signature)
+	}
+	if sig == nil {
+		return false
+	}
+
+	for param := range sig.Params().Variables() {
+		if ptr, ok := param.Type().(*types.Pointer); ok && isNamedType(ptr.Elem(), "testing", "T") {
+			return true
+		}
+	}
+	return false
+}
+
+// isSynchronized applies a heuristic to decide whether a goroutine communicates
+// with its creator: it is passed a channel or *sync.WaitGroup, or its literal
+// body sends on, receives from or closes a channel, or calls Done.
+func (r *Analyzer) isSynchronized(call *ast.CallExpr) bool {
+	for _, arg := range call.Args {
+		if r.isSyncType(arg) {
+			return true
+		}
+	}
+
+	funcLit, ok := r.funcValue(call.Fun).(*ast.FuncLit)
+	if !ok {
+		return false
+	}
+
+	synchronized := false
+	ast.Inspect(funcLit.Body, func(n ast.Node) bool {
+		if synchronized {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.SendStmt:
+			synchronized = true
+		case *ast.UnaryExpr:
+			synchronized = n.Op == token.ARROW
+		case *ast.CallExpr:
+			switch fun := n.Fun.(type) {
+			case *ast.Ident:
+				synchronized = fun.Name == "close"
+			case *ast.SelectorExpr:
+				synchronized = fun.Sel.Name == "Done"
+			}
+		}
+		return !synchronized
+	})
+	return synchronized
+}
+
+// isSyncType checks if an expression is a channel or a *sync.WaitGroup
+func (r *Analyzer) isSyncType(expr ast.Expr) bool {
+	if r.Pass.TypesInfo == nil {
+		return false
+	}
+
+	t := r.Pass.TypesInfo.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+	if _, ok := t.Underlying().(*types.Chan); ok {
+		return true
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		return isNamedType(ptr.Elem(), "sync", "WaitGroup")
+	}
+	return false
+}
+
+// isNamedType checks if t is the named type pkgPath.name
+func isNamedType(t types.Type, pkgPath, name string) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
+
+// hasCallbackRecovery classifies a function handed to errgroup.Group.Go(),
+// which will be executed in a goroutine. It is safe to call from concurrent
+// workers of classifyConcurrently.
+func (r *Analyzer) hasCallbackRecovery(fn ast.Expr) bool {
+	recovered := r.isRecoveringCallback(fn)
+	if !recovered && r.Settings != nil && r.Settings.DeepAnalysis {
+		recovered = r.hasDeepRecovery(fn)
+	}
+	return recovered
+}
+
+// analyzeErrgroupCall reports a classified errgroup.Group.Go() call unless
+// its callback recovers and, with ErrgroupStrict, returns the recovered
+// panic as its error
+func (r *Analyzer) analyzeErrgroupCall(call *ast.CallExpr, recovered bool) {
+	r.recordCoverage(call.Pos(), recovered)
+	switch {
+	case !recovered:
+		r.report(call.Pos(), KindErrgroup, "errgroup goroutine created without panic recovery", r.mustCallRelated(call.Args[0])...)
+	case r.Settings != nil && r.Settings.ErrgroupStrict && !r.returnsPanicAsError(call.Args[0]):
+		r.report(call.Pos(), KindErrgroup, "errgroup callback recovers but does not return the panic as an error")
+	}
+}
+
+// isRecoveringCallback determines if a function passed to be run in a goroutine
+// includes panic recovery. Named functions and method values are resolved to
+// their declarations; other expressions are classified like go statement targets.
+func (r *Analyzer) isRecoveringCallback(fn ast.Expr) bool {
+	switch fn := r.funcValue(fn).(type) {
+	case *ast.FuncLit:
+		return r.containsRecover(fn.Body)
+	case *ast.Ident, *ast.SelectorExpr:
+		if recovers, ok := r.recoversByFact(r.funcObjectOf(fn)); ok {
+			return recovers
+		}
+		if funcDecl := r.funcDeclOf(fn); funcDecl != nil {
+			return funcDecl.Body != nil && r.containsRecover(funcDecl.Body)
+		}
+	}
+	return r.isRecoveringFuncValue(fn)
+}
+
+// hasRecoveryLogic determines if a function call includes panic recovery
+func (r *Analyzer) hasRecoveryLogic(call *ast.CallExpr) bool {
+	if isMalformedCall(call) {
+		return false
+	}
+	return r.isRecoveringFuncValue(call.Fun)
+}
+
+// isRecoveringFuncValue determines if a function-valued expression includes panic recovery
+func (r *Analyzer) isRecoveringFuncValue(fun ast.Expr) bool {
+	fun = r.funcValue(fun)
+	if r.isSafeFunc(fun) {
+		return true
+	}
+	if generic := r.instantiatedFunc(fun); generic != nil {
+		fun = generic
+	}
+
+	switch fun := fun.(type) {
+	case *ast.FuncLit:
+		recovers := r.containsRecover(fun.Body)
+		r.debugf(fun.Pos(), "function literal: recovers=%v", recovers)
+		return recovers
+	case *ast.Ident:
+		if value := r.resolveFuncVar(fun); value != nil {
+			r.debugf(fun.Pos(), "variable %s resolved to its assigned function", fun.Name)
+			return r.isRecoveringFuncValue(value)
+		}
+		if candidates := r.funcCandidates(fun); candidates != nil {
+			r.debugf(fun.Pos(), "range variable %s resolved to %d candidates", fun.Name, len(candidates))
+			return r.allRecovering(candidates)
+		}
+		// Functions of dot-imported packages are called without a selector
+		if fn := r.funcObjectOf(fun); fn != nil && fn.Pkg() != nil && fn.Pkg() != r.Pass.Pkg {
+			r.debugf(fun.Pos(), "function %s of dot-imported package %s", fn.Name(), fn.Pkg().Path())
+			return r.analyzeCrossPackageFunction(fn.Pkg(), fn.Name())
+		}
+		recovers := r.isRecoveryFunction(fun.Name)
+		r.debugf(fun.Pos(), "declared function %s: recovers=%v", fun.Name, recovers)
+		return recovers
+	case *ast.SelectorExpr:
+		if field := r.funcField(fun); field != nil {
+			if value := r.resolveFuncField(field); value != nil {
+				r.debugf(fun.Pos(), "field %s resolved to its assigned function", field.Name())
+				return r.isRecoveringFuncValue(value)
+			}
+			r.debugf(fun.Pos(), "field %s could not be resolved: unsafe", field.Name())
+			return false
+		}
+		fn := r.concreteMethod(fun)
+		if fn == nil {
+			fn = r.dynamicMethod(fun)
+		}
+		if fn != nil {
+			if recovers, ok := r.recoversByFact(fn); ok {
+				r.debugf(fun.Pos(), "method %s resolved by fact: recovers=%v", fn.FullName(), recovers)
+				return recovers
+			}
+			if funcDecl := r.findFuncDecl(fn.Name(), fn.Pos()); funcDecl != nil {
+				recovers := funcDecl.Body != nil && r.containsRecover(funcDecl.Body)
+				r.debugf(fun.Pos(), "method %s resolved to its declaration: recovers=%v", fn.FullName(), recovers)
+				return recovers
+			}
+		}
+		return r.isCrossPackageRecoveryFunction(fun)
+	case *ast.CallExpr:
+		// go factory()() runs the function factory returns
+		recovers := r.returnsRecoveringFunc(fun)
+		r.debugf(fun.Pos(), "function returned by call: recovers=%v", recovers)
+		return recovers
+	case *ast.IndexExpr:
+		if candidates := r.funcCandidates(fun); candidates != nil {
+			r.debugf(fun.Pos(), "collection element resolved to %d candidates", len(candidates))
+			return r.allRecovering(candidates)
+		}
+	}
+	r.debugf(fun.Pos(), "unsupported function expression %T: unsafe", fun)
+	return false
+}
+
+// allRecovering checks if every candidate function recovers
+func (r *Analyzer) allRecovering(candidates []ast.Expr) bool {
+	for _, candidate := range candidates {
+		if !r.isRecoveringFuncValue(candidate) {
+			return false
+		}
+	}
+	return true
+}
+
+// concreteMethod resolves a method call or method value on a concrete type,
+// such as obj.Method or (&server{}).Serve, to the method. It returns nil for
+// package-qualified functions and interface methods.
+func (r *Analyzer) concreteMethod(sel *ast.SelectorExpr) *types.Func {
+	if r.Pass.TypesInfo == nil {
+		return nil
+	}
+	selection, ok := r.Pass.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal || types.IsInterface(selection.Recv()) {
+		return nil
+	}
+	fn, _ := selection.Obj().(*types.Func)
+	return fn
+}
+
+// dynamicMethod resolves an interface method call on a local variable, as in
+// go h.Handle(), to the method of the variable's dynamic type when the
+// variable is assigned exactly once from a composite literal, such as
+// var h Handler = &jsonHandler{} or h := Handler(jsonHandler{}). It returns
+// nil when the dynamic type can't be determined, and without type
+// information.
+func (r *Analyzer) dynamicMethod(sel *ast.SelectorExpr) *types.Func {
+	if r.Pass == nil || r.Pass.TypesInfo == nil || !r.isInterfaceMethod(sel) {
+		return nil
+	}
+	ident, ok := astutil.Unparen(sel.X).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := r.Pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return nil
+	}
+
+	value := r.assignedOnce(v)
+	if value == nil {
+		return nil
+	}
+	value = astutil.Unparen(r.funcValue(value))
+	literal := value
+	if unary, ok := value.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		literal = astutil.Unparen(unary.X)
+	}
+	if _, ok := literal.(*ast.CompositeLit); !ok {
+		return nil
+	}
+
+	dynamic := r.Pass.TypesInfo.TypeOf(value)
+	if dynamic == nil || types.IsInterface(dynamic) {
+		return nil
+	}
+	obj, _, _ := types.LookupFieldOrMethod(dynamic, true, r.Pass.Pkg, sel.Sel.Name)
+	fn, _ := obj.(*types.Func)
+	if fn != nil {
+		r.debugf(sel.Pos(), "interface method %s called on %s", sel.Sel.Name, dynamic)
+	}
+	return fn
+}
+
+// resolveFuncVar traces a local function-typed variable back to the function
+// value it was assigned. Only variables assigned exactly once are resolved, and
+// only when the assigned value is a function literal or a reference to a
+// declared function; reassigned variables and variables initialized from other
+// variables are left unresolved and treated as unsafe. Without type
+// information variables are matched by name, see resolveFuncVarByName.
+func (r *Analyzer) resolveFuncVar(ident *ast.Ident) ast.Expr {
+	if r.Pass == nil {
+		return nil
+	}
+	if r.Pass.TypesInfo == nil {
+		return r.resolveFuncVarByName(ident)
+	}
+
+	v, ok := r.Pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return nil
+	}
+
+	switch value := r.assignedOnce(v).(type) {
+	case *ast.FuncLit, *ast.SelectorExpr:
+		return value
+	case *ast.Ident:
+		if _, ok := r.objectOf(value).(*types.Func); ok {
+			return value
+		}
+	case *ast.CallExpr:
+		// f := sync.OnceFunc(work), classified through funcValue
+		if r.isOnceWrapper(value.Fun) {
+			return value
+		}
+	}
+	return nil
+}
+
+// assignedOnce returns the value a local variable is assigned, or nil if it
+// is assigned more or less than once, including parameters, or its address
+// is taken. Package-level variables and fields are never resolved, since
+// they can be reassigned from anywhere.
+func (r *Analyzer) assignedOnce(v *types.Var) ast.Expr {
+	if !isLocalVar(v) {
+		return nil
+	}
+	value, assignments := r.assignmentsOf(v)
+	if assignments != 1 {
+		return nil
+	}
+	return value
+}
+
+// isLocalVar checks if v is a variable local to a function, including
+// parameters and results, rather than a field or package-level variable
+func isLocalVar(v *types.Var) bool {
+	return !v.IsField() && v.Pkg() != nil && v.Parent() != v.Pkg().Scope()
+}
+
+// assignmentsOf counts the assignments to a local variable in the file
+// declaring it, including its declaration with a value and taking its
+// address, which allows assignments that can't be followed. value is the
+// last value assigned, or nil if it isn't known.
+func (r *Analyzer) assignmentsOf(v *types.Var) (value ast.Expr, assignments int) {
+	record := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for i, expr := range lhs {
+			id, ok := expr.(*ast.Ident)
+			if !ok || r.objectOf(id) != v {
+				continue
+			}
+			assignments++
+			if len(lhs) == len(rhs) {
+				value = rhs[i]
+			} else {
+				value = nil
+			}
+		}
+	}
+
+	for _, file := range r.Pass.Files {
+		if v.Pos() < file.Pos() || v.Pos() > file.End() {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				record(n.Lhs, n.Rhs)
+			case *ast.ValueSpec:
+				lhs := make([]ast.Expr, len(n.Names))
+				for i, name := range n.Names {
+					lhs[i] = name
+				}
+				record(lhs, n.Values)
+			case *ast.UnaryExpr:
+				// Taking the address allows reassignment we cannot follow
+				if id, ok := n.X.(*ast.Ident); ok && n.Op == token.AND && r.objectOf(id) == v {
+					assignments++
+				}
+			}
+			return true
+		})
+	}
+	return value, assignments
+}
+
+// resolveFuncVarByName traces a local function-typed variable to the function
+// value it was assigned without type information, as in
+// f := func() { recover() }; defer f(). Chains of variables such as
+// g := f are followed, and a chain that leads back to a variable already seen,
+// as with a, b := b, a, is left unresolved. See assignedFuncByName for how
+// each variable is looked up.
+func (r *Analyzer) resolveFuncVarByName(ident *ast.Ident) ast.Expr {
+	var resolved ast.Expr
+	seen := make(map[*ast.Ident]bool)
+	for !seen[ident] {
+		seen[ident] = true
+		switch value := r.assignedFuncByName(ident).(type) {
+		case nil:
+			return resolved
+		case *ast.Ident:
+			resolved, ident = value, value
+		default:
+			return value
+		}
+	}
+	r.debugf(ident.Pos(), "variable %s assigned in a cycle: unresolved", ident.Name)
+	return nil
+}
+
+// assignedFuncByName looks up the variable ident by name in the functions
+// enclosing it, innermost first. It returns the value assigned if the
+// function declaring it assigns that name exactly once, anywhere in its body,
+// to a function literal or another name; parameters and names assigned more
+// than once are left unresolved.
+func (r *Analyzer) assignedFuncByName(ident *ast.Ident) ast.Expr {
+	var file *ast.File
+	for _, f := range r.Pass.Files {
+		if f.FileStart <= ident.Pos() && ident.Pos() <= f.FileEnd {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return nil
+	}
+
+	path, _ := astutil.PathEnclosingInterval(file, ident.Pos(), ident.End())
+	for _, node := range path {
+		var fields []*ast.FieldList
+		var scope ast.Node
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			fields = []*ast.FieldList{node.Type.Params, node.Type.Results}
+			if node.Body != nil {
+				scope = node.Body
+			}
+		case *ast.FuncDecl:
+			fields = []*ast.FieldList{node.Recv, node.Type.Params, node.Type.Results}
+			if node.Body != nil {
+				scope = node.Body
+			}
+		default:
+			// A block or statement declaring the name before ident, as in
+			// if cond { f := work; go f() }
+			if shadow := redeclaration(node, ident.Name); !shadow.IsValid() || shadow > ident.Pos() {
+				continue
+			}
+			scope = node
+		}
+
+		if declaresName(fields, ident.Name) || scope == nil {
+			return nil
+		}
+		value, assignments, defined := assignedValue(scope, ident.Name)
+		if !defined {
+			if scope == node {
+				// Declared without a value, as a range variable
+				return nil
+			}
+			continue
+		}
+		if assignments != 1 {
+			return nil
+		}
+		switch value := value.(type) {
+		case *ast.FuncLit:
+			return value
+		case *ast.Ident:
+			if value.Name != ident.Name {
+				return value
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// declaresName checks if a function's receiver, parameters or results
+// include name
+func declaresName(fieldLists []*ast.FieldList, name string) bool {
+	for _, fields := range fieldLists {
+		if fields == nil {
+			continue
+		}
+		for _, field := range fields.List {
+			for _, fieldName := range field.Names {
+				if fieldName.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// assignedValue finds the assignments to name in scope, a function body or
+// a nested block or statement, including those in nested function literals
+// and blocks. It returns the last value assigned, the
+// number of assignments, and whether name is declared in body by := or var.
+// A nested scope declaring name again, such as a function literal with a
+// parameter of that name or a block with name := value, refers to another
+// variable from its declaration on, so only the assignments before it are
+// counted, without a known value.
+func assignedValue(scope ast.Node, name string) (value ast.Expr, assignments int, defined bool) {
+	record := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for i, expr := range lhs {
+			if id, ok := expr.(*ast.Ident); !ok || id.Name != name {
+				continue
+			}
+			assignments++
+			value = nil
+			if len(lhs) == len(rhs) {
+				value = rhs[i]
+			}
+		}
+	}
+
+	ast.Inspect(scope, func(n ast.Node) bool {
+		if n != scope {
+			if shadow := redeclaration(n, name); shadow.IsValid() {
+				before := assignmentsBefore(n, name, shadow)
+				assignments += before
+				if before > 0 {
+					value = nil
+				}
+				return false
+			}
+		}
+
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			record(n.Lhs, n.Rhs)
+			if n.Tok == token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok && id.Name == name {
+						defined = true
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, id := range n.Names {
+				lhs[i] = id
+				if id.Name == name {
+					defined = true
+				}
+			}
+			record(lhs, n.Values)
+		case *ast.UnaryExpr:
+			// Taking the address allows reassignment we cannot follow
+			if id, ok := n.X.(*ast.Ident); ok && n.Op == token.AND && id.Name == name {
+				assignments++
+			}
+		}
+		return true
+	})
+	return value, assignments, defined
+}
+
+// redeclaration returns the position from which a scope-opening node
+// declares name again in its own scope, or token.NoPos if it doesn't. The
+// parameters and results of a function literal shadow name in all of it.
+func redeclaration(n ast.Node, name string) token.Pos {
+	var stmts []ast.Stmt
+	switch n := n.(type) {
+	case *ast.FuncLit:
+		if n.Type != nil && declaresName([]*ast.FieldList{n.Type.Params, n.Type.Results}, name) {
+			return n.Pos()
+		}
+	case *ast.BlockStmt:
+		stmts = n.List
+	case *ast.CaseClause:
+		stmts = n.Body
+	case *ast.CommClause:
+		stmts = append([]ast.Stmt{n.Comm}, n.Body...)
+	case *ast.IfStmt:
+		stmts = []ast.Stmt{n.Init}
+	case *ast.SwitchStmt:
+		stmts = []ast.Stmt{n.Init}
+	case *ast.TypeSwitchStmt:
+		stmts = []ast.Stmt{n.Init, n.Assign}
+	case *ast.ForStmt:
+		stmts = []ast.Stmt{n.Init}
+	case *ast.RangeStmt:
+		if n.Tok == token.DEFINE {
+			for _, expr := range []ast.Expr{n.Key, n.Value} {
+				if id, ok := expr.(*ast.Ident); ok && id.Name == name {
+					return n.Pos()
+				}
+			}
+		}
+	}
+
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE {
+				continue
+			}
+			for _, lhs := range stmt.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == name {
+					return stmt.Pos()
+				}
+			}
+		case *ast.DeclStmt:
+			gen, ok := stmt.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if spec, ok := spec.(*ast.ValueSpec); ok && slices.ContainsFunc(spec.Names, func(id *ast.Ident) bool { return id.Name == name }) {
+					return stmt.Pos()
+				}
+			}
+		}
+	}
+	return token.NoPos
+}
+
+// assignmentsBefore counts the assignments to name in n before pos, where a
+// nested scope declares name again, and the places its address is taken
+func assignmentsBefore(n ast.Node, name string, pos token.Pos) int {
+	assignments := 0
+	ast.Inspect(n, func(n ast.Node) bool {
+		if n == nil || n.Pos() >= pos {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				break
+			}
+			for _, lhs := range n.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == name {
+					assignments++
+				}
+			}
+		case *ast.UnaryExpr:
+			if id, ok := n.X.(*ast.Ident); ok && n.Op == token.AND && id.Name == name {
+				assignments++
+			}
+		}
+		return true
+	})
+	return assignments
+}
+
+// funcField returns the struct field of function type that sel selects, as in
+// go s.handler(), or nil if sel selects anything else
+func (r *Analyzer) funcField(sel *ast.SelectorExpr) *types.Var {
+	if r.Pass == nil || r.Pass.TypesInfo == nil {
+		return nil
+	}
+	selection, ok := r.Pass.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.FieldVal {
+		return nil
+	}
+	if _, ok := selection.Type().Underlying().(*types.Signature); !ok {
+		return nil
+	}
+	field, _ := selection.Obj().(*types.Var)
+	return field
+}
+
+// resolveFuncField returns the function assigned to a struct field of
+// function type when the package assigns it exactly once, either as
+// x.field = value or in a composite literal. It returns nil otherwise.
+func (r *Analyzer) resolveFuncField(field *types.Var) ast.Expr {
+	var value ast.Expr
+	assignments := 0
+
+	for _, file := range r.Pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					sel, ok := lhs.(*ast.SelectorExpr)
+					if !ok || r.funcField(sel) != field {
+						continue
+					}
+					assignments++
+					value = nil
+					if len(n.Lhs) == len(n.Rhs) {
+						value = n.Rhs[i]
+					}
+				}
+			case *ast.CompositeLit:
+				t := r.Pass.TypesInfo.TypeOf(n)
+				if t == nil {
+					return true
+				}
+				st, ok := t.Underlying().(*types.Struct)
+				if !ok {
+					return true
+				}
+				for i, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := kv.Key.(*ast.Ident); ok && r.Pass.TypesInfo.Uses[key] == field {
+							assignments++
+							value = kv.Value
+						}
+					} else if i < st.NumFields() && st.Field(i) == field {
+						assignments++
+						value = elt
+					}
+				}
+			case *ast.UnaryExpr:
+				// Taking the address allows reassignment we cannot follow
+				if sel, ok := n.X.(*ast.SelectorExpr); ok && n.Op == token.AND && r.funcField(sel) == field {
+					assignments++
+				}
+			}
+			return true
+		})
+	}
+
+	if assignments != 1 || value == nil {
+		return nil
+	}
+
+	switch value := value.(type) {
+	case *ast.FuncLit:
+		return value
+	case *ast.SelectorExpr:
+		// Don't chase one function field into another
+		if r.funcField(value) == nil {
+			return value
+		}
+	case *ast.Ident:
+		if _, ok := r.objectOf(value).(*types.Func); ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// isUnresolvedFuncField checks if fun calls a struct field of function type
+// whose value couldn't be resolved
+func (r *Analyzer) isUnresolvedFuncField(fun ast.Expr) bool {
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	field := r.funcField(sel)
+	return field != nil && r.resolveFuncField(field) == nil
+}
+
+// isDynamicTarget checks if fun is dispatched dynamically, so that the
+// function a goroutine runs can't be known statically: an element of a map,
+// slice or array of functions, as in go handlers[name](), a reflect.Value
+// called through Call or CallSlice, or an interface method of a value whose
+// dynamic type is unknown. Dynamic targets are only recognized with type
+// information.
+func (r *Analyzer) isDynamicTarget(fun ast.Expr) bool {
+	if r.Pass == nil || r.Pass.TypesInfo == nil {
+		return false
+	}
+
+	switch fun := r.funcValue(fun).(type) {
+	case *ast.IndexExpr:
+		return r.instantiatedFunc(fun) == nil && r.funcCandidates(fun) == nil
+	case *ast.SelectorExpr:
+		if r.isInterfaceMethod(fun) {
+			return r.dynamicMethod(fun) == nil
+		}
+		if fn := r.concreteMethod(fun); fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "reflect" {
+			return fn.Name() == "Call" || fn.Name() == "CallSlice"
+		}
+	}
+	return false
+}
+
+// objectOf returns the object an identifier defines or refers to
+func (r *Analyzer) objectOf(ident *ast.Ident) types.Object {
+	if obj := r.Pass.TypesInfo.Defs[ident]; obj != nil {
+		return obj
+	}
+	return r.Pass.TypesInfo.Uses[ident]
+}
+
+// isRecoveryFunction checks if a named function contains recovery logic of
+// its own. A function that only returns a recovering closure, as in
+// func handler() func() { return func() { recover() } }, doesn't recover.
+func (r *Analyzer) isRecoveryFunction(funcName string) bool {
+	if hasRecover, exists := r.cachedRecovery(funcName); exists {
+		r.debugf(token.NoPos, "RecoverFunctions[%q] = %v", funcName, hasRecover)
+		return hasRecover
+	}
+	// Unknown functions are assumed unsafe
+	r.debugf(token.NoPos, "RecoverFunctions[%q] not found: unsafe", funcName)
+	return false
+}
+
+// isRecoveryMethod checks if the methods named methodName contain recovery
+// logic, for method calls whose receiver type is unknown. The methods of all
+// receiver types must agree; unknown methods are assumed unsafe.
+func (r *Analyzer) isRecoveryMethod(methodName string) bool {
+	r.lockShared()
+	defer r.unlockShared()
+
+	found := false
+	for key, hasRecover := range r.RecoverFunctions {
+		// Skip the pkg.Func entries cached for imported functions
+		typeName, name, ok := strings.Cut(key, ".")
+		if !ok || name != methodName || r.isImportName(typeName) {
+			continue
+		}
+		if !hasRecover {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// isImportName checks if name refers to a package imported by one of the
+// pass's files
+func (r *Analyzer) isImportName(name string) bool {
+	for _, file := range r.Pass.Files {
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			importName := packageName(path)
+			if spec.Name != nil {
+				importName = spec.Name.Name
+			}
+			if importName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCrossPackageRecoveryFunction handles pkg.Function() calls and methods
+// reached through longer selector chains
+func (r *Analyzer) isCrossPackageRecoveryFunction(sel *ast.SelectorExpr) bool {
+	funcName := sel.Sel.Name
+
+	// Interface methods have no body to analyze, classify them per their
+	// directives and the settings
+	if fn := r.interfaceMethod(sel); fn != nil {
+		safe := r.isInterfaceMethodSafe(fn)
+		r.debugf(sel.Pos(), "interface method %s: safe=%v", fn.FullName(), safe)
+		return safe
+	}
+
+	// Check if we have explicit knowledge of this cross-package function
+	if pkgIdent, ok := sel.X.(*ast.Ident); ok {
+		// Use type information to resolve the actual package (handles both
+		// regular and aliased imports). Key by import path, since the same
+		// alias can name different packages in different files.
+		var imported *types.Package
+		key := pkgIdent.Name + "." + funcName
+		if r.Pass.TypesInfo != nil {
+			if pkgName, ok := r.Pass.TypesInfo.Uses[pkgIdent].(*types.PkgName); ok {
+				imported = pkgName.Imported()
+				key = imported.Path() + "." + funcName
+			}
+		}
+
+		if hasRecover, exists := r.cachedRecovery(key); exists {
+			r.debugf(sel.Pos(), "RecoverFunctions[%q] = %v (cached)", key, hasRecover)
+			return hasRecover
+		}
+
+		// Without type information, a selector on anything but an imported
+		// package is a method call such as s.recover()
+		if r.Pass.TypesInfo == nil && !r.isImportName(pkgIdent.Name) {
+			recovers := r.isRecoveryMethod(funcName)
+			r.debugf(sel.Pos(), "method %s matched by name: recovers=%v", funcName, recovers)
+			return recovers
+		}
+
+		var hasRecovery bool
+		if imported != nil {
+			hasRecovery = r.analyzeCrossPackageFunction(imported, funcName)
+		} else {
+			r.debugf(sel.Pos(), "package %s could not be resolved", pkgIdent.Name)
+		}
+
+		r.debugf(sel.Pos(), "RecoverFunctions[%q] = %v", key, hasRecovery)
+		r.cacheRecovery(key, hasRecovery)
+		return hasRecovery
+	}
+
+	// Selector chains such as pkg.Var.Method or s.field.Method resolve
+	// through type information to the method they denote
+	if fn := r.funcObjectOf(sel); fn != nil {
+		key := fn.FullName()
+		if hasRecover, exists := r.cachedRecovery(key); exists {
+			r.debugf(sel.Pos(), "RecoverFunctions[%q] = %v (cached)", key, hasRecover)
+			return hasRecover
+		}
+
+		hasRecovery, ok := r.recoversByFact(fn)
+		if !ok && fn.Pos().IsValid() {
+			hasRecovery = r.analyzeFunctionFromPosition(fn)
+		}
+		r.debugf(sel.Pos(), "RecoverFunctions[%q] = %v", key, hasRecovery)
+		r.cacheRecovery(key, hasRecovery)
+		return hasRecovery
+	}
+
+	r.debugf(sel.Pos(), "selector %s could not be resolved: unsafe", funcName)
+	return false
+}
+
+// isInterfaceMethod checks if a selector refers to an interface method, either
+// directly or promoted through an embedded interface
+func (r *Analyzer) isInterfaceMethod(sel *ast.SelectorExpr) bool {
+	return r.interfaceMethod(sel) != nil
+}
+
+// interfaceMethod returns the interface method a selector refers to, or nil
+func (r *Analyzer) interfaceMethod(sel *ast.SelectorExpr) *types.Func {
+	if r.Pass.TypesInfo == nil {
+		return nil
+	}
+
+	selection, ok := r.Pass.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal {
+		return nil
+	}
+
+	fn, ok := selection.Obj().(*types.Func)
+	if !ok {
+		return nil
+	}
+	if recv := fn.Type().(*types.Signature).Recv(); recv == nil || !types.IsInterface(recv.Type()) {
+		return nil
+	}
+	return fn
+}
+
+// isInterfaceMethodSafe classifies an interface method call. Methods whose
+// declaration in the interface carries a //recovercheck:safe directive are
+// trusted; others follow AssumeInterfaceMethodsSafe.
+func (r *Analyzer) isInterfaceMethodSafe(fn *types.Func) bool {
+	if r.Settings != nil && r.Settings.AssumeInterfaceMethodsSafe {
+		return true
+	}
+
+	file, matches := r.syntaxAt(fn.Pos())
+	if file == nil {
+		return false
+	}
+
+	safe := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		iface, ok := n.(*ast.InterfaceType)
+		if !ok {
+			return !safe
+		}
+		for _, field := range iface.Methods.List {
+			for _, name := range field.Names {
+				if matches(name.Pos()) {
+					safe = hasSafeDirective(field.Doc) || hasSafeDirective(field.Comment)
+				}
+			}
+		}
+		return !safe
+	})
+	return safe
+}
+
+// hasSafeDirective checks if a comment group contains the //recovercheck:safe directive
+func hasSafeDirective(group *ast.CommentGroup) bool {
+	if group == nil {
+		return false
+	}
+	for _, comment := range group.List {
+		text := strings.TrimSpace(comment.Text)
+		if text == safeDirective || strings.HasPrefix(text, safeDirective+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeCrossPackageFunction analyzes a function from an imported package
+func (r *Analyzer) analyzeCrossPackageFunction(pkg *types.Package, funcName string) bool {
+	// Look for the function in the package scope
+	if obj := pkg.Scope().Lookup(funcName); obj != nil {
+		// Try to get the function declaration from the object
+		if funcObj, ok := obj.(*types.Func); ok {
+			// Prefer the fact exported when the package itself was analyzed
+			if recovers, ok := r.recoversByFact(funcObj); ok {
+				r.debugf(token.NoPos, "%s resolved by fact: recovers=%v", funcObj.FullName(), recovers)
+				return recovers
+			}
+			// Find the function declaration in the imported package's files
+			if pos := funcObj.Pos(); pos.IsValid() {
+				if recovers, found := r.recoversAt(funcObj); found {
+					r.debugf(token.NoPos, "%s resolved from source: recovers=%v", funcObj.FullName(), recovers)
+					return recovers
+				}
+			}
+		}
+	}
+
+	// The function's source couldn't be found or parsed, or it has no body
+	safe := r.Settings != nil && r.Settings.AssumeExternalSafe
+	r.debugf(token.NoPos, "%s.%s could not be resolved: safe=%v", pkg.Path(), funcName, safe)
+	return safe
+}
+
+// analyzeFunctionFromPosition finds and analyzes a function from its declaring position
+func (r *Analyzer) analyzeFunctionFromPosition(fn *types.Func) bool {
+	// If we can't find the function, assume it's unsafe
+	recovers, _ := r.recoversAt(fn)
+	return recovers
+}
+
+// findFuncDecl locates the declaration of the named function at pos. Syntax
+// already parsed for the current pass is preferred; otherwise the declaring
+// file is parsed from disk into a separate file set, so the pass's file set
+// is not extended with positions nobody else knows about. pos comes from the
+// type checker, so of a function declared in several build-tagged files it
+// is the declaration in the build.
+func (r *Analyzer) findFuncDecl(funcName string, pos token.Pos) *ast.FuncDecl {
+	file, matches := r.syntaxAt(pos)
+	if file == nil {
+		// If we can't parse the file, assume it's unsafe
+		return nil
+	}
+	return funcDeclAt(file, funcName, matches)
+}
+
+// syntaxAt returns the file containing pos along with a function reporting
+// whether a position within that file corresponds to pos. Files of the
+// current pass are reused; other files are parsed from disk, once per pass,
+// into a separate file set, in which case positions are compared by offset.
+// A file on disk whose size differs from the one the driver loaded, as with
+// an overlay or unsaved edits, isn't used, since its offsets don't match.
+func (r *Analyzer) syntaxAt(pos token.Pos) (*ast.File, func(token.Pos) bool) {
+	for _, file := range r.Pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file, func(p token.Pos) bool { return p == pos }
+		}
+	}
+
+	// Get the position information. Synthetic positions, such as those of
+	// cgo-generated declarations, may name no file to parse.
+	position := r.Pass.Fset.Position(pos)
+	if !position.IsValid() || position.Filename == "" {
+		r.debugf(token.NoPos, "declaration at %v has no source file", position)
+		return nil, nil
+	}
+
+	parsed := r.parseFile(position.Filename)
+	if parsed.file == nil {
+		return nil, nil
+	}
+	if loaded := r.Pass.Fset.File(pos); loaded != nil && parsed.fset.File(parsed.file.FileStart).Size() != loaded.Size() {
+		r.debugf(token.NoPos, "%s on disk differs from the loaded source", position.Filename)
+		return nil, nil
+	}
+
+	return parsed.file, func(p token.Pos) bool {
+		return parsed.fset.Position(p).Offset == position.Offset
+	}
+}
+
+// parseFile parses a file of another package, at most once per pass. The
+// shared lock is only held to look up the file, so concurrent workers parse
+// different files in parallel, and wait for a file another worker is
+// parsing.
+func (r *Analyzer) parseFile(filename string) *parsedFile {
+	r.lockShared()
+	parsed, ok := r.parsedFiles[filename]
+	if !ok {
+		if r.parsedFiles == nil {
+			r.parsedFiles = make(map[string]*parsedFile)
+		}
+		parsed = &parsedFile{fset: token.NewFileSet()}
+		r.parsedFiles[filename] = parsed
+	}
+	r.unlockShared()
+
+	parsed.once.Do(func() {
+		r.debugf(token.NoPos, "parsing %s", filename)
+		file, err := parser.ParseFile(parsed.fset, filename, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err == nil {
+			parsed.file = file
+		}
+	})
+	return parsed
+}
+
+// funcDeclAt finds the declaration of the named function whose name is at a
+// matching position in file
+func funcDeclAt(file *ast.File, funcName string, matches func(token.Pos) bool) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name != nil && fn.Name.Name == funcName && matches(fn.Name.Pos()) {
+			return fn
+		}
+	}
+	return nil
+}
+
+// containsRecover performs a deep search for recover() calls in any AST node
+func (r *Analyzer) containsRecover(node ast.Node) bool {
+	return r.recoverFinder().containsRecover(node)
+}
+
+// recoverFinder returns a finder that resolves deferred named functions
+// through the analyzer's knowledge of the package and its imports
+func (r *Analyzer) recoverFinder() *recoverFinder {
+	finder := &recoverFinder{settings: r.Settings, resolve: r.isDeferredRecoveryFunction, rethrows: r.panickingHelper}
+	if r.Pass != nil {
+		finder.info = r.Pass.TypesInfo
+	}
+	return finder
+}
+
+// isDeferredRecoveryFunction checks if a deferred named function contains recovery logic.
+// recover only stops a panic when called directly by the deferred function, so
+// the function must call recover itself; one that merely returns a recovering
+// closure only helps when that closure is deferred, as in defer factory()().
+func (r *Analyzer) isDeferredRecoveryFunction(fun ast.Expr) bool {
+	if r.isSafeFunc(fun) {
+		return true
+	}
+	if generic := r.instantiatedFunc(fun); generic != nil {
+		fun = generic
+	}
+
+	switch fun := fun.(type) {
+	case *ast.FuncLit:
+		// Reached through a local variable holding a function literal
+		return r.recoverFinder().handlesRecover(fun.Body)
+	case *ast.Ident:
+		// Check for defer localVar(), including a local variable shadowing the
+		// recover builtin, by classifying the function it was assigned
+		if value := r.resolveFuncVar(fun); value != nil {
+			return r.isDeferredRecoveryFunction(value)
+		}
+		// Check for defer someRecoveryFunc(), preferring the fact of a
+		// function of a dot-imported package
+		if handles, ok := r.handlesByFact(r.funcObjectOf(fun)); ok {
+			return handles
+		}
+		if funcDecl := r.funcDeclOf(fun); funcDecl != nil {
+			return funcDecl.Body != nil && r.recoverFinder().handlesRecover(funcDecl.Body)
+		}
+		return r.isRecoveryFunction(fun.Name)
+	case *ast.SelectorExpr:
+		// Check for defer pkg.RecoveryFunc()
+		if r.isInterfaceMethod(fun) {
+			return r.isCrossPackageRecoveryFunction(fun)
+		}
+		// Prefer the fact exported when the function's package was analyzed
+		if handles, ok := r.handlesByFact(r.funcObjectOf(fun)); ok {
+			return handles
+		}
+		// Functions without a body, such as ones implemented in assembly,
+		// are classified like functions whose source can't be found
+		if funcDecl := r.funcDeclOf(fun); funcDecl != nil && funcDecl.Body != nil {
+			return r.recoverFinder().handlesRecover(funcDecl.Body)
+		}
+		return r.isCrossPackageRecoveryFunction(fun)
+	case *ast.CallExpr:
+		// Check for defer recoveryFactory()()
+		if funcDecl := r.funcDeclOf(fun.Fun); funcDecl != nil {
+			return funcDecl.Body != nil && r.recoverFinder().returnsRecoverHandler(funcDecl.Body)
+		}
+	}
+	return false
+}
+
+// packageFuncResolver returns a resolver of the deferred calls in syntax of
+// package pkg parsed apart from the pass, which the pass's type information
+// doesn't cover: a call of a function is resolved by name in pkg's scope, so
+// that it isn't mistaken for a function of the analyzing package, and other
+// calls by isDeferredRecoveryFunction. seen holds the helpers being
+// classified, so helpers deferring each other don't recurse forever.
+func (r *Analyzer) packageFuncResolver(pkg *types.Package, seen map[*types.Func]bool) func(fun ast.Expr) bool {
+	return func(fun ast.Expr) bool {
+		ident, ok := fun.(*ast.Ident)
+		if !ok {
+			return r.isDeferredRecoveryFunction(fun)
+		}
+		helper, ok := pkg.Scope().Lookup(ident.Name).(*types.Func)
+		if !ok {
+			return r.isDeferredRecoveryFunction(fun)
+		}
+		if seen[helper] || !helper.Pos().IsValid() {
+			return false
+		}
+		seen[helper] = true
+		defer delete(seen, helper)
+
+		funcDecl := r.findFuncDecl(helper.Name(), helper.Pos())
+		if funcDecl == nil || funcDecl.Body == nil {
+			return false
+		}
+		finder := r.recoverFinder()
+		finder.resolve = r.packageFuncResolver(pkg, seen)
+		return finder.handlesRecover(funcDecl.Body)
+	}
+}
+
+// funcValue strips parentheses, conversions to function types and
+// sync.OnceFunc, sync.OnceValue and sync.OnceValues wrappers from a
+// function-valued expression, so go (func() { ... })(), go (Task(f))() and
+// go sync.OnceFunc(f)() are classified by the function they wrap. The
+// wrapped function runs in the caller's goroutine, so its recovery stops a
+// panic before the wrapper re-raises it. Conversions and wrappers are only
+// recognized with type information.
+func (r *Analyzer) funcValue(fun ast.Expr) ast.Expr {
+	for {
+		switch e := fun.(type) {
+		case *ast.ParenExpr:
+			fun = e.X
+			continue
+		case *ast.CallExpr:
+			if len(e.Args) == 1 && r.Pass != nil && r.Pass.TypesInfo != nil {
+				if tv, ok := r.Pass.TypesInfo.Types[e.Fun]; ok && tv.IsType() {
+					fun = e.Args[0]
+					continue
+				}
+				if r.isOnceWrapper(e.Fun) {
+					fun = e.Args[0]
+					continue
+				}
+			}
+		}
+		return fun
+	}
+}
+
+// isOnceWrapper checks if fun denotes sync.OnceFunc, sync.OnceValue or
+// sync.OnceValues, including an explicit instantiation such as
+// sync.OnceValue[int]
+func (r *Analyzer) isOnceWrapper(fun ast.Expr) bool {
+	fn := r.funcObjectOf(astutil.Unparen(fun))
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "sync" {
+		return false
+	}
+	switch fn.Name() {
+	case "OnceFunc", "OnceValue", "OnceValues":
+		return true
+	}
+	return false
+}
+
+// instantiatedFunc returns the generic function of an explicit instantiation
+// such as SafeRun[int] or pkg.Map[K, V], or nil if fun isn't one. Without
+// type information any index expression is assumed to be an instantiation.
+func (r *Analyzer) instantiatedFunc(fun ast.Expr) ast.Expr {
+	var generic ast.Expr
+	switch fun := fun.(type) {
+	case *ast.IndexExpr:
+		generic = fun.X
+	case *ast.IndexListExpr:
+		generic = fun.X
+	default:
+		return nil
+	}
+
+	if r.Pass != nil && r.Pass.TypesInfo != nil {
+		// Indexing a slice or map of functions is not an instantiation
+		if _, ok := r.Pass.TypesInfo.TypeOf(generic).(*types.Signature); !ok {
+			return nil
+		}
+	}
+	return generic
+}
+
+// funcDeclOf resolves a function or method reference to its declaration using
+// type information. It returns nil when the reference cannot be resolved.
+func (r *Analyzer) funcDeclOf(fun ast.Expr) *ast.FuncDecl {
+	fn := r.funcObjectOf(fun)
+	if fn == nil || !fn.Pos().IsValid() {
+		return nil
+	}
+	return r.findFuncDecl(fn.Name(), fn.Pos())
+}
+
+// funcObjectOf returns the function or method a reference denotes, or nil
+// without type information or if fun doesn't denote one
+func (r *Analyzer) funcObjectOf(fun ast.Expr) *types.Func {
+	if generic := r.instantiatedFunc(fun); generic != nil {
+		fun = generic
+	}
+
+	if r.Pass == nil || r.Pass.TypesInfo == nil {
+		return nil
+	}
+
+	var ident *ast.Ident
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+
+	fn, _ := r.Pass.TypesInfo.Uses[ident].(*types.Func)
+	return fn
+}
+
+// HasRecovery reports whether body establishes panic recovery, either through
+// a recover() call or a deferred function literal that calls recover().
+//
+// Unlike the analyzer, HasRecovery keeps no state between calls and does not
+// follow deferred calls to named functions, since their declarations are not
+// available to it. info may be nil; when present it is used to make sure
+// recover refers to the builtin rather than a user-defined identifier.
+func HasRecovery(body *ast.BlockStmt, info *types.Info) bool {
+	if body == nil {
+		return false
+	}
+	finder := &recoverFinder{info: info}
+	return finder.containsRecover(body)
+}
+
+// recoverFinder implements the recover detection heuristics shared by the
+// analyzer and HasRecovery
+type recoverFinder struct {
+	info     *types.Info
+	settings *RecovercheckSettings // nil uses the defaults
+	// resolve classifies deferred calls to named functions; nil treats them as unsafe
+	resolve func(fun ast.Expr) bool
+	// rethrows names the helper a call invokes if it always panics, or
+	// returns ""; nil only recognizes panic itself
+	rethrows func(call *ast.CallExpr) string
+}
+
+// containsRecover performs a deep search for recover() calls in any AST node.
+// With RequireUnconditionalRecover, defers nested in a function body's
+// conditionals, loops or blocks are not searched.
+func (f *recoverFinder) containsRecover(node ast.Node) bool {
+	if f.settings != nil && f.settings.RequireUnconditionalRecover {
+		if body, ok := node.(*ast.BlockStmt); ok {
+			return f.findRecoverCall(body, conditionalDefers(body))
+		}
+	}
+	return f.findRecoverCall(node, nil)
+}
+
+// findRecoverCall recursively searches for recover() calls, ignoring the
+// defer statements in skip
+func (f *recoverFinder) findRecoverCall(node ast.Node, skip map[*ast.DeferStmt]bool) bool {
+	found := false
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if found {
+			return false // Stop traversal once found
+		}
+
+		switch node := n.(type) {
+		case *ast.GoStmt, *ast.FuncLit:
+			// A recover() in a nested goroutine only protects that
+			// goroutine, and one in a function literal that isn't deferred
+			// here doesn't stop a panic in this function. Deferred literals
+			// are handled by isDeferredRecovery.
+			return false
+		case *ast.CallExpr:
+			if isMalformedCall(node) {
+				return false
+			}
+			if f.isRecoverCall(node) {
+				found = true
+				return false
+			}
+		case *ast.DeferStmt:
+			if skip[node] || isMalformedCall(node.Call) {
+				return false
+			}
+			if f.isDeferredRecovery(node) {
+				found = true
+				return false
+			}
+			if f.isSkippableDeferredRecovery(node) {
+				// Don't count the recover() inside it either
+				return false
+			}
+			// Any other defer, such as defer mu.Unlock(), is searched like
+			// a plain call: it only counts if a recover() call is inside
+		case *ast.BlockStmt:
+			// search for CallExpr and DeferStmt within the statements that
+			// can run, ignoring dead code after a return or panic
+			for _, stmt := range f.reachableStmts(node.List) {
+				if f.findRecoverCall(stmt, skip) {
+					found = true
+					break
+				}
+			}
+			return false
+		}
+		return true
+	})
+
+	return found
+}
+
+// isRecoverCall checks if a call expression is a direct recover() call. With
+// type information the identifier must resolve to the builtin, so a local or
+// package-level function named recover doesn't count.
+func (f *recoverFinder) isRecoverCall(call *ast.CallExpr) bool {
+	ident, ok := call.Fun.(*ast.Ident)
+	if !ok || ident.Name != "recover" {
+		return false
+	}
+	if f.info != nil {
+		if obj, ok := f.info.Uses[ident]; ok {
+			_, isBuiltin := obj.(*types.Builtin)
+			return isBuiltin
+		}
+	}
+	return true
+}
+
+// handlesRecover checks if a deferred function body calls recover() and,
+// when RequireHandledRecover is set, uses the recovered value
+func (f *recoverFinder) handlesRecover(body *ast.BlockStmt) bool {
+	if !f.callsRecover(body) {
+		return false
+	}
+	return f.settings == nil || !f.settings.RequireHandledRecover || f.isHandledRecover(body)
+}
+
+// isHandledRecover checks if a deferred function body uses the value it
+// recovers: passing recover() to a call, referencing a variable bound to
+// it, or testing either against nil with a non-empty branch. A variable
+// that is only compared to nil doesn't count.
+func (f *recoverFinder) isHandledRecover(body *ast.BlockStmt) bool {
+	recovered := make(map[string]bool)
+	bindings := make(map[*ast.Ident]bool)
+	bind := func(lhs []ast.Expr, rhs []ast.Expr) {
+		// v, ok := recover().(T) binds the asserted value to v
+		if len(lhs) == 2 && len(rhs) == 1 {
+			if _, ok := ast.Unparen(rhs[0]).(*ast.TypeAssertExpr); ok {
+				lhs = lhs[:1]
+			}
+		}
+		if len(lhs) != len(rhs) {
+			return
+		}
+		for i, value := range rhs {
+			if !f.isRecoveredValue(value) {
+				continue
+			}
+			if ident, ok := lhs[i].(*ast.Ident); ok && ident.Name != "_" {
+				recovered[ident.Name] = true
+				bindings[ident] = true
+			}
+		}
+	}
+	isRecovered := func(expr ast.Expr) bool {
+		switch expr := expr.(type) {
+		case *ast.CallExpr:
+			return f.isRecoverCall(expr)
+		case *ast.Ident:
+			return recovered[expr.Name]
+		}
+		return false
+	}
+
+	handled := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if handled {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			bind(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			bind(lhs, n.Values)
+		case *ast.IfStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok {
+				bind(init.Lhs, init.Rhs)
+			}
+			if isNilComparison(n.Cond, isRecovered) && (len(n.Body.List) > 0 || n.Else != nil) {
+				handled = true
+			}
+		case *ast.TypeSwitchStmt:
+			// switch r := recover().(type) tests the recovered value by type,
+			// so a non-empty case handles it like a non-empty nil check
+			if f.isRecoverTypeSwitch(n) && slices.ContainsFunc(n.Body.List, func(clause ast.Stmt) bool {
+				return len(clause.(*ast.CaseClause).Body) > 0
+			}) {
+				handled = true
+			}
+		case *ast.BinaryExpr:
+			if isNilComparison(n, isRecovered) {
+				// Comparing to nil alone doesn't use the value
+				return false
+			}
+		case *ast.CallExpr:
+			for _, arg := range n.Args {
+				if call, ok := arg.(*ast.CallExpr); ok && f.isRecoverCall(call) {
+					handled = true
+				}
+			}
+		case *ast.Ident:
+			if recovered[n.Name] && !bindings[n] {
+				handled = true
+			}
+		}
+		return !handled
+	})
+	return handled
+}
+
+// isNilComparison checks if cond compares an expression matching operand
+// with nil, using == or !=
+func isNilComparison(cond ast.Expr, operand func(ast.Expr) bool) bool {
+	binary, ok := cond.(*ast.BinaryExpr)
+	if !ok || (binary.Op != token.EQL && binary.Op != token.NEQ) {
+		return false
+	}
+	isNil := func(e ast.Expr) bool {
+		ident, ok := e.(*ast.Ident)
+		return ok && ident.Name == "nil"
+	}
+	return (operand(binary.X) && isNil(binary.Y)) || (isNil(binary.X) && operand(binary.Y))
+}
+
+// callsRecover checks if body calls recover() itself rather than from a
+// nested function literal. Only such functions recover when deferred.
+func (f *recoverFinder) callsRecover(body *ast.BlockStmt) bool {
+	found := false
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if f.isRecoverCall(n) {
+				found = true
+			}
+		case *ast.BlockStmt:
+			// Dead code after a return or panic never recovers
+			for _, stmt := range f.reachableStmts(n.List) {
+				if !found {
+					ast.Inspect(stmt, visit)
+				}
+			}
+			return false
+		}
+		return !found
+	}
+	ast.Inspect(body, visit)
+	return found
+}
+
+// returnsRecoverHandler checks if body returns a function literal that calls
+// recover(), making the result suitable for defer factory()()
+func (f *recoverFinder) returnsRecoverHandler(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Return statements inside literals belong to the literal
+			return false
+		case *ast.ReturnStmt:
+			for _, result := range n.Results {
+				if funcLit, ok := result.(*ast.FuncLit); ok && f.handlesRecover(funcLit.Body) {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// conditionalDefers returns the defer statements of a function body that are
+// nested in an if, for, switch, select or block, and so may never be
+// registered. Defers inside nested function literals belong to those
+// literals and are not included.
+func conditionalDefers(body *ast.BlockStmt) map[*ast.DeferStmt]bool {
+	defers := make(map[*ast.DeferStmt]bool)
+	for _, stmt := range body.List {
+		if _, ok := stmt.(*ast.DeferStmt); ok {
+			continue
+		}
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.DeferStmt:
+				defers[n] = true
+			}
+			return true
+		})
+	}
+	return defers
+}
+
+// isSkippableDeferredRecovery checks if a deferred function literal's
+// recovery can be skipped by an earlier return, when FlagGuardedRecover is
+// set, is undone by a re-panic, when DetectRepanic is set, or discards the
+// recovered value, when RequireHandledRecover is set
+func (f *recoverFinder) isSkippableDeferredRecovery(deferStmt *ast.DeferStmt) bool {
+	if f.settings == nil || isMalformedCall(deferStmt.Call) {
+		return false
+	}
+	if f.settings.RequireHandledRecover && f.isRecoverCall(deferStmt.Call) {
+		// defer recover() discards the value, and doesn't stop the panic either
+		return true
+	}
+	funcLit, ok := astutil.Unparen(deferStmt.Call.Fun).(*ast.FuncLit)
+	if !ok {
+		return false
+	}
+	return (f.settings.FlagGuardedRecover && f.isGuardedRecover(funcLit.Body)) ||
+		(f.settings.DetectRepanic && f.isRepanic(funcLit.Body)) ||
+		(f.settings.RequireHandledRecover && !f.isHandledRecover(funcLit.Body))
+}
+
+// isRepanic checks if a deferred function body re-panics every value it
+// recovers, directly or through a helper
+func (f *recoverFinder) isRepanic(body *ast.BlockStmt) bool {
+	_, ok := f.repanic(body)
+	return ok
+}
+
+// repanic checks if a deferred function body re-panics every value it
+// recovers: panic(recover()), r := recover(); panic(r), or a panic(r) at the
+// top level of if r := recover(); r != nil { ... }. A panic(r) behind any
+// other condition, or after a conditional return, filters what is re-raised
+// and doesn't count. In the same places, a call to a helper that always
+// panics, such as rethrow(r), re-panics too; its name is returned as via.
+func (f *recoverFinder) repanic(body *ast.BlockStmt) (via string, ok bool) {
+	recovered := ""
+	for _, stmt := range body.List {
+		switch stmt := stmt.(type) {
+		case *ast.ExprStmt:
+			if via, ok := f.repanicStmt(stmt, recovered); ok {
+				return via, true
+			}
+		case *ast.AssignStmt:
+			if name := f.recoveredVar(stmt); name != "" {
+				recovered = name
+			}
+		case *ast.IfStmt:
+			if init, ok := stmt.Init.(*ast.AssignStmt); ok {
+				if name := f.recoveredVar(init); name != "" {
+					recovered = name
+				}
+			}
+			if recovered == "" || stmt.Else != nil || !isNonNilCheck(stmt.Cond, recovered) {
+				break
+			}
+			for _, inner := range stmt.Body.List {
+				if exprStmt, ok := inner.(*ast.ExprStmt); ok {
+					if via, ok := f.repanicStmt(exprStmt, recovered); ok {
+						return via, true
+					}
+				}
+				// An earlier return filters which values reach the panic
+				if containsReturn(inner) {
+					break
+				}
+			}
+		}
+		if containsReturn(stmt) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// repanicStmt checks if stmt is panic(recover()) or panic(<recovered>), or,
+// once the value is recovered, a call to a helper that always panics
+func (f *recoverFinder) repanicStmt(stmt *ast.ExprStmt, recovered string) (via string, ok bool) {
+	if f.isPanicOf(stmt.X, recovered) {
+		return "", true
+	}
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok || f.rethrows == nil {
+		return "", false
+	}
+	recovers := recovered != "" || slices.ContainsFunc(call.Args, f.isRecoveredValue)
+	if via := f.rethrows(call); recovers && via != "" {
+		return via, true
+	}
+	return "", false
+}
+
+// isSwallowedRecover checks if body discards the value it recovers: assigns
+// it to the blank identifier, as in _ = recover(), or binds it to a variable
+// declared in body that is never read except to be discarded in turn, as in
+// r := recover(); _ = r. A variable declared outside body may be read once
+// it returns, so assigning one doesn't count.
+func (f *recoverFinder) isSwallowedRecover(body *ast.BlockStmt) bool {
+	swallowed := false
+	unread := make(map[string]bool)
+	ignored := make(map[*ast.Ident]bool) // bindings and discards, which aren't reads
+	bind := func(lhs []ast.Expr, rhs []ast.Expr, declares bool) {
+		// v, ok := recover().(T) binds the asserted value to v
+		if len(lhs) == 2 && len(rhs) == 1 {
+			if _, ok := ast.Unparen(rhs[0]).(*ast.TypeAssertExpr); ok {
+				lhs = lhs[:1]
+			}
+		}
+		if len(lhs) != len(rhs) {
+			return
+		}
+		for i, value := range rhs {
+			ident, ok := lhs[i].(*ast.Ident)
+			if !ok || !f.isRecoveredValue(value) {
+				continue
+			}
+			if ident.Name == "_" {
+				swallowed = true
+			} else if declares {
+				unread[ident.Name] = true
+				ignored[ident] = true
+			}
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// recover() calls in a nested literal don't recover for body.
+			// Reads of the variable in one are still found below.
+			return false
+		case *ast.AssignStmt:
+			bind(n.Lhs, n.Rhs, n.Tok == token.DEFINE)
+			// _ = r discards r again
+			if len(n.Lhs) == len(n.Rhs) {
+				for i, lhs := range n.Lhs {
+					blank, ok := lhs.(*ast.Ident)
+					value, isIdent := ast.Unparen(n.Rhs[i]).(*ast.Ident)
+					if ok && blank.Name == "_" && isIdent {
+						ignored[value] = true
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			bind(lhs, n.Values, true)
+		}
+		return true
+	})
+	if swallowed || len(unread) == 0 {
+		return swallowed
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && unread[ident.Name] && !ignored[ident] {
+			delete(unread, ident.Name)
+		}
+		return len(unread) > 0
+	})
+	return len(unread) > 0
+}
+
+// recoveredVar returns the name of the variable assigned by r := recover(),
+// or "" if assign doesn't store the recovered value
+func (f *recoverFinder) recoveredVar(assign *ast.AssignStmt) string {
+	if len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return ""
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || !f.isRecoverCall(call) {
+		return ""
+	}
+	if ident, ok := assign.Lhs[0].(*ast.Ident); ok && ident.Name != "_" {
+		return ident.Name
+	}
+	return ""
+}
+
+// isRecoveredValue checks if expr is a recover() call, possibly asserted to
+// a type as in recover().(error)
+func (f *recoverFinder) isRecoveredValue(expr ast.Expr) bool {
+	expr = ast.Unparen(expr)
+	if assert, ok := expr.(*ast.TypeAssertExpr); ok {
+		expr = ast.Unparen(assert.X)
+	}
+	call, ok := expr.(*ast.CallExpr)
+	return ok && f.isRecoverCall(call)
+}
+
+// isRecoverTypeSwitch checks if a type switch switches on recover(), as in
+// switch r := recover().(type) or switch recover().(type)
+func (f *recoverFinder) isRecoverTypeSwitch(typeSwitch *ast.TypeSwitchStmt) bool {
+	var tag ast.Expr
+	switch assign := typeSwitch.Assign.(type) {
+	case *ast.AssignStmt:
+		if len(assign.Rhs) == 1 {
+			tag = assign.Rhs[0]
+		}
+	case *ast.ExprStmt:
+		tag = assign.X
+	}
+	return tag != nil && f.isRecoveredValue(tag)
+}
+
+// isPanicOf checks if expr is panic(recover()) or panic(<recovered>)
+func (f *recoverFinder) isPanicOf(expr ast.Expr, recovered string) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || !f.isPanicCall(call) {
+		return false
+	}
+
+	switch arg := call.Args[0].(type) {
+	case *ast.CallExpr:
+		return f.isRecoverCall(arg)
+	case *ast.Ident:
+		return recovered != "" && arg.Name == recovered
+	}
+	return false
+}
+
+// isPanicCall checks if call calls the panic builtin
+func (f *recoverFinder) isPanicCall(call *ast.CallExpr) bool {
+	ident, ok := call.Fun.(*ast.Ident)
+	if !ok || ident.Name != "panic" {
+		return false
+	}
+	if f.info != nil {
+		if obj, ok := f.info.Uses[ident]; ok {
+			_, isBuiltin := obj.(*types.Builtin)
+			return isBuiltin
+		}
+	}
+	return true
+}
+
+// reachableStmts returns the statements of a block that can run: those
+// before the first unconditional return, panic, break, continue or goto.
+// A labeled statement after it may be the target of a goto, so statements
+// from there on are kept.
+func (f *recoverFinder) reachableStmts(list []ast.Stmt) []ast.Stmt {
+	reachable := make([]ast.Stmt, 0, len(list))
+	terminated := false
+	for _, stmt := range list {
+		if _, ok := stmt.(*ast.LabeledStmt); ok {
+			terminated = false
+		}
+		if terminated {
+			continue
+		}
+		reachable = append(reachable, stmt)
+
+		switch stmt := stmt.(type) {
+		case *ast.ReturnStmt, *ast.BranchStmt:
+			terminated = true
+		case *ast.ExprStmt:
+			if call, ok := stmt.X.(*ast.CallExpr); ok && f.isPanicCall(call) {
+				terminated = true
+			}
+		}
+	}
+	return reachable
+}
+
+// isNonNilCheck checks if cond is exactly name != nil or nil != name
+func isNonNilCheck(cond ast.Expr, name string) bool {
+	binary, ok := cond.(*ast.BinaryExpr)
+	if !ok || binary.Op != token.NEQ {
+		return false
+	}
+	isName := func(e ast.Expr) bool {
+		ident, ok := e.(*ast.Ident)
+		return ok && ident.Name == name
+	}
+	isNil := func(e ast.Expr) bool {
+		ident, ok := e.(*ast.Ident)
+		return ok && ident.Name == "nil"
+	}
+	return (isName(binary.X) && isNil(binary.Y)) || (isNil(binary.X) && isName(binary.Y))
+}
+
+// isGuardedRecover checks if a deferred function body can return before
+// reaching its recover() call, e.g. if cond { return }; recover(). A return
+// in any statement preceding the first statement that calls recover counts.
+func (f *recoverFinder) isGuardedRecover(body *ast.BlockStmt) bool {
+	for _, stmt := range body.List {
+		if f.callsRecover(&ast.BlockStmt{List: []ast.Stmt{stmt}}) {
+			return false
+		}
+		if containsReturn(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsReturn checks if a statement contains a return statement that
+// belongs to the enclosing function rather than a nested function literal
+func containsReturn(stmt ast.Stmt) bool {
+	found := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isDeferredRecovery checks if a defer statement contains recovery logic
+func (f *recoverFinder) isDeferredRecovery(deferStmt *ast.DeferStmt) bool {
+	if isMalformedCall(deferStmt.Call) || f.isSkippableDeferredRecovery(deferStmt) {
+		return false
+	}
+
+	// Check for direct defer recover()
+	if f.isRecoverCall(deferStmt.Call) {
+		return true
 	}
 
-	// Check for defer someRecoveryFunc()
-	if ident, ok := deferStmt.Call.Fun.(*ast.Ident); ok {
-		return r.isRecoveryFunction(ident.Name)
+	// Check for defer func() { ... recover() ... }(), including a
+	// parenthesized literal as in defer (func() { ... })()
+	if funcLit, ok := astutil.Unparen(deferStmt.Call.Fun).(*ast.FuncLit); ok {
+		return f.containsRecover(funcLit.Body)
 	}
 
-	// Check for defer pkg.RecoveryFunc()
-	if sel, ok := deferStmt.Call.Fun.(*ast.SelectorExpr); ok {
-		return r.isCrossPackageRecoveryFunction(sel)
+	// Check for defer someRecoveryFunc() and defer pkg.RecoveryFunc()
+	if f.resolve != nil {
+		return f.resolve(deferStmt.Call.Fun)
 	}
 
 	return false
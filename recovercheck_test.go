@@ -1,16 +1,31 @@
 package recovercheck_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/cksidharthan/recovercheck"
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/packages"
 )
 
 // Test data for various scenarios
@@ -106,6 +121,17 @@ func TestCollectNodes(t *testing.T) {
 			expectedFuncCount: 1,
 			expectedGoCount:   3,
 		},
+		{
+			name: "nested goroutines",
+			code: `package test
+func Nested() {
+	go func() {
+		go func() {}()
+	}()
+}`,
+			expectedFuncCount: 1,
+			expectedGoCount:   2,
+		},
 	}
 
 	for _, tt := range tests {
@@ -163,6 +189,76 @@ func TestFunc() {
 			funcName: "TestFunc",
 			expected: true,
 		},
+		{
+			name: "function returning a recovering closure",
+			code: `package test
+func TestFunc() func() {
+	return func() {
+		recover()
+	}
+}`,
+			funcName: "TestFunc",
+			expected: false,
+		},
+		{
+			name: "function calling a recovering closure without deferring it",
+			code: `package test
+func TestFunc() {
+	handle := func() {
+		recover()
+	}
+	handle()
+	panic("unsafe")
+}`,
+			funcName: "TestFunc",
+			expected: false,
+		},
+		{
+			name: "pointer receiver method sharing its name",
+			code: `package test
+type svc struct{}
+func (s *svc) recover2() {
+	recover()
+}
+type client struct{}
+func (c client) recover2() {}
+func recover2() {}`,
+			funcName: "(*svc).recover2",
+			expected: true,
+		},
+		{
+			name: "value receiver method sharing its name",
+			code: `package test
+type svc struct{}
+func (s *svc) recover2() {
+	recover()
+}
+type client struct{}
+func (c client) recover2() {}`,
+			funcName: "client.recover2",
+			expected: false,
+		},
+		{
+			name: "function sharing its name with a method",
+			code: `package test
+func recover2() {}
+type svc struct{}
+func (s *svc) recover2() {
+	recover()
+}`,
+			funcName: "recover2",
+			expected: false,
+		},
+		{
+			name: "generic receiver method",
+			code: `package test
+type list[T any] struct{}
+func (l *list[T]) recover2() {
+	recover()
+}`,
+			funcName: "(*list).recover2",
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -187,6 +283,307 @@ func TestFunc() {
 	}
 }
 
+// TestBuildTaggedDeclarations tests that of a function declared in several
+// build-tagged files passed together, only the one in the build is used
+func TestBuildTaggedDeclarations(t *testing.T) {
+	files := []struct {
+		name string
+		code string
+	}{
+		{"main.go", `package test
+func Start() {
+	go func() {
+		defer handlePanic()
+		panic("oh no")
+	}()
+}`},
+		{"handle_default.go", `//go:build !recovercheck_tagged
+
+package test
+
+func handlePanic() {
+	recover()
+}`},
+		// Last, so that it would overwrite the declaration in the build
+		{"handle_tagged.go", `//go:build recovercheck_tagged
+
+package test
+
+func handlePanic() {}`},
+	}
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, f := range files {
+		file, err := parser.ParseFile(fset, f.name, f.code, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, file)
+	}
+
+	insp := inspector.New(parsed)
+	pass := createMockPass(t, fset, insp)
+	pass.Files = parsed
+	var diagnostics []analysis.Diagnostic
+	pass.Report = func(d analysis.Diagnostic) {
+		diagnostics = append(diagnostics, d)
+	}
+
+	collector := recovercheck.CollectNodes(insp)
+	testAnalyzer := &recovercheck.Analyzer{
+		Pass:             pass,
+		RecoverFunctions: make(map[string]bool),
+		GoContexts:       collector.GoContexts,
+	}
+	testAnalyzer.AnalyzeFunctions(collector.FunctionDecls)
+	testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+
+	if !testAnalyzer.RecoverFunctions["handlePanic"] {
+		t.Error("Expected handlePanic to be keyed by its declaration in the build")
+	}
+	for _, d := range diagnostics {
+		t.Errorf("Unexpected diagnostic at %s: %s", fset.Position(d.Pos), d.Message)
+	}
+}
+
+// TestConflictingDeclarations tests that a function declared twice with
+// conflicting recovery, as by build-tagged files that are all passed in, is
+// logged with -debug and classified as not recovering whichever comes last
+func TestConflictingDeclarations(t *testing.T) {
+	for _, order := range [][]int{{0, 1}, {1, 0}} {
+		declarations := []string{`package test
+
+func worker() {
+	defer func() { recover() }()
+}`, `package test
+
+func worker() {}`}
+
+		fset := token.NewFileSet()
+		main, err := parser.ParseFile(fset, "main.go", "package test\nfunc Start() {\n\tgo worker()\n}", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed := []*ast.File{main}
+		for _, i := range order {
+			file, err := parser.ParseFile(fset, fmt.Sprintf("worker%d.go", i), declarations[i], 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed = append(parsed, file)
+		}
+
+		insp := inspector.New(parsed)
+		pass := createMockPass(t, fset, insp)
+		pass.Files = parsed
+		var diagnostics []analysis.Diagnostic
+		pass.Report = func(d analysis.Diagnostic) {
+			diagnostics = append(diagnostics, d)
+		}
+
+		var out bytes.Buffer
+		collector := recovercheck.CollectNodes(insp)
+		testAnalyzer := &recovercheck.Analyzer{
+			Pass:             pass,
+			RecoverFunctions: make(map[string]bool),
+			Settings:         &recovercheck.RecovercheckSettings{Debug: true, DebugOutput: &out},
+			GoContexts:       collector.GoContexts,
+		}
+		testAnalyzer.AnalyzeFunctions(collector.FunctionDecls)
+		testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+
+		if testAnalyzer.RecoverFunctions["worker"] {
+			t.Errorf("Order %v: expected conflicting declarations of worker not to recover", order)
+		}
+		if len(diagnostics) != 1 {
+			t.Errorf("Order %v: expected the goroutine running worker to be reported, got %d diagnostics", order, len(diagnostics))
+		}
+		if !strings.Contains(out.String(), `RecoverFunctions["worker"] declared again with recovers=`) {
+			t.Errorf("Order %v: expected the conflict to be logged, got:\n%s", order, out.String())
+		}
+	}
+}
+
+// TestFuncMethodCollision tests that a function and methods sharing its name
+// are classified separately
+func TestFuncMethodCollision(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "collision")
+}
+
+// TestNestedGoroutineDiagnostics tests that nested goroutines are reported once each
+func TestNestedGoroutineDiagnostics(t *testing.T) {
+	code := `package test
+func NestedGoroutines() {
+	go func() {
+		go func() {
+			go func() {
+				panic("innermost")
+			}()
+		}()
+	}()
+}`
+
+	insp, fset, _ := parseTestCode(t, code)
+	pass := createMockPass(t, fset, insp)
+
+	var diagnostics []analysis.Diagnostic
+	pass.Report = func(d analysis.Diagnostic) {
+		diagnostics = append(diagnostics, d)
+	}
+
+	collector := recovercheck.CollectNodes(insp)
+	testAnalyzer := &recovercheck.Analyzer{
+		Pass:             pass,
+		RecoverFunctions: make(map[string]bool),
+		GoContexts:       collector.GoContexts,
+	}
+	testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+
+	expected := []struct {
+		line    int
+		column  int
+		message string
+	}{
+		{3, 2, "goroutine created without panic recovery"},
+		{4, 3, "nested goroutine created without panic recovery inside an unrecovered goroutine"},
+		{5, 4, "nested goroutine created without panic recovery inside an unrecovered goroutine"},
+	}
+
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d", len(expected), len(diagnostics))
+	}
+
+	for i, want := range expected {
+		pos := fset.Position(diagnostics[i].Pos)
+		if pos.Line != want.line || pos.Column != want.column {
+			t.Errorf("Diagnostic %d: expected position %d:%d, got %d:%d", i, want.line, want.column, pos.Line, pos.Column)
+		}
+		if diagnostics[i].Message != want.message {
+			t.Errorf("Diagnostic %d: expected message %q, got %q", i, want.message, diagnostics[i].Message)
+		}
+	}
+}
+
+// TestHasRecovery tests the stateless recovery detection
+func TestHasRecovery(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		typecheck bool
+		expected  bool
+	}{
+		{
+			name: "deferred recover closure",
+			code: `package test
+func TestFunc() {
+	defer func() {
+		recover()
+	}()
+}`,
+			expected: true,
+		},
+		{
+			name: "no recover",
+			code: `package test
+func TestFunc() {
+	println("no recover")
+}`,
+			expected: false,
+		},
+		{
+			name: "deferred named function is not followed",
+			code: `package test
+func handler() {
+	recover()
+}
+func TestFunc() {
+	defer handler()
+}`,
+			expected: false,
+		},
+		{
+			name: "shadowed recover with type info",
+			code: `package test
+func TestFunc() {
+	recover := func() {}
+	defer func() {
+		recover()
+	}()
+}`,
+			typecheck: true,
+			expected:  false,
+		},
+		{
+			name: "deferred unlock before a deferred recover closure",
+			code: `package test
+type mutex struct{}
+func (m *mutex) Lock()   {}
+func (m *mutex) Unlock() {}
+func TestFunc() {
+	var mu mutex
+	mu.Lock()
+	defer mu.Unlock()
+	defer func() {
+		recover()
+	}()
+}`,
+			expected: true,
+		},
+		{
+			name: "deferred unlock without recover",
+			code: `package test
+type mutex struct{}
+func (m *mutex) Lock()   {}
+func (m *mutex) Unlock() {}
+func TestFunc() {
+	var mu mutex
+	mu.Lock()
+	defer mu.Unlock()
+	panic("x")
+}`,
+			expected: false,
+		},
+		{
+			name: "builtin recover with type info",
+			code: `package test
+func TestFunc() {
+	defer func() {
+		recover()
+	}()
+}`,
+			typecheck: true,
+			expected:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, fset, file := parseTestCode(t, tt.code)
+
+			var info *types.Info
+			if tt.typecheck {
+				info = &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+				conf := types.Config{}
+				if _, err := conf.Check("test", fset, []*ast.File{file}, info); err != nil {
+					t.Fatalf("Failed to type check test code: %v", err)
+				}
+			}
+
+			var body *ast.BlockStmt
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "TestFunc" {
+					body = fn.Body
+				}
+			}
+
+			if got := recovercheck.HasRecovery(body, info); got != tt.expected {
+				t.Errorf("Expected HasRecovery to return %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
 // TestNew tests the analyzer creation
 func TestNew(t *testing.T) {
 	recovercheckSettings := &recovercheck.RecovercheckSettings{}
@@ -205,9 +602,91 @@ func TestNew(t *testing.T) {
 		t.Error("Expected analyzer to have a Run function")
 	}
 
-	if len(analyzer.Requires) != 1 || analyzer.Requires[0] != inspect.Analyzer {
+	if !slices.Contains(analyzer.Requires, inspect.Analyzer) {
 		t.Error("Expected analyzer to require inspect.Analyzer")
 	}
+
+	if len(analyzer.FactTypes) != 0 {
+		t.Error("Expected facts to be exported by a separate analyzer so dependencies aren't reported on")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *recovercheck.RecovercheckSettings
+		expected string
+	}{
+		{
+			name:     "valid settings",
+			settings: &recovercheck.RecovercheckSettings{IncludeFuncRegex: `^example\.com/`, SpawnFuncs: []string{"example.com/pool.Pool.Go:1"}},
+		},
+		{
+			name:     "bad include regex",
+			settings: &recovercheck.RecovercheckSettings{IncludeFuncRegex: `(unclosed`},
+			expected: "invalid include-func-regex: error parsing regexp: missing closing ): `(unclosed`",
+		},
+		{
+			name:     "bad exclude regex",
+			settings: &recovercheck.RecovercheckSettings{ExcludeFuncRegex: `[z-a]`},
+			expected: "invalid exclude-func-regex: error parsing regexp: invalid character class range: `z-a`",
+		},
+		{
+			name:     "malformed spawn function",
+			settings: &recovercheck.RecovercheckSettings{SpawnFuncs: []string{"pool"}},
+			expected: `invalid spawn-func: invalid spawn function "pool": want pkg.Func or pkg.Type.Method`,
+		},
+		{
+			name:     "malformed trusted spawner",
+			settings: &recovercheck.RecovercheckSettings{TrustedSpawners: []string{"example.com/safego.Run:x"}},
+			expected: `invalid trusted-spawner: invalid spawn function "example.com/safego.Run:x": argument index must be a non-negative integer`,
+		},
+		{
+			name:     "safe function with an argument index",
+			settings: &recovercheck.RecovercheckSettings{SafeFuncs: []string{"example.com/safelib.Run:0"}},
+			expected: `invalid safe-func: invalid safe function "example.com/safelib.Run:0": want pkg.Func or pkg.Type.Method`,
+		},
+		{
+			name:     "negative max findings",
+			settings: &recovercheck.RecovercheckSettings{MaxFindings: -5},
+			expected: "invalid max-findings -5: must not be negative",
+		},
+		{
+			name:     "unknown report position",
+			settings: &recovercheck.RecovercheckSettings{ReportAt: "brace"},
+			expected: `invalid report-at "brace": want go or body`,
+		},
+		{
+			name:     "unknown package kind",
+			settings: &recovercheck.RecovercheckSettings{PackageKind: "plugin"},
+			expected: `invalid package-kind "plugin": want auto, library or main`,
+		},
+		{
+			name:     "negative workers",
+			settings: &recovercheck.RecovercheckSettings{Workers: -1},
+			expected: "invalid workers -1: must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.Validate()
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Expected valid settings, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+
+			// The analyzer fails its first pass without analyzing anything
+			if _, err := recovercheck.New(tt.settings).Run(&analysis.Pass{}); err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected Run to fail with %q, got %v", tt.expected, err)
+			}
+		})
+	}
 }
 
 // TestEdgeCases tests various edge cases and error conditions
@@ -263,32 +742,2252 @@ func NestedGoroutines() {
 	}
 }
 
-// Benchmark tests for performance
-func BenchmarkCollectNodes(b *testing.B) {
-	insp, _, _ := parseTestCode(b, testCodeMixed)
+// TestForwardReferences tests that functions are classified before any
+// goroutine, so one running or deferring a function declared further down,
+// even without type information, is classified by that function
+func TestForwardReferences(t *testing.T) {
+	code := `package test
 
-	for b.Loop() {
-		recovercheck.CollectNodes(insp)
-	}
+func Spawn() {
+	go laterRecovering()
+	go func() {
+		defer laterHandler()
+		panic("safe")
+	}()
+	go laterPlain()
 }
 
-func BenchmarkAnalyzeGoroutines(b *testing.B) {
-	insp, fset, _ := parseTestCode(b, testCodeMixed)
-	pass := createMockPass(b, fset, insp)
+func laterRecovering() {
+	defer func() { recover() }()
+	panic("safe")
+}
+
+func laterHandler() {
+	recover()
+}
+
+func laterPlain() {
+	panic("unsafe")
+}`
+	insp, fset, _ := parseTestCode(t, code)
+	var diagnostics []analysis.Diagnostic
+	pass := createMockPass(t, fset, insp)
+	pass.Report = func(d analysis.Diagnostic) {
+		diagnostics = append(diagnostics, d)
+	}
 
 	testAnalyzer := &recovercheck.Analyzer{
 		Pass:             pass,
 		RecoverFunctions: make(map[string]bool),
 	}
+	collector := recovercheck.CollectNodes(insp)
+	testAnalyzer.AnalyzeFunctions(collector.FunctionDecls)
+	testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+
+	if len(diagnostics) != 1 || fset.Position(diagnostics[0].Pos).Line != 9 {
+		t.Errorf("Expected only go laterPlain() on line 9 to be reported, got %v", diagnostics)
+	}
+}
+
+// TestGoStatementWithoutCall documents that go statements without a call
+// never reach the analyzer from the parser, and that hand-built ones are
+// skipped rather than reported
+func TestGoStatementWithoutCall(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", "package test\n\nfunc f() {\n\tgo f\n}\n", parser.AllErrors)
+	if err == nil || !strings.Contains(err.Error(), "expression in go must be function call") {
+		t.Fatalf("Expected a syntax error for go f, got %v", err)
+	}
 
+	insp := inspector.New([]*ast.File{file})
 	collector := recovercheck.CollectNodes(insp)
+	if len(collector.GoStatements) != 0 {
+		t.Errorf("Expected go f to be parsed as a bad statement, got %d go statements", len(collector.GoStatements))
+	}
 
-	for b.Loop() {
-		testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+	var diagnostics []analysis.Diagnostic
+	pass := createMockPass(t, fset, insp)
+	pass.Report = func(d analysis.Diagnostic) {
+		diagnostics = append(diagnostics, d)
+	}
+	testAnalyzer := &recovercheck.Analyzer{
+		Pass:             pass,
+		RecoverFunctions: make(map[string]bool),
+	}
+	testAnalyzer.AnalyzeGoroutines([]*ast.GoStmt{{Go: file.Package}})
+	if len(diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics for a go statement without a call, got %v", diagnostics)
 	}
 }
 
-func TestAll(t *testing.T) {
-	recovercheckSettings := &recovercheck.RecovercheckSettings{}
-	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "recovercheck")
+// TestGoroutineCounts tests that the result counts the goroutines analyzed
+// and those that are safe
+func TestGoroutineCounts(t *testing.T) {
+	insp, fset, _ := parseTestCode(t, testCodeMixed)
+	pass := createMockPass(t, fset, insp)
+
+	testAnalyzer := &recovercheck.Analyzer{
+		Pass:             pass,
+		RecoverFunctions: map[string]bool{"safeFunc": true},
+	}
+	collector := recovercheck.CollectNodes(insp)
+	testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+
+	result := testAnalyzer.Result()
+	if result.TotalGoroutines != 3 || result.SafeGoroutines != 2 {
+		t.Errorf("Expected 2 of 3 goroutines to be safe, got %d of %d", result.SafeGoroutines, result.TotalGoroutines)
+	}
+	if len(result.Unsafe) != result.TotalGoroutines-result.SafeGoroutines {
+		t.Errorf("Expected %d unsafe goroutines, got %d", result.TotalGoroutines-result.SafeGoroutines, len(result.Unsafe))
+	}
+}
+
+// TestMalformedGoStatements tests that go statements of partial syntax
+// trees, with nodes the parser would always set left nil, are skipped
+// instead of panicking, whatever the settings
+func TestMalformedGoStatements(t *testing.T) {
+	literal := func(stmts ...ast.Stmt) *ast.FuncLit {
+		return &ast.FuncLit{Type: &ast.FuncType{}, Body: &ast.BlockStmt{List: stmts}}
+	}
+	tests := []struct {
+		name   string
+		goStmt *ast.GoStmt
+	}{
+		{name: "nil call", goStmt: &ast.GoStmt{}},
+		{name: "nil function", goStmt: &ast.GoStmt{Call: &ast.CallExpr{}}},
+		{name: "parenthesized nil function", goStmt: &ast.GoStmt{Call: &ast.CallExpr{Fun: &ast.ParenExpr{}}}},
+		{name: "selector without operand", goStmt: &ast.GoStmt{Call: &ast.CallExpr{Fun: &ast.SelectorExpr{Sel: ast.NewIdent("Run")}}}},
+		{name: "literal without body", goStmt: &ast.GoStmt{Call: &ast.CallExpr{Fun: &ast.FuncLit{Type: &ast.FuncType{}}}}},
+		{name: "defer without call", goStmt: &ast.GoStmt{Call: &ast.CallExpr{Fun: literal(&ast.DeferStmt{})}}},
+		{name: "defer of nil function", goStmt: &ast.GoStmt{Call: &ast.CallExpr{Fun: literal(&ast.DeferStmt{Call: &ast.CallExpr{}})}}},
+		{name: "call of nil function", goStmt: &ast.GoStmt{Call: &ast.CallExpr{Fun: literal(&ast.ExprStmt{X: &ast.CallExpr{}})}}},
+	}
+
+	allSettings := &recovercheck.RecovercheckSettings{
+		DetectRepanic:               true,
+		FlagGuardedRecover:          true,
+		RequireHandledRecover:       true,
+		RequireUnconditionalRecover: true,
+		FlagMustCalls:               true,
+		DeepAnalysis:                true,
+		SkipSelectLoops:             true,
+		SkipTrivialBodies:           true,
+		WarnSwallowedRecover:        true,
+		ReportAt:                    "body",
+	}
+	for _, tt := range tests {
+		for _, settings := range []*recovercheck.RecovercheckSettings{nil, allSettings} {
+			t.Run(tt.name, func(t *testing.T) {
+				insp, fset, _ := parseTestCode(t, "package test\n")
+				var diagnostics []analysis.Diagnostic
+				pass := createMockPass(t, fset, insp)
+				pass.Report = func(d analysis.Diagnostic) {
+					diagnostics = append(diagnostics, d)
+				}
+				testAnalyzer := &recovercheck.Analyzer{
+					Pass:             pass,
+					RecoverFunctions: make(map[string]bool),
+					Settings:         settings,
+				}
+				testAnalyzer.AnalyzeGoroutines([]*ast.GoStmt{tt.goStmt})
+				if len(diagnostics) != 0 {
+					t.Errorf("Expected no diagnostics for a malformed go statement, got %v", diagnostics)
+				}
+			})
+		}
+	}
+
+	// Function declarations with malformed defers are classified as not
+	// recovering
+	body := &ast.BlockStmt{List: []ast.Stmt{&ast.DeferStmt{}, &ast.DeferStmt{Call: &ast.CallExpr{}}, &ast.ExprStmt{X: &ast.CallExpr{}}}}
+	if recovercheck.HasRecovery(body, nil) {
+		t.Error("Expected a body with only malformed calls to have no recovery")
+	}
+}
+
+// Benchmark tests for performance
+func BenchmarkCollectNodes(b *testing.B) {
+	insp, _, _ := parseTestCode(b, testCodeMixed)
+
+	for b.Loop() {
+		recovercheck.CollectNodes(insp)
+	}
+}
+
+func BenchmarkAnalyzeGoroutines(b *testing.B) {
+	insp, fset, _ := parseTestCode(b, testCodeMixed)
+	pass := createMockPass(b, fset, insp)
+
+	testAnalyzer := &recovercheck.Analyzer{
+		Pass:             pass,
+		RecoverFunctions: make(map[string]bool),
+	}
+
+	collector := recovercheck.CollectNodes(insp)
+
+	for b.Loop() {
+		testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+	}
+}
+
+// testCodeResolution mixes the goroutine shapes whose classification depends
+// on how far recovery is resolved: named functions, function variables,
+// deferred helpers and deferred handler factories
+const testCodeResolution = `package test
+
+func handlePanic() {
+	if r := recover(); r != nil {
+		println(r)
+	}
+}
+
+func handler() func() {
+	return func() { recover() }
+}
+
+func deferredHelper() {
+	defer handlePanic()
+	panic("safe")
+}
+
+func deferredFactory() {
+	defer handler()()
+	panic("unsafe")
+}
+
+func direct() {
+	defer func() { recover() }()
+	panic("safe")
+}
+
+func unsafe() {
+	panic("unsafe")
+}
+
+func Spawn() {
+	go deferredHelper()
+	go deferredFactory()
+	go direct()
+	go unsafe()
+
+	fn := direct
+	go fn()
+
+	go func() {
+		defer handlePanic()
+		panic("safe")
+	}()
+}`
+
+// benchmarkResolution runs function and goroutine analysis over
+// testCodeResolution with the given settings. Without type information the
+// analyzer falls back to looking recovery functions up by name; with it,
+// function variables and deferred calls are resolved to their declarations.
+func benchmarkResolution(b *testing.B, typed bool, settings recovercheck.RecovercheckSettings) {
+	insp, fset, file := parseTestCode(b, testCodeResolution)
+	pass := createMockPass(b, fset, insp)
+	pass.Files = []*ast.File{file}
+
+	if typed {
+		info := &types.Info{
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		}
+		pkg, err := (&types.Config{}).Check("test", fset, pass.Files, info)
+		if err != nil {
+			b.Fatalf("Failed to type-check test code: %v", err)
+		}
+		pass.Pkg = pkg
+		pass.TypesInfo = info
+	}
+
+	collector := recovercheck.CollectNodes(insp)
+
+	for b.Loop() {
+		testAnalyzer := &recovercheck.Analyzer{
+			Pass:             pass,
+			RecoverFunctions: make(map[string]bool),
+			Settings:         &settings,
+			GoContexts:       collector.GoContexts,
+		}
+		testAnalyzer.AnalyzeFunctions(collector.FunctionDecls)
+		testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+	}
+}
+
+// The resolution benchmarks classify the goroutines of testCodeResolution
+// with each way of resolving recovery. The relative costs measured on this
+// fixture, taking BenchmarkResolutionTyped as 1x, are about:
+//
+//	BenchmarkResolutionByName  4x    naming heuristic only
+//	BenchmarkResolutionTyped   1x    direct and deferred calls resolved
+//	BenchmarkResolutionDeep    1x    full call-graph resolution
+//	BenchmarkResolutionFacts   9x    facts, through the analysis driver
+//
+// The naming heuristic is the slowest in-process variant: without type
+// information, every go statement naming a function is first searched for
+// as a local function variable, scanning the enclosing scopes for
+// assignments. Deep analysis only follows the calls of goroutines that
+// don't recover, one here. The facts variant includes the driver's own
+// work, such as running the inspect analyzer and exporting a fact for every
+// function of the dependency, which dominates on a fixture this small;
+// facts pay off once a dependency is imported by many packages, since its
+// syntax is then analyzed once instead of being parsed from disk by every
+// importer.
+
+// BenchmarkResolutionByName measures classification using only the
+// per-package table of recovering function names
+func BenchmarkResolutionByName(b *testing.B) {
+	benchmarkResolution(b, false, recovercheck.RecovercheckSettings{})
+}
+
+// BenchmarkResolutionTyped measures classification with type information,
+// which adds declaration lookups for function variables and deferred calls.
+// Each lookup is a map access followed by a search of the package's files,
+// so the overhead stays linear in the package size.
+func BenchmarkResolutionTyped(b *testing.B) {
+	benchmarkResolution(b, true, recovercheck.RecovercheckSettings{})
+}
+
+// BenchmarkResolutionDeep measures classification with DeepAnalysis, which
+// also follows the static calls of goroutines that don't recover, up to
+// five calls deep. Only unsafe goroutines pay for it.
+func BenchmarkResolutionDeep(b *testing.B) {
+	benchmarkResolution(b, true, recovercheck.RecovercheckSettings{DeepAnalysis: true})
+}
+
+// BenchmarkResolutionFacts measures classification through the facts of a
+// dependency: the declarations of testCodeResolution move to package lib,
+// exported, and package app starts the same goroutines through them, so
+// that every call resolves to a fact exported when lib was analyzed.
+func BenchmarkResolutionFacts(b *testing.B) {
+	exported := regexp.MustCompile(`\b(handlePanic|handler|deferredHelper|deferredFactory|direct|unsafe)\b`)
+	lib := strings.Replace(testCodeResolution, "package test", "package lib", 1)
+	lib = exported.ReplaceAllStringFunc(lib, func(name string) string {
+		return strings.ToUpper(name[:1]) + name[1:]
+	})
+
+	dir := b.TempDir()
+	for path, content := range map[string]string{
+		"go.mod":     "module example.com/resolution\n\ngo 1.24\n",
+		"lib/lib.go": lib,
+		"app/app.go": `package app
+
+import "example.com/resolution/lib"
+
+func Spawn() {
+	go lib.DeferredHelper()
+	go lib.DeferredFactory()
+	go lib.Direct()
+	go lib.Unsafe()
+
+	fn := lib.Direct
+	go fn()
+
+	go func() {
+		defer lib.HandlePanic()
+		panic("safe")
+	}()
+}
+`,
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax, Dir: dir}, "./app")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		b.Fatal("Failed to load packages")
+	}
+
+	for b.Loop() {
+		analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{})
+		graph, err := checker.Analyze([]*analysis.Analyzer{analyzer}, pkgs, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		// As without facts, only lib.Unsafe is reported
+		if diagnostics := len(graph.Roots[0].Diagnostics); diagnostics != 1 {
+			b.Fatalf("expected 1 diagnostic, got %d", diagnostics)
+		}
+	}
+}
+
+// TestSyntheticPositions tests that functions of other packages declared at
+// positions with no source file, such as those of cgo-generated code, fall
+// back to the external default instead of being parsed
+func TestSyntheticPositions(t *testing.T) {
+	fset := token.NewFileSet()
+
+	// Declared in a file without a name
+	unnamed, err := parser.ParseFile(fset, "", "package unnamed\n\nfunc Run() {}\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unnamedPkg, err := (&types.Config{}).Check("example.com/unnamed", fset, []*ast.File{unnamed}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Declared without any position
+	synthetic := types.NewPackage("example.com/synthetic", "synthetic")
+	synthetic.Scope().Insert(types.NewFunc(token.NoPos, synthetic, "Run", types.NewSignatureType(nil, nil, nil, nil, nil, false)))
+	synthetic.MarkComplete()
+
+	code := `package test
+
+import (
+	"example.com/synthetic"
+	"example.com/unnamed"
+)
+
+func Start() {
+	go unnamed.Run()
+	go synthetic.Run()
+}
+`
+	file, err := parser.ParseFile(fset, "test.go", code, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	config := &types.Config{Importer: fakeImporter{"example.com/unnamed": unnamedPkg, "example.com/synthetic": synthetic}}
+	pkg, err := config.Check("test", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		assumeExternalSafe bool
+		expected           int
+	}{
+		{name: "unsafe by default", expected: 2},
+		{name: "assume external safe", assumeExternalSafe: true, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insp := inspector.New([]*ast.File{file})
+			pass := createMockPass(t, fset, insp)
+			pass.Files = []*ast.File{file}
+			pass.Pkg = pkg
+			pass.TypesInfo = info
+			var diagnostics []analysis.Diagnostic
+			pass.Report = func(d analysis.Diagnostic) {
+				diagnostics = append(diagnostics, d)
+			}
+
+			var debug bytes.Buffer
+			collector := recovercheck.CollectNodes(insp)
+			testAnalyzer := &recovercheck.Analyzer{
+				Pass:             pass,
+				RecoverFunctions: make(map[string]bool),
+				Settings: &recovercheck.RecovercheckSettings{
+					AssumeExternalSafe: tt.assumeExternalSafe,
+					Debug:              true,
+					DebugOutput:        &debug,
+				},
+				GoContexts: collector.GoContexts,
+			}
+			testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+
+			if len(diagnostics) != tt.expected {
+				t.Errorf("Expected %d diagnostics, got %d", tt.expected, len(diagnostics))
+			}
+			// The unnamed file is never handed to the parser
+			if !strings.Contains(debug.String(), "has no source file") {
+				t.Errorf("Expected the unnamed file to be skipped, got debug output:\n%s", debug.String())
+			}
+		})
+	}
+}
+
+// fakeImporter resolves imports to already type-checked packages
+type fakeImporter map[string]*types.Package
+
+func (f fakeImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := f[path]; ok {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %q not found", path)
+}
+
+// BenchmarkCrossPackageAnalysis starts goroutines with many distinct
+// functions declared in a single file of another package. That file is read
+// from disk, and parsing it dominates the cost unless it is parsed only once.
+func BenchmarkCrossPackageAnalysis(b *testing.B) {
+	const funcs = 50
+
+	var pkgSrc, mainSrc strings.Builder
+	pkgSrc.WriteString("package workers\n")
+	mainSrc.WriteString("package test\n\nimport \"example.com/workers\"\n\nfunc Spawn() {\n")
+	for i := range funcs {
+		fmt.Fprintf(&pkgSrc, "\nfunc Worker%d() {\n\tdefer func() { recover() }()\n\tpanic(%d)\n}\n", i, i)
+		fmt.Fprintf(&mainSrc, "\tgo workers.Worker%d()\n", i)
+	}
+	mainSrc.WriteString("}\n")
+
+	pkgFile := filepath.Join(b.TempDir(), "workers.go")
+	if err := os.WriteFile(pkgFile, []byte(pkgSrc.String()), 0o644); err != nil {
+		b.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	workersFile, err := parser.ParseFile(fset, pkgFile, nil, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	workers, err := (&types.Config{}).Check("example.com/workers", fset, []*ast.File{workersFile}, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	file, err := parser.ParseFile(fset, "test.go", mainSrc.String(), 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	config := &types.Config{Importer: fakeImporter{"example.com/workers": workers}}
+	pkg, err := config.Check("test", fset, []*ast.File{file}, info)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	insp := inspector.New([]*ast.File{file})
+	pass := createMockPass(b, fset, insp)
+	pass.Files = []*ast.File{file}
+	pass.Pkg = pkg
+	pass.TypesInfo = info
+
+	collector := recovercheck.CollectNodes(insp)
+
+	for b.Loop() {
+		testAnalyzer := &recovercheck.Analyzer{
+			Pass:             pass,
+			RecoverFunctions: make(map[string]bool),
+		}
+		testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+	}
+}
+
+// TestResultsCache tests that a run with CacheDir reuses the results cached
+// by an earlier run over unchanged files of other packages instead of
+// parsing them, and parses a file again once it changes
+// analyzeWithCache writes the files of the example.com/workers package to
+// dir and runs the analyzer over a package with source mainSrc starting its
+// functions, caching results in cacheDir. It returns the number of
+// diagnostics and of files parsed from disk.
+func analyzeWithCache(t *testing.T, dir, cacheDir string, workersFiles map[string]string, mainSrc string, settings recovercheck.RecovercheckSettings) (diagnostics, parsed int) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range slices.Sorted(maps.Keys(workersFiles)) {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(workersFiles[name]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := parser.ParseFile(fset, filename, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	workers, err := (&types.Config{}).Check("example.com/workers", fset, files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := parser.ParseFile(fset, "test.go", mainSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+	config := &types.Config{Importer: fakeImporter{"example.com/workers": workers}}
+	pkg, err := config.Check("test", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insp := inspector.New([]*ast.File{file})
+	pass := createMockPass(t, fset, insp)
+	pass.Files = []*ast.File{file}
+	pass.Pkg = pkg
+	pass.TypesInfo = info
+	pass.Report = func(analysis.Diagnostic) { diagnostics++ }
+
+	var debug bytes.Buffer
+	collector := recovercheck.CollectNodes(insp)
+	testAnalyzer := &recovercheck.Analyzer{
+		Pass:             pass,
+		RecoverFunctions: make(map[string]bool),
+		Settings:         &settings,
+		GoContexts:       collector.GoContexts,
+	}
+	settings.CacheDir, settings.Debug, settings.DebugOutput = cacheDir, true, &debug
+	testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+	return diagnostics, strings.Count(debug.String(), "debug: parsing ")
+}
+
+func TestResultsCache(t *testing.T) {
+	cacheDir, dir := t.TempDir(), t.TempDir()
+	mainSrc := "package test\n\nimport \"example.com/workers\"\n\nfunc Spawn() {\n\tgo workers.Safe()\n\tgo workers.Unsafe()\n}\n"
+	analyze := func(pkgSrc string, settings recovercheck.RecovercheckSettings) (diagnostics, parsed int) {
+		t.Helper()
+		return analyzeWithCache(t, dir, cacheDir, map[string]string{"workers.go": pkgSrc}, mainSrc, settings)
+	}
+
+	pkgSrc := "package workers\n\nfunc Safe() {\n\tdefer func() { recover() }()\n\tpanic(1)\n}\n\nfunc Unsafe() {\n\tpanic(2)\n}\n"
+	if diagnostics, parsed := analyze(pkgSrc, recovercheck.RecovercheckSettings{}); diagnostics != 1 || parsed != 1 {
+		t.Errorf("First run: expected 1 diagnostic and 1 file parsed, got %d and %d", diagnostics, parsed)
+	}
+	if diagnostics, parsed := analyze(pkgSrc, recovercheck.RecovercheckSettings{}); diagnostics != 1 || parsed != 0 {
+		t.Errorf("Run with unchanged inputs: expected 1 diagnostic and no file parsed, got %d and %d", diagnostics, parsed)
+	}
+
+	// Every setting but those only configuring output or how the analysis
+	// runs is part of the key
+	if diagnostics, parsed := analyze(pkgSrc, recovercheck.RecovercheckSettings{AssumeExternalSafe: true}); diagnostics != 1 || parsed != 1 {
+		t.Errorf("Run with other settings: expected 1 diagnostic and 1 file parsed, got %d and %d", diagnostics, parsed)
+	}
+	if diagnostics, parsed := analyze(pkgSrc, recovercheck.RecovercheckSettings{Workers: 2}); diagnostics != 1 || parsed != 0 {
+		t.Errorf("Run with other workers: expected 1 diagnostic and no file parsed, got %d and %d", diagnostics, parsed)
+	}
+
+	// Safe no longer recovers
+	changed := strings.Replace(pkgSrc, "defer func() { recover() }()", "defer func() {}()", 1)
+	if diagnostics, parsed := analyze(changed, recovercheck.RecovercheckSettings{}); diagnostics != 2 || parsed != 1 {
+		t.Errorf("Run after a change: expected 2 diagnostics and 1 file parsed, got %d and %d", diagnostics, parsed)
+	}
+}
+
+func TestResultsCacheDeferredHelper(t *testing.T) {
+	cacheDir, dir := t.TempDir(), t.TempDir()
+	mainSrc := "package test\n\nimport \"example.com/workers\"\n\nfunc Spawn() {\n\tgo workers.Run()\n}\n"
+	workersSrc := "package workers\n\nfunc Run() {\n\tdefer handle()\n\tpanic(1)\n}\n"
+	analyze := func(helperSrc string) int {
+		t.Helper()
+		files := map[string]string{"workers.go": workersSrc, "handle.go": helperSrc}
+		diagnostics, _ := analyzeWithCache(t, dir, cacheDir, files, mainSrc, recovercheck.RecovercheckSettings{})
+		return diagnostics
+	}
+
+	// Run defers a helper of its own package declared in another file,
+	// which the analyzing package knows nothing about
+	helperSrc := "package workers\n\nfunc handle() {\n\trecover()\n}\n"
+	if diagnostics := analyze(helperSrc); diagnostics != 0 {
+		t.Errorf("First run: expected no diagnostics, got %d", diagnostics)
+	}
+	if diagnostics := analyze(helperSrc); diagnostics != 0 {
+		t.Errorf("Run with unchanged inputs: expected no diagnostics, got %d", diagnostics)
+	}
+
+	// Run's file is unchanged, but its helper no longer recovers
+	changed := strings.Replace(helperSrc, "recover()", "println()", 1)
+	if diagnostics := analyze(changed); diagnostics != 1 {
+		t.Errorf("Run after changing the helper: expected 1 diagnostic, got %d", diagnostics)
+	}
+}
+
+// largePackagePass type-checks a synthetic package with funcs functions
+// starting goroutines of every shape: recovering and unsafe local functions,
+// functions of another package read from disk, nested goroutines and
+// deferred helpers. Diagnostics reported to the pass are appended to
+// diagnostics.
+func largePackagePass(tb testing.TB, funcs int, diagnostics *[]analysis.Diagnostic) (*analysis.Pass, *recovercheck.NodeCollector) {
+	tb.Helper()
+
+	var pkgSrc, mainSrc strings.Builder
+	pkgSrc.WriteString("package workers\n")
+	mainSrc.WriteString("package test\n\nimport \"example.com/workers\"\n\nfunc handle() {\n\trecover()\n}\n")
+	for i := range funcs {
+		fmt.Fprintf(&pkgSrc, "\nfunc Worker%d() {\n\tdefer func() { recover() }()\n\tpanic(%d)\n}\n", i, i)
+		fmt.Fprintf(&pkgSrc, "\nfunc Unsafe%d() {\n\tpanic(%d)\n}\n", i, i)
+		fmt.Fprintf(&mainSrc, `
+func safe%[1]d() {
+	defer func() { recover() }()
+	panic(%[1]d)
+}
+
+func unsafe%[1]d() {
+	panic(%[1]d)
+}
+
+func Spawn%[1]d() {
+	go safe%[1]d()
+	go unsafe%[1]d()
+	go workers.Worker%[1]d()
+	go workers.Unsafe%[1]d()
+	go func() {
+		go unsafe%[1]d()
+	}()
+	go func() {
+		defer handle()
+		panic(%[1]d)
+	}()
+}
+`, i)
+	}
+
+	pkgFile := filepath.Join(tb.TempDir(), "workers.go")
+	if err := os.WriteFile(pkgFile, []byte(pkgSrc.String()), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	workersFile, err := parser.ParseFile(fset, pkgFile, nil, 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	workers, err := (&types.Config{}).Check("example.com/workers", fset, []*ast.File{workersFile}, nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	file, err := parser.ParseFile(fset, "test.go", mainSrc.String(), 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	info := &types.Info{
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+	}
+	config := &types.Config{Importer: fakeImporter{"example.com/workers": workers}}
+	pkg, err := config.Check("test", fset, []*ast.File{file}, info)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	insp := inspector.New([]*ast.File{file})
+	pass := createMockPass(tb, fset, insp)
+	pass.Files = []*ast.File{file}
+	pass.Pkg = pkg
+	pass.TypesInfo = info
+	pass.Report = func(d analysis.Diagnostic) {
+		*diagnostics = append(*diagnostics, d)
+	}
+	return pass, recovercheck.CollectNodes(insp)
+}
+
+// analyzeLargePackage runs function and goroutine analysis over pass with
+// the given number of workers
+func analyzeLargePackage(pass *analysis.Pass, collector *recovercheck.NodeCollector, workers int) {
+	testAnalyzer := &recovercheck.Analyzer{
+		Pass:             pass,
+		RecoverFunctions: make(map[string]bool),
+		Settings:         &recovercheck.RecovercheckSettings{Workers: workers},
+		GoContexts:       collector.GoContexts,
+	}
+	testAnalyzer.AnalyzeFunctions(collector.FunctionDecls)
+	testAnalyzer.AnalyzeGoroutines(collector.GoStatements)
+}
+
+// TestConcurrentClassificationMatchesSerial checks that classifying
+// goroutines concurrently reports the same diagnostics, in the same order,
+// as classifying them one at a time
+func TestConcurrentClassificationMatchesSerial(t *testing.T) {
+	var serial, concurrent []analysis.Diagnostic
+	pass, collector := largePackagePass(t, 50, &serial)
+	analyzeLargePackage(pass, collector, 1)
+	pass.Report = func(d analysis.Diagnostic) {
+		concurrent = append(concurrent, d)
+	}
+	analyzeLargePackage(pass, collector, 8)
+
+	// Per function: unsafe0, workers.Unsafe0, the enclosing literal and
+	// the goroutine nested in it
+	if len(serial) != 50*4 {
+		t.Fatalf("Expected %d diagnostics, got %d", 50*4, len(serial))
+	}
+	if len(concurrent) != len(serial) {
+		t.Fatalf("Expected %d diagnostics classifying concurrently, got %d", len(serial), len(concurrent))
+	}
+	for i := range serial {
+		if serial[i].Pos != concurrent[i].Pos || serial[i].Message != concurrent[i].Message {
+			t.Errorf("Diagnostic %d: serial %s %q, concurrent %s %q", i,
+				pass.Fset.Position(serial[i].Pos), serial[i].Message,
+				pass.Fset.Position(concurrent[i].Pos), concurrent[i].Message)
+		}
+	}
+}
+
+// BenchmarkLargePackageSerial classifies the goroutines of a large package
+// one at a time
+func BenchmarkLargePackageSerial(b *testing.B) {
+	benchmarkLargePackage(b, 1)
+}
+
+// BenchmarkLargePackageConcurrent classifies the goroutines of a large
+// package on GOMAXPROCS workers
+func BenchmarkLargePackageConcurrent(b *testing.B) {
+	benchmarkLargePackage(b, 0)
+}
+
+func benchmarkLargePackage(b *testing.B, workers int) {
+	var diagnostics []analysis.Diagnostic
+	pass, collector := largePackagePass(b, 500, &diagnostics)
+
+	for b.Loop() {
+		diagnostics = diagnostics[:0]
+		analyzeLargePackage(pass, collector, workers)
+	}
+}
+
+func TestAll(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "recovercheck")
+}
+
+func TestFlagDetachedInMain(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{FlagDetachedInMain: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "detachedmain")
+}
+
+func TestAssumeInterfaceMethodsSafe(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{AssumeInterfaceMethodsSafe: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "interfacesafe")
+}
+
+func TestJSONSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	recovercheckSettings := &recovercheck.RecovercheckSettings{
+		Summary: &recovercheck.SummaryCollector{Path: path},
+	}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "summary")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read JSON summary: %v", err)
+	}
+
+	var findings []recovercheck.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		t.Fatalf("Failed to parse JSON summary: %v", err)
+	}
+
+	expected := []recovercheck.Finding{
+		{File: "summary.go", Line: 6, Column: 2, Kind: "goroutine", Message: "goroutine created without panic recovery"},
+		{File: "summary.go", Line: 13, Column: 2, Kind: "errgroup", Message: "errgroup goroutine created without panic recovery"},
+	}
+
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %d: %s", len(expected), len(findings), data)
+	}
+
+	for i, want := range expected {
+		got := findings[i]
+		got.File = filepath.Base(got.File)
+		if got != want {
+			t.Errorf("Finding %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
+
+func TestHTTPHandlerSeverity(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{HTTPHandlerSeverity: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "httphandler")
+}
+
+func TestPackageLevelFuncVar(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{}
+	results := analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "pkgvar")
+
+	var related []string
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			for _, info := range diagnostic.Related {
+				related = append(related, info.Message)
+			}
+		}
+	}
+
+	expected := []string{"in function value Handler", "in function value second"}
+	if len(related) != len(expected) {
+		t.Fatalf("Expected related information %v, got %v", expected, related)
+	}
+	for i := range expected {
+		if related[i] != expected[i] {
+			t.Errorf("Expected related information %q, got %q", expected[i], related[i])
+		}
+	}
+}
+
+func TestEnclosingFuncRelated(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{}
+	results := analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "related")
+
+	var related []string
+	for _, result := range results {
+		fset := result.Action.Package.Fset
+		for _, diagnostic := range result.Diagnostics {
+			for _, info := range diagnostic.Related {
+				related = append(related, fmt.Sprintf("%d -> %d: %s", fset.Position(diagnostic.Pos).Line, fset.Position(info.Pos).Line, info.Message))
+			}
+		}
+	}
+
+	expected := []string{
+		"5 -> 4: in function Start",
+		"12 -> 11: in function (*server).Serve",
+		"13 -> 11: in function (*server).Serve",
+		"20 -> 19: in function worker.Run",
+		"25 -> 24: in function value handler",
+	}
+	if !slices.Equal(related, expected) {
+		t.Errorf("Expected related information %q, got %q", expected, related)
+	}
+}
+
+func TestBuildTaggedFiles(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "buildtags")
+}
+
+func TestRecoverResult(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "related")
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	fset := results[0].Action.Package.Fset
+	result, ok := results[0].Result.(*recovercheck.RecoverResult)
+	if !ok {
+		t.Fatalf("Expected a *RecoverResult, got %T", results[0].Result)
+	}
+
+	var unsafe []string
+	for _, goroutine := range result.Unsafe {
+		unsafe = append(unsafe, fmt.Sprintf("%d %s: %s", fset.Position(goroutine.Pos).Line, goroutine.Kind, goroutine.Message))
+	}
+	expected := []string{
+		"5 goroutine: goroutine created without panic recovery",
+		"12 goroutine: goroutine created without panic recovery",
+		"13 goroutine: nested goroutine created without panic recovery inside an unrecovered goroutine",
+		"20 goroutine: goroutine created without panic recovery",
+		"25 goroutine: goroutine created without panic recovery",
+	}
+	if !slices.Equal(unsafe, expected) {
+		t.Errorf("Expected unsafe goroutines %q, got %q", expected, unsafe)
+	}
+
+	if hasRecover, exists := result.RecoverFunctions["work"]; !exists || hasRecover {
+		t.Errorf("Expected work to be classified as not recovering, got %v (exists=%v)", hasRecover, exists)
+	}
+}
+
+func TestConcTrusted(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "conc")
+}
+
+func TestAliasedImports(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "aliasimport")
+}
+
+func TestFuncFields(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "funcfield")
+}
+
+func TestDotImports(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "dotimport")
+}
+
+func TestRecoveryFacts(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "facts")
+}
+
+func TestCrossModuleRecovery(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{}
+	dir := filepath.Join(analysistest.TestData(), "crossmodule")
+	analysistest.Run(t, dir, recovercheck.New(recovercheckSettings), "example.com/app/app")
+}
+
+// fakeBlame reports fixed change times for lines of the since fixture
+type fakeBlame map[int]time.Time
+
+func (f fakeBlame) LineTime(filename string, line int) (time.Time, error) {
+	if t, ok := f[line]; ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("no blame information for %s:%d", filename, line)
+}
+
+func TestSince(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{
+		Since: 30 * 24 * time.Hour,
+		Blame: fakeBlame{
+			5:  time.Now().Add(-100 * 24 * time.Hour),
+			12: time.Now().Add(-24 * time.Hour),
+		},
+	}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "since")
+}
+
+func TestGitBlame(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_DATE=2020-01-01T00:00:00Z", "GIT_COMMITTER_DATE=2020-01-01T00:00:00Z",
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	filename := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(filename, []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", "main.go")
+	git("commit", "-q", "-m", "initial")
+
+	// An uncommitted line is reported as changed now
+	if err := os.WriteFile(filename, []byte("package main\n\nfunc main() {}\n\nfunc other() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	blame := &recovercheck.GitBlame{}
+
+	committed, err := blame.LineTime(filename, 3)
+	if err != nil {
+		t.Fatalf("Failed to blame committed line: %v", err)
+	}
+	if want := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC); !committed.Equal(want) {
+		t.Errorf("Expected committed line time %v, got %v", want, committed)
+	}
+
+	uncommitted, err := blame.LineTime(filename, 5)
+	if err != nil {
+		t.Fatalf("Failed to blame uncommitted line: %v", err)
+	}
+	if time.Since(uncommitted) > time.Hour {
+		t.Errorf("Expected uncommitted line to be recent, got %v", uncommitted)
+	}
+
+	// Concurrent passes share the blame of each file, and a file git
+	// can't blame fails every time
+	blame = &recovercheck.GitBlame{}
+	missing := filepath.Join(dir, "missing.go")
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := blame.LineTime(filename, 3); err != nil || !got.Equal(committed) {
+				t.Errorf("Expected committed line time %v, got %v, %v", committed, got, err)
+			}
+			if _, err := blame.LineTime(missing, 1); err == nil {
+				t.Error("Expected an error blaming a missing file")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCoverage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	recovercheckSettings := &recovercheck.RecovercheckSettings{
+		Coverage: &recovercheck.CoverageCollector{Path: path},
+	}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "coverage", "summary")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read coverage report: %v", err)
+	}
+
+	var packages []recovercheck.PackageCoverage
+	if err := json.Unmarshal(data, &packages); err != nil {
+		t.Fatalf("Failed to parse coverage report: %v", err)
+	}
+
+	expected := []recovercheck.PackageCoverage{
+		{Package: "coverage", Total: 4, Recovered: 2, Coverage: 0.5},
+		{Package: "summary", Total: 3, Recovered: 1, Coverage: 1.0 / 3},
+	}
+
+	if len(packages) != len(expected) {
+		t.Fatalf("Expected %d packages, got %d: %s", len(expected), len(packages), data)
+	}
+	for i, want := range expected {
+		if packages[i] != want {
+			t.Errorf("Package %d: expected %+v, got %+v", i, want, packages[i])
+		}
+	}
+}
+
+func TestFlagGuardedRecover(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{FlagGuardedRecover: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "guardedrecover")
+}
+
+func TestDetectRepanic(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{DetectRepanic: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "repanic")
+}
+
+func TestMaxFindings(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{MaxFindings: 2}
+	results := analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "maxfindings")
+
+	// The truncation note isn't an unsafe goroutine
+	for _, result := range results {
+		if unsafe := result.Result.(*recovercheck.RecoverResult).Unsafe; len(unsafe) != 2 {
+			t.Errorf("Expected 2 unsafe goroutines in the result, got %d", len(unsafe))
+		}
+	}
+}
+
+func TestWarnSwallowedRecover(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{WarnSwallowedRecover: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "swallowed")
+}
+
+func TestSwallowedRecoverNotesAreNotFindings(t *testing.T) {
+	summary := &recovercheck.SummaryCollector{}
+	recovercheckSettings := &recovercheck.RecovercheckSettings{WarnSwallowedRecover: true, MaxFindings: 1, Summary: summary}
+	results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(recovercheckSettings), "swallowed")
+
+	// The notes are about recovered goroutines, so they neither count toward
+	// MaxFindings nor appear among the unsafe goroutines
+	count := 0
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			count++
+			if diagnostic.Category != "swallowed" {
+				t.Errorf("Expected category swallowed, got %q for %q", diagnostic.Category, diagnostic.Message)
+			}
+		}
+		if unsafe := result.Result.(*recovercheck.RecoverResult).Unsafe; len(unsafe) != 0 {
+			t.Errorf("Expected no unsafe goroutines in the result, got %v", unsafe)
+		}
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 notes, got %d", count)
+	}
+	if findings := summary.Findings(); len(findings) != 0 {
+		t.Errorf("Expected no findings in the summary, got %v", findings)
+	}
+}
+
+func TestSwallowedRecoverNotReportedByDefault(t *testing.T) {
+	results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "swallowed")
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			t.Errorf("Unexpected diagnostic without WarnSwallowedRecover: %s", diagnostic.Message)
+		}
+	}
+}
+
+func TestErrgroupStrict(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{ErrgroupStrict: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "errgroupstrict")
+}
+
+func TestErrgroupStrictNotFlaggedByDefault(t *testing.T) {
+	// The fixture expects the strict reports, so record the mismatches instead
+	results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "errgroupstrict")
+
+	count := 0
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			count++
+			if diagnostic.Message != "errgroup goroutine created without panic recovery" {
+				t.Errorf("Expected only unrecovered callbacks by default, got %q", diagnostic.Message)
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 diagnostic, got %d", count)
+	}
+}
+
+func TestRequireUnconditionalRecover(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{RequireUnconditionalRecover: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "unconditional")
+}
+
+func TestRequireHandledRecover(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{RequireHandledRecover: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "handled")
+}
+
+func TestFlagGoroutinesInLoops(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{FlagGoroutinesInLoops: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "loops")
+}
+
+func TestGoroutinesInLoopsNotFlaggedByDefault(t *testing.T) {
+	// The fixture expects the loop warning, so record the mismatches instead
+	results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "loops")
+
+	count := 0
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			count++
+			if strings.Contains(diagnostic.Message, "inside loop") {
+				t.Errorf("Expected no loop warning by default, got %q", diagnostic.Message)
+			}
+		}
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 diagnostics, got %d", count)
+	}
+}
+
+func TestFlagMustCalls(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{FlagMustCalls: true}
+	results := analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "mustcalls")
+
+	var related []string
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			for _, info := range diagnostic.Related {
+				// Enclosing functions are covered by TestEnclosingFuncRelated
+				if !strings.HasPrefix(info.Message, "in function ") {
+					related = append(related, info.Message)
+				}
+			}
+		}
+	}
+
+	expected := []string{"regexp.MustCompile may panic", "mustLoad may panic"}
+	if len(related) != len(expected) {
+		t.Fatalf("Expected related information %v, got %v", expected, related)
+	}
+	for i := range expected {
+		if related[i] != expected[i] {
+			t.Errorf("Expected related information %q, got %q", expected[i], related[i])
+		}
+	}
+}
+
+func TestDeepAnalysis(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{DeepAnalysis: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "deep")
+}
+
+func TestDiagnosticCategories(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{
+		SpawnFuncs: []string{"pool.Pool.Go", "pool.Go"},
+	}
+	results := analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "mustcalls", "spawn")
+
+	expected := map[string]string{
+		"goroutine created without panic recovery":                 "goroutine",
+		"errgroup goroutine created without panic recovery":        "errgroup",
+		"goroutine spawned by pool.Pool.Go without panic recovery": "spawn",
+		"goroutine spawned by pool.Go without panic recovery":      "spawn",
+	}
+	seen := make(map[string]bool)
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			category, ok := expected[diagnostic.Message]
+			if !ok {
+				t.Errorf("Unexpected diagnostic %q", diagnostic.Message)
+				continue
+			}
+			if diagnostic.Category != category {
+				t.Errorf("Expected category %q for %q, got %q", category, diagnostic.Message, diagnostic.Category)
+			}
+			seen[category] = true
+		}
+	}
+	for _, category := range []string{"goroutine", "errgroup", "spawn"} {
+		if !seen[category] {
+			t.Errorf("Expected a diagnostic with category %q", category)
+		}
+	}
+}
+
+func TestIsFinding(t *testing.T) {
+	tests := map[string]bool{
+		recovercheck.KindGoroutine: true,
+		recovercheck.KindErrgroup:  true,
+		recovercheck.KindSpawn:     true,
+		recovercheck.KindMain:      true,
+		recovercheck.KindNote:      false,
+		recovercheck.KindSwallowed: false,
+		recovercheck.KindTruncated: false,
+	}
+	for category, expected := range tests {
+		if got := recovercheck.IsFinding(category); got != expected {
+			t.Errorf("Expected IsFinding(%q) = %v, got %v", category, expected, got)
+		}
+	}
+}
+
+func TestSkipTestFiles(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{SkipTestFiles: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "skiptests")
+}
+
+func TestSkipGenerated(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{SkipGenerated: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "skipgenerated")
+}
+
+func TestGeneratedNotSkippedByDefault(t *testing.T) {
+	// The fixture expects the generated file to be skipped, so record the
+	// mismatches instead
+	results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "skipgenerated")
+
+	var files []string
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			files = append(files, filepath.Base(result.Action.Package.Fset.Position(diagnostic.Pos).Filename))
+		}
+	}
+	slices.Sort(files)
+	if expected := []string{"generated.go", "skipgenerated.go"}; !slices.Equal(files, expected) {
+		t.Errorf("Expected diagnostics in %q, got %q", expected, files)
+	}
+}
+
+func TestStrictMain(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{StrictMain: true}
+	results := analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "strictmain")
+
+	var categories []string
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			categories = append(categories, fmt.Sprintf("%d %s", result.Action.Package.Fset.Position(diagnostic.Pos).Line, diagnostic.Category))
+		}
+	}
+	slices.Sort(categories)
+	if expected := []string{"13 goroutine", "21 main", "9 goroutine"}; !slices.Equal(categories, expected) {
+		t.Errorf("Expected categories %q, got %q", expected, categories)
+	}
+}
+
+func TestNoteTestGoroutines(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{NoteTestGoroutines: true}
+	results := analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "testnotes")
+
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			isNote := strings.HasPrefix(diagnostic.Message, "note: ")
+			if (diagnostic.Category == "note") != isNote {
+				t.Errorf("Expected category note only for notes, got %q for %q", diagnostic.Category, diagnostic.Message)
+			}
+		}
+	}
+}
+
+func TestSpawnFuncs(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{
+		SpawnFuncs: []string{"pool.Pool.Go", "pool.Go"},
+	}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "spawn")
+}
+
+func TestParseSpawnFunc(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected recovercheck.SpawnFunc
+		wantErr  bool
+	}{
+		{spec: "pool.Go", expected: recovercheck.SpawnFunc{PkgPath: "pool", Name: "Go", Arg: -1}},
+		{spec: "github.com/acme/pool.Pool.Go", expected: recovercheck.SpawnFunc{PkgPath: "github.com/acme/pool", Recv: "Pool", Name: "Go", Arg: -1}},
+		{spec: "sched.Run:1", expected: recovercheck.SpawnFunc{PkgPath: "sched", Name: "Run", Arg: 1}},
+		{spec: "github.com/acme/sched.Scheduler.Run:0", expected: recovercheck.SpawnFunc{PkgPath: "github.com/acme/sched", Recv: "Scheduler", Name: "Run", Arg: 0}},
+		{spec: "sched.Run:x", wantErr: true},
+		{spec: "sched.Run:-1", wantErr: true},
+		{spec: "pool.Pool.Go.Extra", wantErr: true},
+		{spec: "Go", wantErr: true},
+		{spec: "pool.", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spawnFunc, err := recovercheck.ParseSpawnFunc(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", spawnFunc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if spawnFunc != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, spawnFunc)
+			}
+			if spawnFunc.String() != tt.spec {
+				t.Errorf("expected String() = %q, got %q", tt.spec, spawnFunc.String())
+			}
+		})
+	}
+}
+
+func TestConfigFiles(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "config", "config/sub", "config/cache")
+}
+
+func TestConfigFileFlagPrecedence(t *testing.T) {
+	analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{})
+	if err := analyzer.Flags.Set("detect-repanic", "false"); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.Run(t, analysistest.TestData(), analyzer, "config/explicit")
+}
+
+func TestFlagDefaults(t *testing.T) {
+	// The flag package calls String on a zero value of each flag to decide
+	// whether its default is worth printing
+	flags := &recovercheck.New(&recovercheck.RecovercheckSettings{}).Flags
+	var b strings.Builder
+	flags.SetOutput(&b)
+	flags.PrintDefaults()
+	if strings.Contains(b.String(), "panic calling") || strings.Contains(b.String(), "(default false)") || !strings.Contains(b.String(), "-detect-repanic") {
+		t.Errorf("expected the flag defaults without panics or false defaults, got:\n%s", b.String())
+	}
+}
+
+// errorRecorder collects the errors analysistest reports
+type errorRecorder struct {
+	errors []string
+}
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:     "unknown key",
+			config:   "unknownSetting: true\n",
+			expected: `.recovercheck.yaml:1: unknown key "unknownSetting"`,
+		},
+		{
+			name:     "invalid boolean",
+			config:   "# comment\ndetectRepanic: maybe\n",
+			expected: `.recovercheck.yaml:2: detectRepanic: invalid boolean "maybe"`,
+		},
+		{
+			name:     "list for a boolean",
+			config:   "deepAnalysis: [true]\n",
+			expected: "deepAnalysis: expected a single value, got a list",
+		},
+		{
+			name:     "invalid spawn function",
+			config:   "spawnFuncs:\n  - pool\n",
+			expected: `invalid spawn function "pool"`,
+		},
+		{
+			name:     "invalid integer",
+			config:   "maxFindings: lots\n",
+			expected: `maxFindings: invalid integer "lots"`,
+		},
+		{
+			name:     "unexpected indentation",
+			config:   "detectRepanic: true\n  deepAnalysis: true\n",
+			expected: "line 2: unexpected indentation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "src", "bad")
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, ".recovercheck.yaml"), []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "bad.go"), []byte("package bad\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			recorder := &errorRecorder{}
+			analysistest.Run(recorder, filepath.Dir(filepath.Dir(dir)), recovercheck.New(&recovercheck.RecovercheckSettings{}), "bad")
+
+			if !slices.ContainsFunc(recorder.errors, func(err string) bool { return strings.Contains(err, tt.expected) }) {
+				t.Errorf("Expected an error containing %q, got %q", tt.expected, recorder.errors)
+			}
+		})
+	}
+}
+
+func TestTrustedSpawners(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{
+		SpawnFuncs:      []string{"pool.Pool.Go", "pool.Go"},
+		TrustedSpawners: []string{"safego.Run", "pool.Go"},
+	}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "trusted")
+}
+
+func TestSafeFuncs(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{
+		SafeFuncs: []string{"example.com/safelib.Run", "example.com/safelib.Recover", "example.com/safelib.Guard.Recover"},
+	}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "safefunc")
+}
+
+func TestShadowedRecover(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "shadowrecover")
+}
+
+func TestDebugLog(t *testing.T) {
+	var out bytes.Buffer
+	recovercheckSettings := &recovercheck.RecovercheckSettings{Debug: true, DebugOutput: &out}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "debuglog")
+
+	expected := []string{
+		`recovercheck: debug: RecoverFunctions["work"] = false`,
+		`debuglog.go:11:5: declared function work: recovers=false`,
+		`debuglog.go:11:2: goroutine has no recovery: reported`,
+		`recovercheck: debug: asmlib.Work resolved by fact: recovers=false`,
+		`debuglog.go:12:5: RecoverFunctions["asmlib.Work"] = false`,
+		`debuglog.go:12:2: goroutine has no recovery: reported`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("Expected debug log to contain %q, got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestDebugLogDisabled(t *testing.T) {
+	var out bytes.Buffer
+	recovercheckSettings := &recovercheck.RecovercheckSettings{DebugOutput: &out}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "debuglog")
+
+	if out.Len() != 0 {
+		t.Errorf("Expected no debug log without Debug, got:\n%s", out.String())
+	}
+}
+
+// TestOverlaySource tests that functions of another package are classified
+// by the source the driver loaded, such as an editor's overlay, rather than
+// by the file on disk
+func TestOverlaySource(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/overlay\n\ngo 1.24\n",
+		// Recover is declared at the same offset as in the overlay
+		"lib/lib.go": `package lib
+
+func Recover() {}
+
+func Run() {}
+
+func NewWorker() func() {
+	return Run
+}
+`,
+		"app/app.go": `package app
+
+import "example.com/overlay/lib"
+
+func Start() {
+	go lib.Run()
+	go func() {
+		defer lib.Recover()
+		panic("oh no")
+	}()
+	go lib.NewWorker()()
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The edited buffer recovers, while the file on disk doesn't
+	overlay := map[string][]byte{
+		filepath.Join(dir, "lib/lib.go"): []byte(`package lib
+
+func Recover() {
+	if r := recover(); r != nil {
+		println("recovered:", r)
+	}
+}
+
+func Run() {
+	defer Recover()
+	panic("oh no")
+}
+
+func NewWorker() func() {
+	return Run
+}
+`),
+	}
+
+	analyze := func(overlay map[string][]byte) ([]string, string) {
+		var out bytes.Buffer
+		analyzer := recovercheck.New(&recovercheck.RecovercheckSettings{Debug: true, DebugOutput: &out})
+		cfg := &packages.Config{Mode: packages.LoadAllSyntax, Dir: dir, Overlay: overlay}
+		pkgs, err := packages.Load(cfg, "./app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packages.PrintErrors(pkgs) > 0 {
+			t.Fatal("Failed to load packages")
+		}
+		graph, err := checker.Analyze([]*analysis.Analyzer{analyzer}, pkgs, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var diagnostics []string
+		for _, act := range graph.Roots {
+			for _, diagnostic := range act.Diagnostics {
+				diagnostics = append(diagnostics, fmt.Sprintf("%d: %s", act.Package.Fset.Position(diagnostic.Pos).Line, diagnostic.Message))
+			}
+		}
+		return diagnostics, out.String()
+	}
+
+	diagnostics, log := analyze(overlay)
+	// The factory's body is only available from disk, which is stale
+	expected := []string{"11: goroutine created without panic recovery (could not resolve function returned by call)"}
+	if !slices.Equal(diagnostics, expected) {
+		t.Errorf("With the overlay, expected %q, got %q", expected, diagnostics)
+	}
+	if !strings.Contains(log, "lib.go on disk differs from the loaded source") {
+		t.Errorf("Expected the stale file on disk to be logged, got:\n%s", log)
+	}
+
+	diagnostics, _ = analyze(nil)
+	expected = []string{
+		"6: goroutine created without panic recovery",
+		"7: goroutine created without panic recovery",
+		"11: goroutine created without panic recovery",
+	}
+	if !slices.Equal(diagnostics, expected) {
+		t.Errorf("Without the overlay, expected %q, got %q", expected, diagnostics)
+	}
+}
+
+func TestAssumeExternalSafe(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{AssumeExternalSafe: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "externalsafe")
+}
+
+func TestExternalFunctionsUnsafeByDefault(t *testing.T) {
+	// The fixture's markers only cover the findings that remain with
+	// AssumeExternalSafe, so record the extra diagnostics instead
+	results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "externalsafe")
+
+	var lines []int
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			lines = append(lines, result.Action.Package.Fset.Position(diagnostic.Pos).Line)
+		}
+	}
+	slices.Sort(lines)
+
+	if expected := []int{7, 12, 28}; !slices.Equal(lines, expected) {
+		t.Errorf("Expected diagnostics on lines %v, got %v", expected, lines)
+	}
+}
+
+func TestFactoryCalls(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "factory")
+}
+
+func TestSelectorChains(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "selectorchain")
+}
+
+func TestAnalyzeSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected []string // "line: message"
+	}{
+		{
+			name: "safe goroutine",
+			src: `package p
+
+func f() {
+	go func() {
+		defer func() { recover() }()
+		panic("oh no")
+	}()
+}
+`,
+		},
+		{
+			name: "unsafe goroutine",
+			src: `package p
+
+func f() {
+	go func() {
+		panic("oh no")
+	}()
+}
+`,
+			expected: []string{"4: goroutine created without panic recovery"},
+		},
+		{
+			name: "recovery function declared in the file",
+			src: `package p
+
+func handlePanic() {
+	recover()
+}
+
+func f() {
+	go func() {
+		defer handlePanic()
+		panic("oh no")
+	}()
+	go work()
+}
+
+func work() {}
+`,
+			expected: []string{"12: goroutine created without panic recovery"},
+		},
+		{
+			name: "recovery function with parameters",
+			src: `package p
+
+import "log"
+
+func handlePanic(logger *log.Logger, attempts int) {
+	if r := recover(); r != nil {
+		logger.Println(attempts, r)
+	}
+}
+
+func f(logger *log.Logger) {
+	go func() {
+		defer handlePanic(logger, 3)
+		panic("oh no")
+	}()
+}
+`,
+		},
+		{
+			name: "recovery function of another package",
+			src: `package p
+
+import "example.com/safe"
+
+func f() {
+	go func() {
+		defer safe.Recover()
+		panic("oh no")
+	}()
+}
+`,
+			expected: []string{"6: goroutine created without panic recovery"},
+		},
+		{
+			name: "deferred local closure",
+			src: `package p
+
+func f() {
+	go func() {
+		handle := func() {
+			recover()
+		}
+		defer handle()
+		panic("oh no")
+	}()
+	go func() {
+		cleanup := func() {}
+		defer cleanup()
+		panic("oh no")
+	}()
+}
+`,
+			expected: []string{"11: goroutine created without panic recovery"},
+		},
+		{
+			name: "deferred closure of the enclosing function",
+			src: `package p
+
+func f() {
+	handle := func() {
+		recover()
+	}
+	go func() {
+		defer handle()
+		panic("oh no")
+	}()
+}
+`,
+		},
+		{
+			name: "deferred closure assigned twice",
+			src: `package p
+
+func f(debug bool) {
+	go func() {
+		handle := func() {
+			recover()
+		}
+		if debug {
+			handle = func() {}
+		}
+		defer handle()
+		panic("oh no")
+	}()
+}
+`,
+			expected: []string{"4: goroutine created without panic recovery"},
+		},
+		{
+			name: "deferred parameter shadowing a closure",
+			src: `package p
+
+func f() {
+	handle := func() {
+		recover()
+	}
+	run := func(handle func()) {
+		go func() {
+			defer handle()
+			panic("oh no")
+		}()
+	}
+	run(handle)
+}
+`,
+			expected: []string{"8: goroutine created without panic recovery"},
+		},
+		{
+			name: "recovery method declared in the file",
+			src: `package p
+
+type svc struct{}
+
+func (s *svc) recover() {
+	recover()
+}
+
+func (s *svc) f() {
+	go func() {
+		defer s.recover()
+		panic("oh no")
+	}()
+}
+`,
+		},
+		{
+			name: "same-named methods that disagree",
+			src: `package p
+
+type svc struct{}
+
+func (s *svc) done() {
+	recover()
+}
+
+type client struct{}
+
+func (c client) done() {}
+
+func f(c client) {
+	go func() {
+		defer c.done()
+		panic("oh no")
+	}()
+}
+`,
+			expected: []string{"14: goroutine created without panic recovery"},
+		},
+		{
+			name: "function named like a recovery method",
+			src: `package p
+
+type svc struct{}
+
+func (s *svc) done() {
+	recover()
+}
+
+func done() {}
+
+func f() {
+	go func() {
+		defer done()
+		panic("oh no")
+	}()
+}
+`,
+			expected: []string{"12: goroutine created without panic recovery"},
+		},
+		{
+			name: "variable assigned from a name shadowed in a function literal",
+			src: `package p
+
+func g() {}
+
+func f() {
+	a := g
+	go a()
+	func() {
+		g := a
+		_ = g
+	}()
+}
+`,
+			expected: []string{"7: goroutine created without panic recovery"},
+		},
+		{
+			name: "variables swapped",
+			src: `package p
+
+func g() {}
+
+func h() {}
+
+func f() {
+	a, b := g, h
+	func() {
+		a, b := b, a
+		go a()
+		_ = b
+	}()
+	_, _ = a, b
+}
+`,
+			expected: []string{"11: goroutine created without panic recovery"},
+		},
+		{
+			name: "variable shadowed in a function literal",
+			src: `package p
+
+func f() {
+	run := func() {
+		defer func() { recover() }()
+		panic("oh no")
+	}
+	func() {
+		run := 1
+		_ = run
+	}()
+	go run()
+}
+`,
+		},
+		{
+			name: "variable declared in a block",
+			src: `package p
+
+func f(cond bool) {
+	if cond {
+		run := func() {
+			defer func() { recover() }()
+			panic("oh no")
+		}
+		go run()
+	}
+}
+`,
+		},
+		{
+			name: "errgroup callback",
+			src: `package p
+
+import "golang.org/x/sync/errgroup"
+
+func f() {
+	var g errgroup.Group
+	g.Go(func() error {
+		panic("oh no")
+	})
+}
+`,
+			expected: []string{"7: errgroup goroutine created without panic recovery"},
+		},
+		{
+			name: "function named Go",
+			src: `package p
+
+func Go(f func() error) {}
+
+func f() {
+	Go(func() error {
+		panic("oh no")
+	})
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics, err := recovercheck.AnalyzeSource("p.go", tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var actual []string
+			for _, diagnostic := range diagnostics {
+				if diagnostic.Pos.Filename != "p.go" {
+					t.Errorf("Expected diagnostic in p.go, got %s", diagnostic.Pos)
+				}
+				actual = append(actual, fmt.Sprintf("%d: %s", diagnostic.Pos.Line, diagnostic.Message))
+			}
+			if !slices.Equal(actual, tt.expected) {
+				t.Errorf("Expected diagnostics %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSourceSyntaxError(t *testing.T) {
+	if _, err := recovercheck.AnalyzeSource("p.go", "package p\n\nfunc f() {\n"); err == nil {
+		t.Error("Expected an error for source that doesn't parse")
+	}
+}
+
+func TestRecoverySuggestedFixes(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "fix")
+}
+
+func TestSkipSelectLoops(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{SkipSelectLoops: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "selectloop")
+}
+
+func TestSelectLoopsReportedByDefault(t *testing.T) {
+	// The fixture's markers only cover the loops that are still reported
+	// with SkipSelectLoops, so record the extra diagnostics instead
+	results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "selectloop")
+
+	var lines []int
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			lines = append(lines, result.Action.Package.Fset.Position(diagnostic.Pos).Line)
+		}
+	}
+	slices.Sort(lines)
+
+	expected := []int{12, 26, 42, 69, 74, 88}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("Expected diagnostics on lines %v, got %v", expected, lines)
+	}
+}
+
+func TestReportAt(t *testing.T) {
+	positions := func(reportAt string) []string {
+		t.Helper()
+		settings := &recovercheck.RecovercheckSettings{ReportAt: reportAt}
+		// Each goroutine is reported on one line or the other depending
+		// on the mode, so record the diagnostics instead of matching them
+		results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(settings), "reportat")
+
+		var positions []string
+		for _, result := range results {
+			for _, diagnostic := range result.Diagnostics {
+				position := result.Action.Package.Fset.Position(diagnostic.Pos)
+				positions = append(positions, fmt.Sprintf("%d:%d", position.Line, position.Column))
+			}
+		}
+		slices.Sort(positions)
+		return positions
+	}
+
+	tests := []struct {
+		reportAt string
+		expected []string
+	}{
+		{reportAt: "", expected: []string{"12:2", "19:2", "28:2"}},
+		{reportAt: "go", expected: []string{"12:2", "19:2", "28:2"}},
+		{reportAt: "body", expected: []string{"12:12", "20:10", "28:2"}},
+	}
+	for _, tt := range tests {
+		if got := positions(tt.reportAt); !slices.Equal(got, tt.expected) {
+			t.Errorf("ReportAt %q: expected diagnostics at %v, got %v", tt.reportAt, tt.expected, got)
+		}
+	}
+}
+
+func TestPackageKind(t *testing.T) {
+	// Which goroutines are reported depends on the mode, so record the
+	// diagnostics instead of matching them
+	lines := func(settings *recovercheck.RecovercheckSettings, pkg string) []int {
+		t.Helper()
+		results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(settings), pkg)
+
+		var lines []int
+		for _, result := range results {
+			for _, diagnostic := range result.Diagnostics {
+				lines = append(lines, result.Action.Package.Fset.Position(diagnostic.Pos).Line)
+			}
+		}
+		slices.Sort(lines)
+		return lines
+	}
+
+	tests := []struct {
+		name     string
+		settings *recovercheck.RecovercheckSettings
+		pkg      string
+		expected []int
+	}{
+		{
+			name:     "command by default",
+			settings: &recovercheck.RecovercheckSettings{},
+			pkg:      "packagekind/cmd",
+			expected: []int{8, 9, 16},
+		},
+		{
+			name:     "command as main",
+			settings: &recovercheck.RecovercheckSettings{PackageKind: "main"},
+			pkg:      "packagekind/cmd",
+			expected: []int{16},
+		},
+		{
+			name:     "command as main with strict main",
+			settings: &recovercheck.RecovercheckSettings{PackageKind: "main", StrictMain: true},
+			pkg:      "packagekind/cmd",
+			expected: []int{8, 9, 16},
+		},
+		{
+			name:     "command detected",
+			settings: &recovercheck.RecovercheckSettings{PackageKind: "auto"},
+			pkg:      "packagekind/cmd",
+			expected: []int{16},
+		},
+		{
+			name:     "library skipping trivial bodies by default",
+			settings: &recovercheck.RecovercheckSettings{SkipTrivialBodies: true},
+			pkg:      "packagekind/lib",
+			expected: []int{16},
+		},
+		{
+			name:     "library as library",
+			settings: &recovercheck.RecovercheckSettings{PackageKind: "library", SkipTrivialBodies: true},
+			pkg:      "packagekind/lib",
+			expected: []int{9, 16},
+		},
+		{
+			name:     "library detected",
+			settings: &recovercheck.RecovercheckSettings{PackageKind: "auto", SkipTrivialBodies: true},
+			pkg:      "packagekind/lib",
+			expected: []int{9, 16},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lines(tt.settings, tt.pkg); !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected diagnostics on lines %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPackageKindCoverage(t *testing.T) {
+	// The goroutines started directly in main are exempt, and count as
+	// recovered like those of a trusted spawner
+	coverage := &recovercheck.CoverageCollector{}
+	settings := &recovercheck.RecovercheckSettings{PackageKind: "main", Coverage: coverage}
+	analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(settings), "packagekind/cmd")
+
+	expected := []recovercheck.PackageCoverage{
+		{Package: "packagekind/cmd", Total: 3, Recovered: 2, Coverage: 2.0 / 3},
+	}
+	if packages := coverage.Packages(); !slices.Equal(packages, expected) {
+		t.Errorf("Expected coverage %+v, got %+v", expected, packages)
+	}
+}
+
+func TestSkipTrivialBodies(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{SkipTrivialBodies: true}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "trivialbodies")
+}
+
+func TestTrivialBodiesReportedByDefault(t *testing.T) {
+	results := analysistest.Run(&errorRecorder{}, analysistest.TestData(), recovercheck.New(&recovercheck.RecovercheckSettings{}), "trivialbodies")
+
+	var lines []int
+	for _, result := range results {
+		for _, diagnostic := range result.Diagnostics {
+			lines = append(lines, result.Action.Package.Fset.Position(diagnostic.Pos).Line)
+		}
+	}
+	slices.Sort(lines)
+
+	expected := []int{9, 16, 30, 37, 44, 51, 58, 65, 72, 79, 86}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("Expected diagnostics on lines %v, got %v", expected, lines)
+	}
+}
+
+func TestSpawnFuncRegistry(t *testing.T) {
+	recovercheckSettings := &recovercheck.RecovercheckSettings{
+		SpawnFuncs: []string{"sched.Run:1"},
+	}
+	analysistest.Run(t, analysistest.TestData(), recovercheck.New(recovercheckSettings), "registry")
+}
+
+func TestFuncRegexFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *recovercheck.RecovercheckSettings
+		pkg      string
+	}{
+		{
+			name:     "include exported functions and methods",
+			settings: &recovercheck.RecovercheckSettings{IncludeFuncRegex: `(^|\.|\))[A-Z]\w*$`},
+			pkg:      "funcfilter/include",
+		},
+		{
+			name: "exclude methods of Server and a variable initializer",
+			settings: &recovercheck.RecovercheckSettings{
+				ExcludeFuncRegex: `^\(\*funcfilter/exclude\.Server\)\.|\.startWorker$`,
+			},
+			pkg: "funcfilter/exclude",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysistest.Run(t, analysistest.TestData(), recovercheck.New(tt.settings), tt.pkg)
+		})
+	}
 }
@@ -0,0 +1,51 @@
+package recovercheck
+
+import (
+	"cmp"
+	"go/token"
+	"slices"
+)
+
+// RecoverResult is the result of the recovercheck analyzer for a package.
+// Analyzers that list recovercheck in their Requires can reuse its findings
+// and classifications instead of repeating the analysis.
+type RecoverResult struct {
+	// Unsafe lists the reported goroutines and callbacks in position order.
+	// Findings suppressed by Since are left out.
+	Unsafe []UnsafeGoroutine
+
+	// TotalGoroutines counts the go statements analyzed, leaving out those
+	// skipped by SkipTestFiles, SkipGenerated or the function filters, and
+	// SafeGoroutines those that recover or need no recovery, such as ones
+	// running a trusted spawner. Unlike Unsafe, they aren't affected by
+	// Since or MaxFindings. Spawned callbacks aren't counted.
+	TotalGoroutines int
+	SafeGoroutines  int
+
+	// RecoverFunctions records whether each function classified during the
+	// pass recovers: the package's own functions and methods, keyed by name
+	// or receiver-qualified name such as (*T).m, and the functions of other
+	// packages it calls, keyed by package-qualified name.
+	RecoverFunctions map[string]bool
+}
+
+// UnsafeGoroutine is a goroutine or spawned callback reported by the analyzer
+type UnsafeGoroutine struct {
+	Pos     token.Pos
+	Kind    string // the diagnostic's Category, such as KindGoroutine, as in Finding
+	Message string
+}
+
+// Result returns the result of a pass once every finding has been reported
+func (r *Analyzer) Result() *RecoverResult {
+	unsafe := slices.Clone(r.unsafe)
+	slices.SortStableFunc(unsafe, func(a, b UnsafeGoroutine) int {
+		return cmp.Compare(a.Pos, b.Pos)
+	})
+	return &RecoverResult{
+		Unsafe:           unsafe,
+		TotalGoroutines:  r.totalGoroutines,
+		SafeGoroutines:   r.safeGoroutines,
+		RecoverFunctions: r.RecoverFunctions,
+	}
+}
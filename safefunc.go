@@ -0,0 +1,44 @@
+package recovercheck
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// parseSafeFuncs parses the configured safe functions. Unlike spawn
+// functions they take no :N argument index, since no argument of theirs is
+// run in a goroutine.
+func parseSafeFuncs(specs []string) ([]SpawnFunc, error) {
+	safeFuncs := make([]SpawnFunc, 0, len(specs))
+	for _, spec := range specs {
+		safeFunc, ok := parseFuncName(spec)
+		if !ok || strings.Contains(spec, ":") {
+			return nil, fmt.Errorf("invalid safe function %q: want pkg.Func or pkg.Type.Method", spec)
+		}
+		safeFuncs = append(safeFuncs, safeFunc)
+	}
+	return safeFuncs, nil
+}
+
+// isSafeFunc checks if fun refers to one of the configured safe functions,
+// which are trusted to recover whatever their source says, whether a
+// goroutine runs them, as in go safelib.Run(fn), or they are deferred, as in
+// defer safelib.Recover(). Safe functions are only matched with type
+// information, by the import path of their package.
+func (r *Analyzer) isSafeFunc(fun ast.Expr) bool {
+	if len(r.safeFuncs) == 0 {
+		return false
+	}
+	fn := r.funcObjectOf(fun)
+	if fn == nil {
+		return false
+	}
+	for _, safeFunc := range r.safeFuncs {
+		if isSpawnFunc(fn, safeFunc) {
+			r.debugf(fun.Pos(), "%s is a safe function: recovers=true", safeFunc.funcName())
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package recovercheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// isContextSelectLoop checks if a goroutine's function is a worker loop:
+// its body is a single for { select { ... } } with a case receiving from
+// ctx.Done(), optionally preceded by defer statements such as
+// defer ticker.Stop(). Function literals and functions declared in the
+// current package are recognized.
+func (r *Analyzer) isContextSelectLoop(fn ast.Expr) bool {
+	var body *ast.BlockStmt
+	switch fn := r.funcValue(fn).(type) {
+	case *ast.FuncLit:
+		body = fn.Body
+	case *ast.Ident, *ast.SelectorExpr:
+		if funcDecl := r.funcDeclOf(fn); funcDecl != nil {
+			body = funcDecl.Body
+		}
+	}
+	if body == nil {
+		return false
+	}
+
+	var loop *ast.ForStmt
+	for _, stmt := range body.List {
+		switch stmt := stmt.(type) {
+		case *ast.DeferStmt:
+			if loop != nil {
+				return false
+			}
+		case *ast.ForStmt:
+			if loop != nil {
+				return false
+			}
+			loop = stmt
+		default:
+			return false
+		}
+	}
+	if loop == nil || loop.Init != nil || loop.Cond != nil || loop.Post != nil || len(loop.Body.List) != 1 {
+		return false
+	}
+
+	sel, ok := loop.Body.List[0].(*ast.SelectStmt)
+	if !ok {
+		return false
+	}
+	for _, stmt := range sel.Body.List {
+		if clause, ok := stmt.(*ast.CommClause); ok && r.isContextDoneReceive(clause.Comm) {
+			return true
+		}
+	}
+	return false
+}
+
+// isContextDoneReceive checks if a select case receives from the Done
+// channel of a context, as in case <-ctx.Done(). Without type information
+// any Done() method counts.
+func (r *Analyzer) isContextDoneReceive(comm ast.Stmt) bool {
+	var recv ast.Expr
+	switch comm := comm.(type) {
+	case *ast.ExprStmt:
+		recv = comm.X
+	case *ast.AssignStmt:
+		if len(comm.Rhs) == 1 {
+			recv = comm.Rhs[0]
+		}
+	}
+
+	unary, ok := recv.(*ast.UnaryExpr)
+	if !ok || unary.Op != token.ARROW {
+		return false
+	}
+	call, ok := unary.X.(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return false
+	}
+	done, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || done.Sel.Name != "Done" {
+		return false
+	}
+
+	if r.Pass.TypesInfo == nil {
+		return true
+	}
+	t := r.Pass.TypesInfo.TypeOf(done.X)
+	return t != nil && isNamedType(types.Unalias(t), "context", "Context")
+}
@@ -0,0 +1,74 @@
+package recovercheck
+
+import (
+	"cmp"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Diagnostic is a finding reported by AnalyzeSource
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+// AnalyzeSource checks the goroutines and errgroup callbacks of a single Go
+// source file, such as an editor buffer, without loading its package. The
+// default settings are used and diagnostics are returned in source order.
+//
+// Without type information some checks are degraded:
+//   - functions are matched to the file's declarations by name; functions
+//     of other packages, or of other files of the same package, can't be
+//     resolved and are assumed not to recover
+//   - methods are matched by name, since receiver types are unknown: a
+//     method call is assumed to recover only if every method of that name
+//     declared in the file does
+//   - local variables are matched by name within their enclosing functions,
+//     and only resolved when assigned once; other function values, including
+//     functions returned by calls as in go factory()(), are not resolved, so
+//     goroutines running them are assumed not to recover
+//   - every .Go() call is treated as an errgroup.Group.Go call
+//   - recover and panic are assumed to be the builtins, even if shadowed by
+//     a package-level declaration in another file
+//
+// filename is only used in positions. An error is returned if src doesn't
+// parse.
+func AnalyzeSource(filename, src string) ([]Diagnostic, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	pass := &analysis.Pass{
+		Fset:  fset,
+		Files: []*ast.File{file},
+		Report: func(d analysis.Diagnostic) {
+			diagnostics = append(diagnostics, Diagnostic{Pos: fset.Position(d.Pos), Message: d.Message})
+		},
+	}
+	analyzer := &Analyzer{
+		Pass:             pass,
+		RecoverFunctions: make(map[string]bool),
+		Settings:         &RecovercheckSettings{},
+	}
+
+	nodes := CollectNodes(inspector.New(pass.Files), errgroupGo)
+	analyzer.GoContexts = nodes.GoContexts
+	analyzer.SpawnContexts = nodes.SpawnContexts
+
+	analyzer.AnalyzeFunctions(nodes.FunctionDecls)
+	analyzer.AnalyzeGoroutines(nodes.GoStatements)
+	analyzer.AnalyzeSpawnCalls(nodes.SpawnCalls, []SpawnFunc{errgroupGo})
+
+	slices.SortStableFunc(diagnostics, func(a, b Diagnostic) int {
+		return cmp.Compare(a.Pos.Offset, b.Pos.Offset)
+	})
+	return diagnostics, nil
+}
@@ -0,0 +1,335 @@
+package recovercheck
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SpawnFunc identifies a function or method that runs its function argument
+// in a new goroutine, such as a worker pool's Go method
+type SpawnFunc struct {
+	PkgPath string // import path of the declaring package
+	Recv    string // receiver type name for methods, "" for functions
+	Name    string // function or method name
+	Arg     int    // index of the argument run in a goroutine, -1 for the first function-typed one
+}
+
+// errgroupGo is errgroup.Group.Go, whose callbacks are reported as errgroup
+// goroutines rather than spawned ones
+var errgroupGo = SpawnFunc{PkgPath: "golang.org/x/sync/errgroup", Recv: "Group", Name: "Go", Arg: 0}
+
+// knownSpawnFuncs is the registry of well-known APIs that run a function
+// argument in a new goroutine. They are checked in every package, before
+// any configured SpawnFuncs.
+var knownSpawnFuncs = []SpawnFunc{
+	errgroupGo,
+	{PkgPath: "github.com/panjf2000/ants/v2", Recv: "Pool", Name: "Submit", Arg: 0},
+	{PkgPath: "github.com/sourcegraph/conc/pool", Recv: "Pool", Name: "Go", Arg: 0},
+}
+
+// knownTrustedSpawners is the registry of well-known APIs that run their
+// function argument with recovery: conc's WaitGroup.Go catches a panic and
+// re-raises it from Wait, and its panics.Try and Catcher.Try recover it.
+// Unlike configured TrustedSpawners they are only matched with type
+// information, so that other .Go() calls aren't mistaken for them.
+var knownTrustedSpawners = []SpawnFunc{
+	{PkgPath: "github.com/sourcegraph/conc", Recv: "WaitGroup", Name: "Go", Arg: 0},
+	{PkgPath: "github.com/sourcegraph/conc/panics", Name: "Try", Arg: 0},
+	{PkgPath: "github.com/sourcegraph/conc/panics", Recv: "Catcher", Name: "Try", Arg: 0},
+}
+
+// ParseSpawnFunc parses a spawn function written as pkg.Func or
+// pkg.Type.Method, where pkg is a full import path such as
+// github.com/acme/pool. The last element of pkg must not contain a dot.
+// A :N suffix, as in pkg.Run:1, selects the argument at index N as the
+// function run in a goroutine; by default it is the first function-typed
+// argument.
+func ParseSpawnFunc(spec string) (SpawnFunc, error) {
+	arg := -1
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		n, err := strconv.Atoi(spec[i+1:])
+		if err != nil || n < 0 {
+			return SpawnFunc{}, fmt.Errorf("invalid spawn function %q: argument index must be a non-negative integer", spec)
+		}
+		spec, arg = spec[:i], n
+	}
+
+	spawnFunc, ok := parseFuncName(spec)
+	if !ok {
+		return SpawnFunc{}, fmt.Errorf("invalid spawn function %q: want pkg.Func or pkg.Type.Method", spec)
+	}
+	spawnFunc.Arg = arg
+	return spawnFunc, nil
+}
+
+// parseFuncName parses a function or method written as pkg.Func or
+// pkg.Type.Method, where pkg is a full import path whose last element has
+// no dot
+func parseFuncName(spec string) (SpawnFunc, bool) {
+	dir, last := "", spec
+	if i := strings.LastIndex(spec, "/"); i >= 0 {
+		dir, last = spec[:i+1], spec[i+1:]
+	}
+
+	parts := strings.Split(last, ".")
+	for _, part := range parts {
+		if part == "" {
+			return SpawnFunc{}, false
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return SpawnFunc{PkgPath: dir + parts[0], Name: parts[1], Arg: -1}, true
+	case 3:
+		return SpawnFunc{PkgPath: dir + parts[0], Recv: parts[1], Name: parts[2], Arg: -1}, true
+	}
+	return SpawnFunc{}, false
+}
+
+// String returns the spawn function in the form accepted by ParseSpawnFunc
+func (s SpawnFunc) String() string {
+	if s.Arg >= 0 {
+		return s.funcName() + ":" + strconv.Itoa(s.Arg)
+	}
+	return s.funcName()
+}
+
+// funcName returns the function or method as pkg.Func or pkg.Type.Method
+func (s SpawnFunc) funcName() string {
+	if s.Recv != "" {
+		return s.PkgPath + "." + s.Recv + "." + s.Name
+	}
+	return s.PkgPath + "." + s.Name
+}
+
+// parseSpawnFuncs parses every configured spawn function
+func parseSpawnFuncs(specs []string) ([]SpawnFunc, error) {
+	spawnFuncs := make([]SpawnFunc, 0, len(specs))
+	for _, spec := range specs {
+		spawnFunc, err := ParseSpawnFunc(spec)
+		if err != nil {
+			return nil, err
+		}
+		spawnFuncs = append(spawnFuncs, spawnFunc)
+	}
+	return spawnFuncs, nil
+}
+
+// calleeName returns the name of the function or method called by call,
+// looking through explicit instantiations such as Go[int]
+func calleeName(call *ast.CallExpr) string {
+	fun := call.Fun
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+// isSpawnCallCandidate checks by name alone if call might call one of spawnFuncs
+func isSpawnCallCandidate(call *ast.CallExpr, spawnFuncs []SpawnFunc) bool {
+	name := calleeName(call)
+	for _, spawnFunc := range spawnFuncs {
+		if spawnFunc.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// spawnCall is a call to a spawn function along with the function it runs
+type spawnCall struct {
+	call      *ast.CallExpr
+	spawnFunc SpawnFunc
+	fn        ast.Expr
+}
+
+// AnalyzeSpawnCalls analyzes calls to registered spawn functions. The
+// functions they run are classified concurrently, then reported in position order.
+func (r *Analyzer) AnalyzeSpawnCalls(calls []*ast.CallExpr, spawnFuncs []SpawnFunc) {
+	var candidates []spawnCall
+	for _, call := range calls {
+		if r.isSkippedFile(call.Pos()) || r.isFilteredSpawnCall(call) {
+			continue
+		}
+		spawnFunc, ok := r.spawnFuncOf(call, spawnFuncs)
+		if !ok {
+			continue
+		}
+		if r.isTrustedSpawnerCall(call) {
+			r.recordCoverage(call.Pos(), true)
+			continue
+		}
+		if fn := r.spawnedFunc(call, spawnFunc); fn != nil {
+			candidates = append(candidates, spawnCall{call, spawnFunc, fn})
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b spawnCall) int {
+		return cmp.Compare(a.call.Pos(), b.call.Pos())
+	})
+	recovered := r.classifyConcurrently(len(candidates), func(worker *Analyzer, i int) bool {
+		if candidates[i].spawnFunc == errgroupGo {
+			return worker.hasCallbackRecovery(candidates[i].fn)
+		}
+		return worker.isRecoveringCallback(candidates[i].fn)
+	})
+	for i, c := range candidates {
+		if c.spawnFunc == errgroupGo {
+			r.analyzeErrgroupCall(c.call, recovered[i])
+			continue
+		}
+		r.analyzeSpawnCall(c.call, c.spawnFunc, c.fn, recovered[i])
+	}
+}
+
+// isTrustedSpawnerCall checks if call calls one of the trusted spawners,
+// whose function argument is run with recovery
+func (r *Analyzer) isTrustedSpawnerCall(call *ast.CallExpr) bool {
+	if r.Pass.TypesInfo != nil {
+		if _, ok := r.spawnFuncOf(call, knownTrustedSpawners); ok {
+			return true
+		}
+	}
+	if len(r.trustedSpawners) == 0 {
+		return false
+	}
+	_, ok := r.spawnFuncOf(call, r.trustedSpawners)
+	return ok
+}
+
+// analyzeSpawnCall reports a classified call to a spawn function unless the
+// function fn it runs recovers
+func (r *Analyzer) analyzeSpawnCall(call *ast.CallExpr, spawnFunc SpawnFunc, fn ast.Expr, recovered bool) {
+	r.recordCoverage(call.Pos(), recovered)
+	if !recovered {
+		r.report(call.Pos(), KindSpawn, fmt.Sprintf("goroutine spawned by %s without panic recovery", spawnFunc.funcName()), r.mustCallRelated(fn)...)
+	}
+}
+
+// spawnFuncOf returns the spawn function called by call. With type
+// information the callee must match exactly; without it, the function or
+// method name must match, methods must be called through a selector and,
+// for package-qualified calls to functions, the package name must match too.
+func (r *Analyzer) spawnFuncOf(call *ast.CallExpr, spawnFuncs []SpawnFunc) (SpawnFunc, bool) {
+	fun := call.Fun
+	if generic := r.instantiatedFunc(fun); generic != nil {
+		fun = generic
+	}
+
+	var ident *ast.Ident
+	var qualifier string
+	var selected bool
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident, selected = fun.Sel, true
+		if x, ok := fun.X.(*ast.Ident); ok {
+			qualifier = x.Name
+		}
+	default:
+		return SpawnFunc{}, false
+	}
+
+	if r.Pass.TypesInfo != nil {
+		fn, ok := r.Pass.TypesInfo.Uses[ident].(*types.Func)
+		if !ok {
+			return SpawnFunc{}, false
+		}
+		for _, spawnFunc := range spawnFuncs {
+			if isSpawnFunc(fn, spawnFunc) {
+				return spawnFunc, true
+			}
+		}
+		return SpawnFunc{}, false
+	}
+
+	for _, spawnFunc := range spawnFuncs {
+		if spawnFunc.Name != ident.Name || (spawnFunc.Recv != "" && !selected) {
+			continue
+		}
+		if spawnFunc.Recv == "" && qualifier != "" && qualifier != packageName(spawnFunc.PkgPath) {
+			continue
+		}
+		return spawnFunc, true
+	}
+	return SpawnFunc{}, false
+}
+
+// isSpawnFunc checks if fn, or the generic function or method it was
+// instantiated from, is spawnFunc
+func isSpawnFunc(fn *types.Func, spawnFunc SpawnFunc) bool {
+	fn = fn.Origin()
+	if fn.Pkg() == nil || fn.Pkg().Path() != spawnFunc.PkgPath || fn.Name() != spawnFunc.Name {
+		return false
+	}
+
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return spawnFunc.Recv == ""
+	}
+
+	t := types.Unalias(recv.Type())
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = types.Unalias(ptr.Elem())
+	}
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Name() == spawnFunc.Recv
+}
+
+// spawnedFunc returns the argument a spawn function runs in a goroutine: the
+// one at its Arg index if set, otherwise the first function-typed argument,
+// or without type information the first function literal, falling back to
+// the first argument
+func (r *Analyzer) spawnedFunc(call *ast.CallExpr, spawnFunc SpawnFunc) ast.Expr {
+	if spawnFunc.Arg >= 0 {
+		if spawnFunc.Arg < len(call.Args) {
+			return call.Args[spawnFunc.Arg]
+		}
+		return nil
+	}
+	if len(call.Args) == 0 {
+		return nil
+	}
+
+	for _, arg := range call.Args {
+		if r.Pass.TypesInfo != nil {
+			if t := r.Pass.TypesInfo.TypeOf(arg); t != nil {
+				if _, ok := t.Underlying().(*types.Signature); ok {
+					return arg
+				}
+			}
+			continue
+		}
+		if _, ok := arg.(*ast.FuncLit); ok {
+			return arg
+		}
+	}
+
+	if r.Pass.TypesInfo != nil {
+		return nil
+	}
+	return call.Args[0]
+}
+
+// packageName returns the last element of an import path, which is the
+// package name by convention
+func packageName(pkgPath string) string {
+	return pkgPath[strings.LastIndex(pkgPath, "/")+1:]
+}
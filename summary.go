@@ -0,0 +1,144 @@
+package recovercheck
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Kinds of reported goroutines, also used as the Category of each
+// diagnostic. Drivers tell findings from notes with IsFinding.
+const (
+	KindGoroutine = "goroutine"
+	KindErrgroup  = "errgroup"
+	KindSpawn     = "spawn"
+	KindMain      = "main" // an unrecovered goroutine in main() reported by StrictMain
+	KindNote      = "note" // an unrecovered goroutine downgraded by NoteTestGoroutines
+
+	// Categories of notes about something other than an unsafe goroutine,
+	// which are left out of the result, the summary and MaxFindings
+	KindSwallowed = "swallowed" // a recovered goroutine discarding the recovered value, see WarnSwallowedRecover
+	KindTruncated = "truncated" // the note that MaxFindings was reached
+)
+
+// IsFinding checks if diagnostics of category report an unsafe goroutine at
+// the configured severity, which fails a run. Other categories are notes,
+// which are printed without failing it.
+func IsFinding(category string) bool {
+	switch category {
+	case KindGoroutine, KindErrgroup, KindSpawn, KindMain:
+		return true
+	}
+	return false
+}
+
+// Finding describes a single goroutine reported by the analyzer
+type Finding struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// SummaryCollector accumulates findings across all analysis passes.
+//
+// Drivers such as singlechecker exit without telling analyzers that the run
+// is over, so when Path is set the whole summary is rewritten after every
+// pass; the file left behind when the process exits covers all packages.
+type SummaryCollector struct {
+	Path string
+
+	mu       sync.Mutex
+	findings []Finding
+	seen     map[Finding]bool
+}
+
+// Add records a finding. A finding already recorded, as when a package is
+// analyzed again as its test variant, is recorded once. It is safe to call
+// from concurrent passes.
+func (c *SummaryCollector) Add(finding Finding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.seen[finding] {
+		return
+	}
+	if c.seen == nil {
+		c.seen = make(map[Finding]bool)
+	}
+	c.seen[finding] = true
+	c.findings = append(c.findings, finding)
+}
+
+// Findings returns the recorded findings sorted by position
+func (c *SummaryCollector) Findings() []Finding {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sortedFindings()
+}
+
+// WriteJSON writes the recorded findings as a JSON array
+func (c *SummaryCollector) WriteJSON(w io.Writer) error {
+	return writeJSON(w, nonNil(c.Findings()))
+}
+
+// Flush writes the summary to Path, if set
+func (c *SummaryCollector) Flush() error {
+	if c.Path == "" {
+		return nil
+	}
+
+	// Hold the lock while writing so concurrent passes can't replace a
+	// newer summary with an older snapshot
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return writeJSONFile(c.Path, nonNil(c.sortedFindings()))
+}
+
+// sortedFindings returns a copy of the findings sorted by position; the
+// caller must hold c.mu
+func (c *SummaryCollector) sortedFindings() []Finding {
+	findings := slices.Clone(c.findings)
+	slices.SortFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+	return findings
+}
+
+// nonNil returns an empty slice for nil so it is encoded as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeJSONFile writes v as indented JSON to path, replacing its contents
+func writeJSONFile(path string, v any) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(file, v); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,19 @@
+package app
+
+import "example.com/recoverlib"
+
+// SafeGoroutine defers a recovery function from another module
+func SafeGoroutine() {
+	go func() {
+		defer recoverlib.Recover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutine defers a non-recovering function from another module
+func UnsafeGoroutine() {
+	go func() { // want "goroutine created without panic recovery"
+		defer recoverlib.Cleanup()
+		panic("oh no")
+	}()
+}
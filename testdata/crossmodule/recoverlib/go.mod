@@ -0,0 +1,3 @@
+module example.com/recoverlib
+
+go 1.24
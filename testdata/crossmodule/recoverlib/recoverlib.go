@@ -0,0 +1,16 @@
+// Package recoverlib lives in its own module to exercise cross-module resolution
+package recoverlib
+
+import "log"
+
+// Recover recovers from a panic in the deferring goroutine
+func Recover() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+// Cleanup looks like a deferred helper but doesn't recover
+func Cleanup() {
+	log.Println("cleaning up")
+}
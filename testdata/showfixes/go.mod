@@ -0,0 +1,3 @@
+module example.com/showfixes
+
+go 1.24
@@ -0,0 +1,27 @@
+// Package showfixes runs goroutines without panic recovery, to preview the
+// fixes suggested for them
+package showfixes
+
+import "fmt"
+
+// Config configures a worker
+type Config struct {
+	Name    string
+	Retries int
+}
+
+// String describes the configuration
+func (c Config) String() string {
+	return fmt.Sprintf("%s (%d retries)", c.Name, c.Retries)
+}
+
+func work(c Config) {
+	fmt.Println("working on", c)
+}
+
+// Start runs work for each configuration
+func Start(configs []Config) {
+	for _, c := range configs {
+		go work(c)
+	}
+}
@@ -0,0 +1,24 @@
+package aliasimport
+
+import aliaspkg "recovercheck/pkg"
+
+// SafeAliasedRecover defers a recovering function through an import alias
+func SafeAliasedRecover() {
+	go func() {
+		defer aliaspkg.PanicRecover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeAliasedNotRecovering defers a non-recovering function through an import alias
+func UnsafeAliasedNotRecovering() {
+	go func() { // want "goroutine created without panic recovery"
+		defer aliaspkg.NotRecovering()
+		panic("oh no")
+	}()
+}
+
+// SafeAliasedRun runs a recovering function through an import alias
+func SafeAliasedRun() {
+	go aliaspkg.Run()
+}
@@ -0,0 +1,13 @@
+package other
+
+import "log"
+
+// PanicRecover shares its name with recovercheck/pkg.PanicRecover but doesn't recover
+func PanicRecover() {
+	log.Println("done")
+}
+
+// Run shares its name with recovercheck/pkg.Run but doesn't recover
+func Run() {
+	panic("oh no")
+}
@@ -0,0 +1,17 @@
+package aliasimport
+
+// The same alias names a different package in this file
+import aliaspkg "aliasimport/other"
+
+// UnsafeSameAliasOtherPackage defers a same-named function from another package
+func UnsafeSameAliasOtherPackage() {
+	go func() { // want "goroutine created without panic recovery"
+		defer aliaspkg.PanicRecover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeSameAliasOtherRun runs a same-named function from another package
+func UnsafeSameAliasOtherRun() {
+	go aliaspkg.Run() // want "goroutine created without panic recovery"
+}
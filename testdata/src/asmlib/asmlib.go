@@ -0,0 +1,14 @@
+package asmlib
+
+// DoWork is implemented outside Go, so its body can't be analyzed
+func DoWork()
+
+// Recover recovers from panics
+func Recover() {
+	recover()
+}
+
+// Work has no panic recovery
+func Work() {
+	panic("oh no")
+}
@@ -0,0 +1,24 @@
+package buildtags
+
+import "buildtags/platform"
+
+// SafeLocalHelper defers the helper declared for the default build
+func SafeLocalHelper() {
+	go func() {
+		defer handlePanic()
+		panic("oh no")
+	}()
+}
+
+// SafeImportedHelper defers the imported helper declared for the default build
+func SafeImportedHelper() {
+	go func() {
+		defer platform.HandlePanic()
+		panic("oh no")
+	}()
+}
+
+// SafeImportedGoroutine runs the imported helper declared for the default build
+func SafeImportedGoroutine() {
+	go platform.HandlePanic()
+}
@@ -0,0 +1,7 @@
+//go:build !recovercheck_tagged
+
+package buildtags
+
+func handlePanic() {
+	recover()
+}
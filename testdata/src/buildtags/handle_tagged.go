@@ -0,0 +1,5 @@
+//go:build recovercheck_tagged
+
+package buildtags
+
+func handlePanic() {}
@@ -0,0 +1,8 @@
+//go:build !recovercheck_tagged
+
+package platform
+
+// HandlePanic recovers in default builds
+func HandlePanic() {
+	recover()
+}
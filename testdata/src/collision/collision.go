@@ -0,0 +1,42 @@
+package collision
+
+import "log"
+
+type pool struct{}
+
+// worker shares its name with the method of pool, but doesn't recover
+func worker() {
+	panic("oh no")
+}
+
+// worker recovers
+func (p *pool) worker() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+type queue struct{}
+
+// worker doesn't recover, whatever the methods of other types do
+func (q queue) worker() {
+	panic("oh no")
+}
+
+// UnsafeGoroutineWithFunction runs the function, not the method of the same name
+func UnsafeGoroutineWithFunction() {
+	go worker() // want "goroutine created without panic recovery"
+}
+
+// SafeGoroutineWithMethod runs the recovering method
+func SafeGoroutineWithMethod(p *pool) {
+	go p.worker()
+}
+
+// UnsafeGoroutineWithValueMethod runs the method of another type
+func UnsafeGoroutineWithValueMethod(q queue) {
+	go q.worker() // want "goroutine created without panic recovery"
+}
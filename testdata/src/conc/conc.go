@@ -0,0 +1,49 @@
+package conc
+
+import (
+	"sync"
+
+	"github.com/sourcegraph/conc"
+	"github.com/sourcegraph/conc/panics"
+)
+
+func work() {
+	panic("oh no")
+}
+
+// SafeConcWaitGroup relies on conc.WaitGroup catching the panic
+func SafeConcWaitGroup() {
+	var wg conc.WaitGroup
+	wg.Go(work)
+	wg.Go(func() {
+		panic("oh no")
+	})
+	wg.Wait()
+}
+
+// SafePanicsTry runs the goroutine's work through panics.Try
+func SafePanicsTry() {
+	go panics.Try(work)
+
+	var catcher panics.Catcher
+	go catcher.Try(work)
+}
+
+// UnsafeSyncWaitGroup only synchronizes, it doesn't recover
+func UnsafeSyncWaitGroup() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { // want "goroutine created without panic recovery"
+		defer wg.Done()
+		panic("oh no")
+	}()
+	wg.Wait()
+}
+
+// UnsafeWorkAroundTry only protects the call inside panics.Try
+func UnsafeWorkAroundTry() {
+	go func() { // want "goroutine created without panic recovery"
+		panics.Try(work)
+		work()
+	}()
+}
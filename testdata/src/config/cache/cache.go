@@ -0,0 +1,15 @@
+//line ../cgosrc/cache.go:1
+package cache
+
+func work() {}
+
+// GeneratedStart is excluded by the configuration in the directory its
+// //line directive points to
+func GeneratedStart() {
+	go work()
+}
+
+// Start is reported
+func Start() {
+	go work() // want "goroutine created without panic recovery"
+}
@@ -0,0 +1,32 @@
+package config
+
+import "log"
+
+var debug bool
+
+// UnsafeGuardedRecover is reported because the directory's configuration
+// enables flagGuardedRecover
+func UnsafeGuardedRecover() {
+	go func() { // want "goroutine recovery can be skipped: deferred function may return before calling recover"
+		defer func() {
+			if debug {
+				return
+			}
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeRepanic is reported because the directory's configuration enables
+// detectRepanic
+func UnsafeRepanic() {
+	go func() { // want "recover immediately re-panics"
+		defer func() {
+			panic(recover())
+		}()
+		panic("oh no")
+	}()
+}
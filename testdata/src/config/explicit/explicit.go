@@ -0,0 +1,12 @@
+package explicit
+
+// SafeRepanic is accepted when -detect-repanic=false is given, which takes
+// precedence over the parent directory's configuration
+func SafeRepanic() {
+	go func() {
+		defer func() {
+			panic(recover())
+		}()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,32 @@
+package sub
+
+import "log"
+
+var debug bool
+
+// SafeGuardedRecover is accepted because the nearer configuration disables
+// flagGuardedRecover
+func SafeGuardedRecover() {
+	go func() {
+		defer func() {
+			if debug {
+				return
+			}
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeRepanic is reported because detectRepanic is inherited from the
+// parent directory's configuration
+func UnsafeRepanic() {
+	go func() { // want "recover immediately re-panics"
+		defer func() {
+			panic(recover())
+		}()
+		panic("oh no")
+	}()
+}
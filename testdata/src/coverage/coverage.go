@@ -0,0 +1,34 @@
+package coverage
+
+import (
+	"log"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func recoverPanic() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+func Mixed() {
+	go func() {
+		defer recoverPanic()
+		panic("recovered")
+	}()
+
+	go func() { // want "goroutine created without panic recovery"
+		panic("not recovered")
+	}()
+
+	var g errgroup.Group
+	g.Go(func() error {
+		defer recoverPanic()
+		panic("recovered")
+	})
+	g.Go(func() error { // want "errgroup goroutine created without panic recovery"
+		panic("not recovered")
+	})
+	g.Wait()
+}
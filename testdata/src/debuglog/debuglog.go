@@ -0,0 +1,13 @@
+package debuglog
+
+import "asmlib"
+
+func work() {
+	panic("oh no")
+}
+
+// Start runs functions without panic recovery
+func Start() {
+	go work()        // want "goroutine created without panic recovery"
+	go asmlib.Work() // want "goroutine created without panic recovery"
+}
@@ -0,0 +1,69 @@
+package deep
+
+import "log"
+
+func recovering() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+func risky() {
+	panic("oh no")
+}
+
+// a reaches recovery through b
+func a() { b() }
+
+func b() { recovering() }
+
+// mixed reaches recovery on one path only
+func mixed() {
+	recovering()
+	risky()
+}
+
+// recursive calls itself without recovering
+func recursive(n int) {
+	if n > 0 {
+		recursive(n - 1)
+	}
+}
+
+func level1() { level2() }
+func level2() { level3() }
+func level3() { level4() }
+func level4() { level5() }
+func level5() { level6() }
+func level6() { recovering() }
+
+// SafeTransitiveRecovery recovers two calls deep
+func SafeTransitiveRecovery() {
+	go a()
+	go func() {
+		log.Println("starting")
+		a()
+	}()
+}
+
+// UnsafeOnePathUnprotected has a call path without recovery
+func UnsafeOnePathUnprotected() {
+	go mixed()  // want "goroutine created without panic recovery"
+	go func() { // want "goroutine created without panic recovery"
+		a()
+		risky()
+	}()
+}
+
+// UnsafeRecursion never reaches recovery
+func UnsafeRecursion() {
+	go recursive(3) // want "goroutine created without panic recovery"
+}
+
+// UnsafeBeyondDepthLimit only recovers past the depth limit
+func UnsafeBeyondDepthLimit() {
+	go level1() // want "goroutine created without panic recovery"
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"sync"
+)
+
+func main() {
+	// This should be flagged - detached and unrecovered
+	go func() { // want "detached goroutine in main created without panic recovery or synchronization"
+		panic("This will crash the program")
+	}()
+
+	// Synchronized through a WaitGroup, so only the missing recovery is reported
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() { // want "^goroutine created without panic recovery"
+		defer wg.Done()
+		panic("This will crash the program")
+	}()
+	wg.Wait()
+
+	// Synchronized through a channel argument
+	done := make(chan struct{})
+	go worker(done) // want "^goroutine created without panic recovery"
+	<-done
+
+	// Detached but recovered
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+func worker(done chan struct{}) {
+	close(done)
+	panic("This will crash the program")
+}
+
+// helper is not main, so its goroutines get the regular diagnostic
+func helper() {
+	go func() { // want "^goroutine created without panic recovery"
+		panic("This will crash the program")
+	}()
+}
@@ -0,0 +1,29 @@
+package dotimport
+
+import . "recovercheck/pkg"
+
+// SafeDotImportedRun runs a recovering function from a dot-imported package
+func SafeDotImportedRun() {
+	go Run()
+}
+
+// SafeDotImportedDefer defers a recovery function from a dot-imported package
+func SafeDotImportedDefer() {
+	go func() {
+		defer PanicRecover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeDotImportedRun runs a function without recovery from a dot-imported package
+func UnsafeDotImportedRun() {
+	go RunUnsafe() // want "goroutine created without panic recovery"
+}
+
+// UnsafeDotImportedDefer defers a non-recovering function from a dot-imported package
+func UnsafeDotImportedDefer() {
+	go func() { // want "goroutine created without panic recovery"
+		defer NotRecovering()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,120 @@
+package errgroupstrict
+
+import (
+	"fmt"
+	"log"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// recoverToError stores a recovered panic in *err
+func recoverToError(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic: %v", r)
+	}
+}
+
+// UnsafeLoggedPanic recovers and logs, so Wait never sees the panic
+func UnsafeLoggedPanic() error {
+	var g errgroup.Group
+	g.Go(func() error { // want "errgroup callback recovers but does not return the panic as an error"
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("recovered:", r)
+			}
+		}()
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+// UnsafeNamedResultIgnored names its result but never assigns the panic to it
+func UnsafeNamedResultIgnored() error {
+	var g errgroup.Group
+	g.Go(func() (err error) { // want "errgroup callback recovers but does not return the panic as an error"
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("recovered:", r)
+			}
+		}()
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+// UnsafeResultNotFromPanic assigns an error that doesn't carry the panic
+func UnsafeResultNotFromPanic() error {
+	var g errgroup.Group
+	g.Go(func() (err error) { // want "errgroup callback recovers but does not return the panic as an error"
+		defer func() {
+			recover()
+			err = fmt.Errorf("failed")
+		}()
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+// UnrecoveredStillReported is reported as unrecovered, not as strict
+func UnrecoveredStillReported() error {
+	var g errgroup.Group
+	g.Go(func() error { // want "errgroup goroutine created without panic recovery"
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+// SafeReturnedPanic converts the recovered value into the result
+func SafeReturnedPanic() error {
+	var g errgroup.Group
+	g.Go(func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+// SafeAssignedRecover assigns the recovered value after binding it
+func SafeAssignedRecover() error {
+	var g errgroup.Group
+	g.Go(func() (err error) {
+		defer func() {
+			r := recover()
+			if r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+// SafeHelper hands the result to a deferred helper that fills it in
+func SafeHelper() error {
+	var g errgroup.Group
+	g.Go(func() (err error) {
+		defer recoverToError(&err)
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+// SafeNamedCallback passes a named function, which isn't inspected
+func SafeNamedCallback() error {
+	var g errgroup.Group
+	g.Go(work)
+	return g.Wait()
+}
+
+func work() error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("recovered:", r)
+		}
+	}()
+	panic("oh no")
+}
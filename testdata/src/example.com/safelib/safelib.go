@@ -0,0 +1,25 @@
+// Package safelib stands in for a vendored library that recovers in ways the
+// analyzer can't see. Its recovery is elided here.
+package safelib
+
+// Run runs fn, recovering from a panic
+func Run(fn func()) {
+	fn()
+}
+
+// Go runs fn without recovery
+func Go(fn func()) {
+	fn()
+}
+
+// Recover recovers from a panic when deferred
+func Recover() {}
+
+// Guard recovers from panics on behalf of its owner
+type Guard struct{}
+
+// Recover recovers from a panic when deferred
+func (g *Guard) Recover() {}
+
+// Close releases the guard
+func (g *Guard) Close() {}
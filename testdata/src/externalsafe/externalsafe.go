@@ -0,0 +1,29 @@
+package externalsafe
+
+import "asmlib"
+
+// GoroutineWithUnanalyzableFunction runs a function without a body to analyze
+func GoroutineWithUnanalyzableFunction() {
+	go asmlib.DoWork()
+}
+
+// GoroutineDeferringUnanalyzableFunction defers a function without a body to analyze
+func GoroutineDeferringUnanalyzableFunction() {
+	go func() {
+		defer asmlib.DoWork()
+		panic("oh no")
+	}()
+}
+
+// SafeGoroutineWithExternalRecovery defers an analyzable recovering function
+func SafeGoroutineWithExternalRecovery() {
+	go func() {
+		defer asmlib.Recover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineWithExternalFunction runs an analyzable function without recovery
+func UnsafeGoroutineWithExternalFunction() {
+	go asmlib.Work() // want "goroutine created without panic recovery"
+}
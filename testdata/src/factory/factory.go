@@ -0,0 +1,110 @@
+package factory
+
+import (
+	"log"
+
+	"factory/handlers"
+)
+
+// recoveringWorker returns a worker that recovers from its own panics
+func recoveringWorker() func() {
+	return func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}
+}
+
+// unsafeWorker returns a worker without panic recovery
+func unsafeWorker() func() {
+	return func() {
+		panic("oh no")
+	}
+}
+
+func safeRun() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// namedWorker returns a declared function that recovers
+func namedWorker() func() {
+	return safeRun
+}
+
+// variableWorker returns a recovering function through a local variable
+func variableWorker() func() {
+	worker := func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}
+	return worker
+}
+
+// chosenWorker only recovers on one of its return paths
+func chosenWorker(safe bool) func() {
+	if safe {
+		return safeRun
+	}
+	return func() {
+		panic("oh no")
+	}
+}
+
+// wrappedWorker returns what another factory returns
+func wrappedWorker() func() {
+	return recoveringWorker()
+}
+
+// loopingWorker returns its own result
+func loopingWorker() func() {
+	return loopingWorker()
+}
+
+// namedResultWorker hides the returned worker behind a bare return
+func namedResultWorker() (worker func()) {
+	worker = safeRun
+	return
+}
+
+type pool struct{}
+
+// worker returns a worker that recovers from its own panics
+func (p *pool) worker() func() {
+	return recoveringWorker()
+}
+
+// SafeFactoryCalls run workers that recover
+func SafeFactoryCalls(p *pool) {
+	go recoveringWorker()()
+	go namedWorker()()
+	go variableWorker()()
+	go wrappedWorker()()
+	go (recoveringWorker())()
+	go p.worker()()
+	go handlers.NewWorker()()
+}
+
+// UnsafeFactoryCalls run workers that may panic without recovery
+func UnsafeFactoryCalls(safe bool) {
+	go unsafeWorker()()      // want `^goroutine created without panic recovery$`
+	go chosenWorker(safe)()  // want `^goroutine created without panic recovery$`
+	go loopingWorker()()     // want `^goroutine created without panic recovery$`
+	go namedResultWorker()() // want `^goroutine created without panic recovery$`
+}
+
+// UnresolvedFactoryCall runs a worker returned by a function value
+func UnresolvedFactoryCall(newWorker func() func()) {
+	go newWorker()() // want `^goroutine created without panic recovery \(could not resolve function returned by call\)$`
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import "log"
+
+// NewWorker returns a worker that recovers from its own panics
+func NewWorker() func() {
+	return func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}
+}
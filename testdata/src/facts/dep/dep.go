@@ -0,0 +1,29 @@
+package dep
+
+import "log"
+
+func handlePanic() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+// Run recovers through a deferred helper of this package
+func Run() {
+	defer handlePanic()
+	panic("oh no")
+}
+
+// RunUnsafe has no panic recovery
+func RunUnsafe() {
+	panic("oh no")
+}
+
+// Worker runs jobs
+type Worker struct{}
+
+// Serve recovers through a deferred helper of this package
+func (w *Worker) Serve() {
+	defer handlePanic()
+	panic("oh no")
+}
@@ -0,0 +1,17 @@
+package facts
+
+import "facts/dep"
+
+// SafeImportedRun runs a function whose recovery is only visible with the
+// imported package's type information
+func SafeImportedRun() {
+	go dep.Run()
+
+	w := &dep.Worker{}
+	go w.Serve()
+}
+
+// UnsafeImportedRun runs a function without recovery
+func UnsafeImportedRun() {
+	go dep.RunUnsafe() // want "goroutine created without panic recovery"
+}
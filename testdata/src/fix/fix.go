@@ -0,0 +1,34 @@
+package fix
+
+import (
+	"fmt"
+
+	"pool"
+)
+
+type worker struct{}
+
+func (w *worker) run() {
+	fmt.Println("working")
+}
+
+func process() {
+	fmt.Println("processing")
+}
+
+func idle() {}
+
+// StartWorkers runs named functions and methods of this package
+func StartWorkers() {
+	go process() // want "goroutine created without panic recovery"
+	go idle()    // want "goroutine created without panic recovery"
+
+	w := &worker{}
+	go w.run() // want "goroutine created without panic recovery"
+}
+
+// StartForeign runs a function of another package, which can't be fixed here
+func StartForeign() {
+	var p pool.Pool[int]
+	go p.Go(func() {}) // want "goroutine created without panic recovery"
+}
@@ -0,0 +1,10 @@
+package fix
+
+func tick() {
+	println("tick")
+}
+
+// StartTicker runs a function declared in a file without imports
+func StartTicker() {
+	go tick() // want "goroutine created without panic recovery"
+}
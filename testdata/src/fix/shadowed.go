@@ -0,0 +1,15 @@
+package fix
+
+type logger struct{}
+
+func (logger) Println(...any) {}
+
+// flush takes a parameter named log, so the fix can't refer to the package
+func flush(log logger) {
+	log.Println("flushing")
+}
+
+// StartFlush runs a function whose parameter shadows the log package
+func StartFlush() {
+	go flush(logger{}) // want "goroutine created without panic recovery"
+}
@@ -0,0 +1,76 @@
+package funcfield
+
+import "log"
+
+func recovering() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+func unsafe() {
+	panic("oh no")
+}
+
+type server struct {
+	handler func()
+	cb      func()
+	unsafe  func()
+	dynamic func()
+}
+
+func newServer() *server {
+	return &server{handler: recovering, unsafe: unsafe}
+}
+
+func (s *server) setup() {
+	s.cb = func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}
+}
+
+func (s *server) configure(fns []func()) {
+	for _, fn := range fns {
+		s.dynamic = fn
+	}
+}
+
+// SafeFieldFromCompositeLit runs a field set once in a composite literal
+func SafeFieldFromCompositeLit() {
+	s := newServer()
+	go s.handler()
+}
+
+// SafeFieldFromAssignment runs a field assigned once elsewhere
+func SafeFieldFromAssignment(s *server) {
+	go s.cb()
+}
+
+// UnsafeField runs a field holding a function without recovery
+func UnsafeField(s *server) {
+	go s.unsafe() // want "goroutine created without panic recovery$"
+}
+
+// UnresolvedField runs a field assigned from a value that can't be resolved
+func UnresolvedField(s *server) {
+	go s.dynamic() // want `goroutine created without panic recovery \(could not resolve function value\)`
+}
+
+type positional struct {
+	run func()
+}
+
+var jobs = []positional{{recovering}}
+
+// SafePositionalField runs a field set once in a positional composite literal
+func SafePositionalField() {
+	go jobs[0].run()
+}
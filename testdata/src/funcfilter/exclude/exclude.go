@@ -0,0 +1,42 @@
+package exclude
+
+import "golang.org/x/sync/errgroup"
+
+// Server runs background work
+type Server struct{}
+
+// Start is excluded as a method of Server
+func (s *Server) Start() {
+	go func() {
+		panic("oh no")
+	}()
+}
+
+// Wait is excluded as a method of Server, errgroup callbacks included
+func (s *Server) Wait() error {
+	var g errgroup.Group
+	g.Go(func() error {
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+// Exported is analyzed
+func Exported() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
+
+func unexported() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
+
+// startWorker is excluded by its variable name
+var startWorker = func() {
+	go func() {
+		panic("oh no")
+	}()
+}
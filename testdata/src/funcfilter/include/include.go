@@ -0,0 +1,50 @@
+package include
+
+import "golang.org/x/sync/errgroup"
+
+// Server runs background work
+type Server struct{}
+
+// Start is a method of an exported type, matched as (*funcfilter/include.Server).Start
+func (s *Server) Start() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
+
+// Exported is matched as funcfilter/include.Exported
+func Exported() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
+
+func unexported() {
+	go func() {
+		panic("oh no")
+	}()
+}
+
+var startWorker = func() {
+	go func() {
+		panic("oh no")
+	}()
+}
+
+// Wait is matched as funcfilter/include.Wait, so its errgroup callback is
+// analyzed
+func Wait() error {
+	var g errgroup.Group
+	g.Go(func() error { // want "errgroup goroutine created without panic recovery"
+		panic("oh no")
+	})
+	return g.Wait()
+}
+
+func wait() error {
+	var g errgroup.Group
+	g.Go(func() error {
+		panic("oh no")
+	})
+	return g.Wait()
+}
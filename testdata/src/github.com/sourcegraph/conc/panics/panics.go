@@ -0,0 +1,21 @@
+// Package panics provides a mock implementation for testing purposes
+package panics
+
+// Recovered is a recovered panic
+type Recovered struct {
+	Value any
+}
+
+// Catcher catches the panics of the functions it runs
+type Catcher struct{}
+
+// Try runs f, catching its panic
+func (c *Catcher) Try(f func()) {
+	f()
+}
+
+// Try runs f and returns its recovered panic, if any
+func Try(f func()) *Recovered {
+	f()
+	return nil
+}
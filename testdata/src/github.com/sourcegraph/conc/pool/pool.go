@@ -0,0 +1,14 @@
+package pool
+
+// Pool runs tasks on a bounded number of goroutines
+type Pool struct{}
+
+// New creates a pool
+func New() *Pool {
+	return &Pool{}
+}
+
+// Go runs f in a new goroutine
+func (p *Pool) Go(f func()) {
+	go f()
+}
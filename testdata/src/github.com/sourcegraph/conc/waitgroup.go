@@ -0,0 +1,13 @@
+// Package conc provides a mock implementation for testing purposes
+package conc
+
+// WaitGroup runs goroutines that catch their panics and re-raise them from Wait
+type WaitGroup struct{}
+
+// Go runs f in a new goroutine
+func (wg *WaitGroup) Go(f func()) {
+	go f()
+}
+
+// Wait waits for every goroutine to complete
+func (wg *WaitGroup) Wait() {}
@@ -1,9 +1,16 @@
 // Package errgroup provides a mock implementation for testing purposes
 package errgroup
 
+import "context"
+
 // Group is a mock errgroup.Group for testing
 type Group struct{}
 
+// WithContext returns a new Group and a derived Context
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	return &Group{}, ctx
+}
+
 // Go runs the given function in a new goroutine
 func (g *Group) Go(f func() error) {
 	go f()
@@ -0,0 +1,49 @@
+package guardedrecover
+
+import "log"
+
+var debug bool
+
+// UnsafeGuardedRecover returns before recovering when debug is set
+func UnsafeGuardedRecover() {
+	go func() { // want "goroutine recovery can be skipped: deferred function may return before calling recover"
+		defer func() {
+			if debug {
+				return
+			}
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeRecoverThenReturn returns only after recovering
+func SafeRecoverThenReturn() {
+	go func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			log.Println("Recovered from panic:", r)
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeGuardWithoutReturn has a conditional that can't skip the recover
+func SafeGuardWithoutReturn() {
+	go func() {
+		defer func() {
+			if debug {
+				log.Println("recovering")
+			}
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
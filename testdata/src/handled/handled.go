@@ -0,0 +1,144 @@
+package handled
+
+import "log"
+
+func logPanic() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+func swallowPanic() {
+	recover()
+}
+
+// SafeLoggedRecover logs the recovered value
+func SafeLoggedRecover() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeRecoverPassedToCall hands the recovered value straight to a logger
+func SafeRecoverPassedToCall() {
+	go func() {
+		defer func() {
+			log.Println("Recovered from panic:", recover())
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeAssignedRecover stores the recovered value
+func SafeAssignedRecover(errs chan<- any) {
+	go func() {
+		defer func() {
+			r := recover()
+			errs <- r
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeNamedHandler defers a handler that logs the recovered value
+func SafeNamedHandler() {
+	go func() {
+		defer logPanic()
+		panic("oh no")
+	}()
+}
+
+// UnsafeBareRecover swallows the panic
+func UnsafeBareRecover() {
+	go func() { // want "goroutine recovery discards the recovered value"
+		defer func() {
+			recover()
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeEmptyNilCheck only compares the recovered value to nil
+func UnsafeEmptyNilCheck() {
+	go func() { // want "goroutine recovery discards the recovered value"
+		defer func() {
+			if r := recover(); r != nil {
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeDeferRecover defers recover itself
+func UnsafeDeferRecover() {
+	go func() { // want "goroutine recovery discards the recovered value"
+		defer recover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeSwallowingHandler defers a handler that discards the recovered value
+func UnsafeSwallowingHandler() {
+	go func() { // want "goroutine created without panic recovery"
+		defer swallowPanic()
+		panic("oh no")
+	}()
+}
+
+// SafeTypeSwitch tells errors from other panics
+func SafeTypeSwitch() {
+	go func() {
+		defer func() {
+			switch r := recover().(type) {
+			case nil:
+			case error:
+				log.Println("Recovered from error:", r)
+			default:
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeUnboundTypeSwitch handles panics by type without binding the value
+func SafeUnboundTypeSwitch() {
+	go func() {
+		defer func() {
+			switch recover().(type) {
+			case error:
+				log.Println("Recovered from an error")
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeTypeAssertion logs the recovered value when it is an error
+func SafeTypeAssertion() {
+	go func() {
+		defer func() {
+			if err, ok := recover().(error); ok {
+				log.Println("Recovered from error:", err)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeEmptyTypeSwitch switches on the recovered value but does nothing
+func UnsafeEmptyTypeSwitch() {
+	go func() { // want "goroutine recovery discards the recovered value"
+		defer func() {
+			switch recover().(type) {
+			case error:
+			}
+		}()
+		panic("oh no")
+	}()
+}
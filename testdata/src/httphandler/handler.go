@@ -0,0 +1,40 @@
+package httphandler
+
+import (
+	"log"
+	"net/http"
+)
+
+func backgroundWork() {
+	panic("oh no")
+}
+
+// Handler launches unrecovered work from a request handler
+func Handler(w http.ResponseWriter, r *http.Request) {
+	go backgroundWork() // want "goroutine created without panic recovery in HTTP handler"
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// SafeHandler recovers inside the background goroutine
+func SafeHandler(w http.ResponseWriter, r *http.Request) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		backgroundWork()
+	}()
+}
+
+// Register uses a handler function literal
+func Register(mux *http.ServeMux) {
+	mux.HandleFunc("/work", func(w http.ResponseWriter, r *http.Request) {
+		go backgroundWork() // want "goroutine created without panic recovery in HTTP handler"
+	})
+}
+
+// NotAHandler has a different signature, so the regular message is used
+func NotAHandler(w http.ResponseWriter) {
+	go backgroundWork() // want "^goroutine created without panic recovery$"
+}
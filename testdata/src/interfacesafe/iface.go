@@ -0,0 +1,34 @@
+package interfacesafe
+
+// Recoverer is implemented by types that can recover from panics
+type Recoverer interface {
+	Recover()
+}
+
+// embeddedRecoverer gets its Recover method from an embedded interface
+type embeddedRecoverer struct {
+	Recoverer
+}
+
+// SafeGoroutineWithEmbeddedInterfaceRecover trusts the embedded interface method
+func SafeGoroutineWithEmbeddedInterfaceRecover(h embeddedRecoverer) {
+	go func() {
+		defer h.Recover()
+		panic("oh no")
+	}()
+}
+
+// SafeGoroutineWithInterfaceRecover trusts the interface method
+func SafeGoroutineWithInterfaceRecover(r Recoverer) {
+	go func() {
+		defer r.Recover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutine has no recovery at all
+func UnsafeGoroutine() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,65 @@
+package loops
+
+import "log"
+
+func process(job string) {
+	if job == "" {
+		panic("empty job")
+	}
+}
+
+func safeProcess(job string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	process(job)
+}
+
+// UnsafeRangeLoop starts an unrecovered goroutine per job
+func UnsafeRangeLoop(jobs []string) {
+	for _, job := range jobs {
+		go process(job) // want `goroutine created without panic recovery \(inside loop — panic will crash process on any iteration\)`
+	}
+}
+
+// UnsafeForLoop starts an unrecovered goroutine per iteration
+func UnsafeForLoop(jobs []string) {
+	for i := 0; i < len(jobs); i++ {
+		go func() { // want `goroutine created without panic recovery \(inside loop — panic will crash process on any iteration\)`
+			process(jobs[i])
+		}()
+	}
+}
+
+// UnsafeNestedBlock starts an unrecovered goroutine inside a block of the loop body
+func UnsafeNestedBlock(jobs []string) {
+	for _, job := range jobs {
+		if job != "" {
+			go process(job) // want `goroutine created without panic recovery \(inside loop — panic will crash process on any iteration\)`
+		}
+	}
+}
+
+// SafeRangeLoop recovers in every goroutine it starts
+func SafeRangeLoop(jobs []string) {
+	for _, job := range jobs {
+		go safeProcess(job)
+	}
+}
+
+// UnsafeOutsideLoop starts a single unrecovered goroutine
+func UnsafeOutsideLoop(job string) {
+	go process(job) // want `goroutine created without panic recovery$`
+}
+
+// UnsafeInRangeExpression starts one goroutine while computing the range
+// expression, which is evaluated once
+func UnsafeInRangeExpression(jobs []string) {
+	for range func() []string {
+		go process("start") // want `goroutine created without panic recovery$`
+		return jobs
+	}() {
+	}
+}
@@ -0,0 +1,21 @@
+package maxfindings
+
+func work() {
+	panic("oh no")
+}
+
+// Start runs more unrecovered goroutines than the cap of 2 allows
+func Start() {
+	go work() // want "goroutine created without panic recovery"
+	go work() // want "goroutine created without panic recovery"
+	go work() // want `additional findings truncated \(2 reached\)`
+	go work()
+}
+
+// StartMore runs unrecovered goroutines once the cap is reached
+func StartMore() {
+	go work()
+	go func() {
+		work()
+	}()
+}
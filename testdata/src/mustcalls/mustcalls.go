@@ -0,0 +1,42 @@
+package mustcalls
+
+import (
+	"regexp"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func mustLoad(path string) string {
+	if path == "" {
+		panic("empty path")
+	}
+	return path
+}
+
+func process(s string) {}
+
+// UnsafeMustCompile panics if the pattern is invalid
+func UnsafeMustCompile(pattern string) {
+	go func() { // want "goroutine created without panic recovery"
+		process("start")
+		re := regexp.MustCompile(pattern)
+		process(re.String())
+	}()
+}
+
+// UnsafeLocalMust panics if the path is empty
+func UnsafeLocalMust(path string) {
+	var g errgroup.Group
+	g.Go(func() error { // want "errgroup goroutine created without panic recovery"
+		process(mustLoad(path))
+		return nil
+	})
+	g.Wait()
+}
+
+// UnsafeWithoutMust has no Must-style calls to point at
+func UnsafeWithoutMust() {
+	go func() { // want "goroutine created without panic recovery"
+		process("mustard")
+	}()
+}
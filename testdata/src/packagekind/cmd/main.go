@@ -0,0 +1,17 @@
+package main
+
+func work() {
+	panic("oh no")
+}
+
+func main() {
+	go work()
+	go func() {
+		work()
+	}()
+	start()
+}
+
+func start() {
+	go work()
+}
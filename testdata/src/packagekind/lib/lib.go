@@ -0,0 +1,17 @@
+package lib
+
+func work() {
+	panic("oh no")
+}
+
+// Notify signals done without anything that can panic
+func Notify(done chan<- struct{}) {
+	go func() {
+		done <- struct{}{}
+	}()
+}
+
+// Start runs work without recovery
+func Start() {
+	go work()
+}
@@ -0,0 +1,28 @@
+package pkgvar
+
+import "log"
+
+func background() {
+	panic("oh no")
+}
+
+// Handler launches a goroutine whenever it is called
+var Handler = func() {
+	go background() // want "goroutine created without panic recovery"
+}
+
+// SafeHandler launches a recovering goroutine whenever it is called
+var SafeHandler = func() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		background()
+	}()
+}
+
+var first, second = func() {}, func() {
+	go background() // want "goroutine created without panic recovery"
+}
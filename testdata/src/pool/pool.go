@@ -0,0 +1,14 @@
+package pool
+
+// Pool runs tasks on worker goroutines
+type Pool[T any] struct{}
+
+// Go runs fn in a new goroutine
+func (p *Pool[T]) Go(fn func()) {
+	go fn()
+}
+
+// Go runs fn with v in a new goroutine
+func Go[T any](v T, fn func(T)) {
+	go fn(v)
+}
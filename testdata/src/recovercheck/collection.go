@@ -0,0 +1,97 @@
+package recovercheck
+
+import "log"
+
+func recoveringJobA() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	risky()
+}
+
+func recoveringJobB() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	risky()
+}
+
+// SafeGoroutinesFromFuncSlice ranges over a slice of recovering functions
+func SafeGoroutinesFromFuncSlice() {
+	tasks := []func(){recoveringJobA, recoveringJobB}
+	for _, task := range tasks {
+		go task()
+	}
+}
+
+// SafeGoroutinesFromLiteralInRange ranges over a literal written in place
+func SafeGoroutinesFromLiteralInRange() {
+	for _, task := range []func(){recoveringJobA, func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		risky()
+	}} {
+		go task()
+	}
+}
+
+// SafeGoroutinesFromFuncMapByIndex indexes a map of recovering functions
+func SafeGoroutinesFromFuncMapByIndex(name string) {
+	tasks := map[string]func(){
+		"first":  recoveringJobA,
+		"second": recoveringJobB,
+	}
+	go tasks[name]()
+}
+
+// UnsafeGoroutinesFromMixedFuncSlice ranges over a slice where one function doesn't recover
+func UnsafeGoroutinesFromMixedFuncSlice() {
+	tasks := []func(){recoveringJobA, risky, recoveringJobB}
+	for _, task := range tasks {
+		go task() // want `goroutine created without panic recovery \(may run risky, which has no recovery\)`
+	}
+}
+
+// UnsafeGoroutinesFromMixedLiterals ranges over an array holding a literal without recovery
+func UnsafeGoroutinesFromMixedLiterals() {
+	tasks := [2]func(){
+		recoveringJobA,
+		func() { risky() },
+	}
+	for i := range tasks {
+		go tasks[i]() // want `goroutine created without panic recovery \(may run function literal on line 66, which has no recovery\)`
+	}
+}
+
+// UnsafeGoroutinesFromModifiedSlice ranges over a slice whose element is replaced
+func UnsafeGoroutinesFromModifiedSlice() {
+	tasks := []func(){recoveringJobA}
+	tasks[0] = risky
+	for _, task := range tasks {
+		go task() // want "goroutine created without panic recovery"
+	}
+}
+
+// UnsafeGoroutinesFromReassignedRangeVariable replaces the range variable in the loop
+func UnsafeGoroutinesFromReassignedRangeVariable(fallback func()) {
+	for _, task := range []func(){recoveringJobA} {
+		if fallback != nil {
+			task = fallback
+		}
+		go task() // want "goroutine created without panic recovery"
+	}
+}
+
+// UnsafeGoroutinesFromFuncSliceParameter ranges over functions passed in by the caller
+func UnsafeGoroutinesFromFuncSliceParameter(tasks []func()) {
+	for _, task := range tasks {
+		go task() // want "goroutine created without panic recovery"
+	}
+}
@@ -0,0 +1,77 @@
+package recovercheck
+
+import "log"
+
+// SafeGoroutineDeferringLocalClosure stores its recovery in a variable before deferring it
+func SafeGoroutineDeferringLocalClosure() {
+	go func() {
+		f := func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}
+		defer f()
+		panic("oh no")
+	}()
+}
+
+// SafeGoroutineDeferringDeclaredClosure declares its recovery variable with var
+func SafeGoroutineDeferringDeclaredClosure() {
+	go func() {
+		var cleanup = func() {
+			recover()
+		}
+		defer cleanup()
+		panic("oh no")
+	}()
+}
+
+// SafeGoroutineDeferringOuterClosure defers a closure declared outside the goroutine
+func SafeGoroutineDeferringOuterClosure() {
+	handle := func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}
+	go func() {
+		defer handle()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineDeferringLocalClosure defers a stored closure that never recovers
+func UnsafeGoroutineDeferringLocalClosure() {
+	go func() { // want "goroutine created without panic recovery"
+		f := func() {
+			log.Println("done")
+		}
+		defer f()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineDeferringClosureCallingRecoverIndirectly defers a closure whose
+// recover() runs in a nested call, where it can't stop the panic
+func UnsafeGoroutineDeferringClosureCallingRecoverIndirectly() {
+	go func() { // want "goroutine created without panic recovery"
+		f := func() {
+			func() {
+				recover()
+			}()
+		}
+		defer f()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineDeferringReassignedClosure defers a variable reassigned to a non-recovering closure
+func UnsafeGoroutineDeferringReassignedClosure() {
+	go func() { // want "goroutine created without panic recovery"
+		f := func() {
+			recover()
+		}
+		f = func() {}
+		defer f()
+		panic("oh no")
+	}()
+}
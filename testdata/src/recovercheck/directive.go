@@ -0,0 +1,35 @@
+package recovercheck
+
+// Worker runs units of work
+type Worker interface {
+	// Run recovers from panics in the work it runs.
+	//recovercheck:safe
+	Run()
+
+	// Process gives no recovery guarantee
+	Process()
+}
+
+// Guard recovers from panics when deferred
+type Guard interface {
+	Recover() //recovercheck:safe
+}
+
+// SafeGoroutineCallingSafeInterfaceMethod runs a method documented to recover
+func SafeGoroutineCallingSafeInterfaceMethod(w Worker) {
+	go w.Run()
+}
+
+// UnsafeGoroutineCallingInterfaceMethod runs a method without a recovery
+// contract, whose implementation is only known at run time
+func UnsafeGoroutineCallingInterfaceMethod(w Worker) {
+	go w.Process() // want "goroutine target could not be analyzed; ensure recovery"
+}
+
+// SafeGoroutineDeferringSafeInterfaceMethod defers a method documented to recover
+func SafeGoroutineDeferringSafeInterfaceMethod(g Guard) {
+	go func() {
+		defer g.Recover()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,104 @@
+package recovercheck
+
+import (
+	"log"
+	"reflect"
+)
+
+// UnsafeGoroutineFromFuncMap runs a handler looked up by name in a map of
+// functions filled in at run time
+func UnsafeGoroutineFromFuncMap(name string) {
+	handlers := make(map[string]func())
+	handlers["risky"] = risky
+	go handlers[name]() // want "goroutine target could not be analyzed; ensure recovery"
+}
+
+// UnsafeGoroutineFromFuncMapWithArgs passes arguments to a function of a map
+func UnsafeGoroutineFromFuncMapWithArgs(handlers map[string]func(int), name string) {
+	go (handlers[name])(1) // want "goroutine target could not be analyzed; ensure recovery"
+}
+
+// UnsafeGoroutineWithReflectCall runs a function through reflection
+func UnsafeGoroutineWithReflectCall(fn any) {
+	v := reflect.ValueOf(fn)
+	go v.Call(nil)      // want "goroutine target could not be analyzed; ensure recovery"
+	go v.CallSlice(nil) // want "goroutine target could not be analyzed; ensure recovery"
+}
+
+// UnsafeGoroutineWithReflectMethod runs another method of reflect.Value, which is analyzed as usual
+func UnsafeGoroutineWithReflectMethod(v reflect.Value) {
+	go v.SetZero() // want "goroutine created without panic recovery"
+}
+
+// SafeGoroutineRecoveringFuncMap recovers around a function of a map
+func SafeGoroutineRecoveringFuncMap(handlers map[string]func(), name string) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		handlers[name]()
+	}()
+}
+
+// Handler is implemented by handlers with and without recovery
+type Handler interface {
+	Handle()
+}
+
+type recoveringHandler struct{}
+
+func (recoveringHandler) Handle() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	risky()
+}
+
+type plainHandler struct {
+	name string
+}
+
+func (h *plainHandler) Handle() {
+	risky()
+}
+
+// SafeGoroutineWithKnownDynamicType calls an interface method on a variable
+// assigned once from a literal of a type whose method recovers
+func SafeGoroutineWithKnownDynamicType() {
+	var h Handler = recoveringHandler{}
+	go h.Handle()
+}
+
+// SafeGoroutineWithConvertedLiteral converts the literal to the interface
+func SafeGoroutineWithConvertedLiteral() {
+	h := Handler(recoveringHandler{})
+	go h.Handle()
+}
+
+// UnsafeGoroutineWithKnownDynamicType calls an interface method whose
+// dynamic type is known but doesn't recover
+func UnsafeGoroutineWithKnownDynamicType() {
+	var h Handler = &plainHandler{name: "plain"}
+	go h.Handle() // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineWithReassignedInterface reassigns the variable, so its
+// dynamic type is indeterminate
+func UnsafeGoroutineWithReassignedInterface(plain bool) {
+	var h Handler = recoveringHandler{}
+	if plain {
+		h = &plainHandler{}
+	}
+	go h.Handle() // want "goroutine target could not be analyzed; ensure recovery"
+}
+
+// UnsafeGoroutineWithInterfaceFromCall assigns the variable from a call,
+// whose dynamic type is indeterminate
+func UnsafeGoroutineWithInterfaceFromCall(newHandler func() Handler) {
+	h := newHandler()
+	go h.Handle() // want "goroutine target could not be analyzed; ensure recovery"
+}
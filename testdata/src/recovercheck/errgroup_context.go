@@ -0,0 +1,43 @@
+package recovercheck
+
+import (
+	"context"
+	"log"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// UnsafeErrgroupWithContext gets its group from errgroup.WithContext
+func UnsafeErrgroupWithContext(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error { // want "errgroup goroutine created without panic recovery"
+		<-ctx.Done()
+		panic("This will crash the program")
+	})
+	return g.Wait()
+}
+
+// SafeErrgroupWithContext recovers in the function passed to a group from
+// errgroup.WithContext
+func SafeErrgroupWithContext(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	return g.Wait()
+}
+
+// UnsafeErrgroupWithContextAssigned assigns the group from
+// errgroup.WithContext to a declared variable
+func UnsafeErrgroupWithContextAssigned(ctx context.Context) error {
+	var g *errgroup.Group
+	g, _ = errgroup.WithContext(ctx)
+	g.Go(unsafeTask) // want "errgroup goroutine created without panic recovery"
+	return g.Wait()
+}
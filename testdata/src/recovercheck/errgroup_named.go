@@ -0,0 +1,61 @@
+package recovercheck
+
+import (
+	"log"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// recoveringTask recovers from its own panics
+func recoveringTask() error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// unsafeTask has no panic recovery
+func unsafeTask() error {
+	panic("oh no")
+}
+
+// taskRunner provides tasks as methods
+type taskRunner struct{}
+
+// Safe recovers from its own panics
+func (taskRunner) Safe() error {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// Unsafe has no panic recovery
+func (taskRunner) Unsafe() error {
+	panic("oh no")
+}
+
+// ErrgroupWithNamedFunctions passes named functions to Go
+func ErrgroupWithNamedFunctions() {
+	var g errgroup.Group
+
+	g.Go(recoveringTask)
+	g.Go(unsafeTask) // want "errgroup goroutine created without panic recovery"
+
+	g.Wait()
+}
+
+// ErrgroupWithMethodValues passes method values to Go
+func ErrgroupWithMethodValues() {
+	var g errgroup.Group
+	var t taskRunner
+
+	g.Go(t.Safe)
+	g.Go(t.Unsafe) // want "errgroup goroutine created without panic recovery"
+
+	g.Wait()
+}
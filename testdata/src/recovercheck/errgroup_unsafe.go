@@ -44,3 +44,33 @@ func UnsafeErrgroupWithAlias() {
 
 	g.Wait()
 }
+
+// runner has a Go method that has nothing to do with errgroup
+type runner struct{}
+
+// Go runs f synchronously
+func (runner) Go(f func() error) {
+	_ = f()
+}
+
+// CustomGoMethod calls an unrelated Go method, which must not be reported
+func CustomGoMethod() {
+	var r runner
+	r.Go(func() error {
+		panic("runs on the calling goroutine")
+	})
+}
+
+// embeddedGroup gets its Go method from an embedded errgroup.Group
+type embeddedGroup struct {
+	errgroup.Group
+}
+
+// UnsafeEmbeddedErrgroup calls Go promoted from an embedded errgroup.Group
+func UnsafeEmbeddedErrgroup() {
+	var g embeddedGroup
+	g.Go(func() error { // want "errgroup goroutine created without panic recovery"
+		panic("This will crash the program")
+	})
+	g.Wait()
+}
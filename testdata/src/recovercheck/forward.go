@@ -0,0 +1,52 @@
+package recovercheck
+
+import "log"
+
+// SafeGoroutineWithLaterFunction runs a recovering function declared below it
+func SafeGoroutineWithLaterFunction() {
+	go laterRecoveringFunc()
+}
+
+// SafeGoroutineDeferringLaterHandler defers a handler declared below it
+func SafeGoroutineDeferringLaterHandler() {
+	go func() {
+		defer laterHandler()
+		panic("oh no")
+	}()
+}
+
+// SafeGoroutineWithLaterMethod runs a recovering method of a type declared below it
+func SafeGoroutineWithLaterMethod(w *laterWorker) {
+	go w.run()
+}
+
+// UnsafeGoroutineWithLaterFunction runs a function declared below it without recovery
+func UnsafeGoroutineWithLaterFunction() {
+	go laterPlainFunc() // want "goroutine created without panic recovery"
+}
+
+func laterRecoveringFunc() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+func laterHandler() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+func laterPlainFunc() {
+	panic("oh no")
+}
+
+type laterWorker struct{}
+
+func (w *laterWorker) run() {
+	defer laterHandler()
+	panic("oh no")
+}
@@ -0,0 +1,50 @@
+package recovercheck
+
+import "log"
+
+// recoveringWorker is a goroutine body that recovers from its own panics
+func recoveringWorker() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// unsafeWorker is a goroutine body without panic recovery
+func unsafeWorker() {
+	panic("oh no")
+}
+
+// SafeGoroutineViaFuncVar launches a recovering function through a local variable
+func SafeGoroutineViaFuncVar() {
+	fn := recoveringWorker
+	go fn()
+}
+
+// SafeGoroutineViaFuncLitVar launches a recovering function literal through a local variable
+func SafeGoroutineViaFuncLitVar() {
+	var fn = func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}
+	go fn()
+}
+
+// UnsafeGoroutineViaFuncVar launches a non-recovering function through a local variable
+func UnsafeGoroutineViaFuncVar() {
+	fn := unsafeWorker
+	go fn() // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineViaReassignedFuncVar cannot be resolved because the variable is reassigned
+func UnsafeGoroutineViaReassignedFuncVar() {
+	fn := recoveringWorker
+	fn = unsafeWorker
+	go fn() // want "goroutine created without panic recovery"
+}
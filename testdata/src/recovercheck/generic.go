@@ -0,0 +1,71 @@
+package recovercheck
+
+import (
+	"log"
+
+	"recovercheck/pkg"
+)
+
+func recoveringGenericWorker[T any]() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	var zero T
+	panic(zero)
+}
+
+func plainGenericWorker[T any]() {
+	var zero T
+	panic(zero)
+}
+
+func recoveringPairGenericWorker[K comparable, V any](k K, v V) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic(map[K]V{k: v})
+}
+
+func plainPairGenericWorker[K comparable, V any](k K, v V) {
+	panic(map[K]V{k: v})
+}
+
+// SafeGenericWorker starts a recovering generic worker instantiated with
+// the enclosing function's type parameter
+func SafeGenericWorker[T any](items []T) {
+	for range items {
+		go recoveringGenericWorker[T]()
+	}
+}
+
+// UnsafeGenericWorker starts a generic worker without recovery
+func UnsafeGenericWorker[T any](items []T) {
+	for range items {
+		go plainGenericWorker[T]() // want "goroutine created without panic recovery"
+	}
+}
+
+// SafeGenericPairWorker instantiates a worker with several type arguments
+func SafeGenericPairWorker[K comparable, V any](m map[K]V) {
+	for k, v := range m {
+		go recoveringPairGenericWorker[K, V](k, v)
+	}
+}
+
+// UnsafeGenericPairWorker instantiates a worker without recovery with
+// several type arguments
+func UnsafeGenericPairWorker[K comparable, V any](m map[K]V) {
+	for k, v := range m {
+		go plainPairGenericWorker[K, V](k, v) // want "goroutine created without panic recovery"
+	}
+}
+
+// SafeGenericWorkerOfAnotherPackage instantiates a recovering generic
+// function of another package
+func SafeGenericWorkerOfAnotherPackage[T any](v T) {
+	go pkg.RunGeneric[T](v)
+}
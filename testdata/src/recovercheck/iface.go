@@ -0,0 +1,20 @@
+package recovercheck
+
+// Recoverer is implemented by types that can recover from panics
+type Recoverer interface {
+	Recover()
+}
+
+// embeddedRecoverer gets its Recover method from an embedded interface
+type embeddedRecoverer struct {
+	Recoverer
+}
+
+// UnsafeGoroutineWithEmbeddedInterfaceRecover defers a method that has no body
+// to inspect, so it is assumed unsafe by default
+func UnsafeGoroutineWithEmbeddedInterfaceRecover(h embeddedRecoverer) {
+	go func() { // want "goroutine created without panic recovery"
+		defer h.Recover()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,46 @@
+package recovercheck
+
+import "log"
+
+func init() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// startWorker starts a goroutine from a package-level variable's initializer
+var startWorker = func() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
+
+// startSafeWorker starts a recovered goroutine from a package-level variable's initializer
+var startSafeWorker = func() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// workers holds a goroutine-starting function in a composite literal
+var workers = map[string]func(){
+	"unsafe": func() {
+		go func() { // want "goroutine created without panic recovery"
+			panic("oh no")
+		}()
+	},
+}
@@ -0,0 +1,64 @@
+package recovercheck
+
+import "log"
+
+// recoveringHelper recovers only from its own panics
+func recoveringHelper() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	risky()
+}
+
+// workCallingRecoveringHelper panics after a helper that recovers for itself
+func workCallingRecoveringHelper() {
+	recoveringHelper()
+	panic("oh no")
+}
+
+// workWithInnerClosure declares a closure calling recover but never defers it
+func workWithInnerClosure() {
+	handle := func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}
+	handle()
+	panic("oh no")
+}
+
+// workCallingClosureImmediately calls a recovering closure without deferring it
+func workCallingClosureImmediately() {
+	func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// UnsafeGoroutineCallingRecoveringHelper runs a function whose own panics aren't recovered
+func UnsafeGoroutineCallingRecoveringHelper() {
+	go workCallingRecoveringHelper() // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineWithInnerClosure runs a function whose recovering closure isn't deferred
+func UnsafeGoroutineWithInnerClosure() {
+	go workWithInnerClosure() // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineCallingClosureImmediately runs a function that calls a recovering closure directly
+func UnsafeGoroutineCallingClosureImmediately() {
+	go workCallingClosureImmediately() // want "goroutine created without panic recovery"
+}
+
+// UnsafeLiteralWithInnerClosure starts a literal whose recovering closure isn't deferred
+func UnsafeLiteralWithInnerClosure() {
+	go func() { // want "goroutine created without panic recovery"
+		handle := func() { recover() }
+		handle()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,65 @@
+package recovercheck
+
+import (
+	"log"
+	"sync"
+)
+
+// UnsafeGoroutineWithDeferredUnlock releases a lock but never recovers
+func UnsafeGoroutineWithDeferredUnlock() {
+	var mu sync.Mutex
+	go func() { // want "goroutine created without panic recovery"
+		mu.Lock()
+		defer mu.Unlock()
+		panic("x")
+	}()
+}
+
+// UnsafeGoroutineWithDeferredRUnlock releases a read lock but never recovers
+func UnsafeGoroutineWithDeferredRUnlock() {
+	var mu sync.RWMutex
+	go func() { // want "goroutine created without panic recovery"
+		mu.RLock()
+		defer mu.RUnlock()
+		panic("x")
+	}()
+}
+
+// UnsafeGoroutineWithDeferredLockerUnlock releases a lock through the sync.Locker interface
+func UnsafeGoroutineWithDeferredLockerUnlock(mu sync.Locker) {
+	go func() { // want "goroutine created without panic recovery"
+		mu.Lock()
+		defer mu.Unlock()
+		panic("x")
+	}()
+}
+
+// SafeGoroutineWithDeferredUnlock releases a lock after recovering
+func SafeGoroutineWithDeferredUnlock() {
+	var mu sync.Mutex
+	go func() {
+		mu.Lock()
+		defer mu.Unlock()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("x")
+	}()
+}
+
+// SafeGoroutineRecoveringBeforeUnlock recovers in a defer registered before the unlock
+func SafeGoroutineRecoveringBeforeUnlock() {
+	var mu sync.Mutex
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		mu.Lock()
+		defer mu.Unlock()
+		panic("x")
+	}()
+}
@@ -0,0 +1,35 @@
+package recovercheck
+
+import "log"
+
+// SafeGoroutineWithDeferInLoop registers its recovery inside a loop body.
+// Deferred calls run when the surrounding function returns, not at the end
+// of the loop iteration, so the panic below is recovered.
+func SafeGoroutineWithDeferInLoop() {
+	go func() {
+		for i := 0; i < 1; i++ {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Println("Recovered from panic:", r)
+				}
+			}()
+		}
+		panic("oh no")
+	}()
+}
+
+// SafeGoroutineWithDeferInBlock registers its recovery inside an explicit
+// block. Go has no block-scoped defer: this also runs when the function
+// returns, so the panic below is recovered too.
+func SafeGoroutineWithDeferInBlock() {
+	go func() {
+		{
+			defer func() {
+				if r := recover(); r != nil {
+					log.Println("Recovered from panic:", r)
+				}
+			}()
+		}
+		panic("oh no")
+	}()
+}
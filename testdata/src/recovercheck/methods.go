@@ -0,0 +1,65 @@
+package recovercheck
+
+import "log"
+
+type server struct{}
+
+// Serve recovers from its own panics
+func (s *server) Serve() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// ServeUnsafe has no panic recovery
+func (s *server) ServeUnsafe() {
+	panic("oh no")
+}
+
+type job struct{}
+
+// Run recovers from its own panics
+func (j job) Run() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// RunUnsafe has no panic recovery
+func (j job) RunUnsafe() {
+	panic("oh no")
+}
+
+// SafeGoroutinePointerReceiver calls recovering pointer-receiver methods
+func SafeGoroutinePointerReceiver() {
+	s := &server{}
+	go s.Serve()
+	go (&server{}).Serve()
+}
+
+// SafeGoroutineValueReceiver calls recovering value-receiver methods
+func SafeGoroutineValueReceiver() {
+	var j job
+	go j.Run()
+	go job{}.Run()
+}
+
+// UnsafeGoroutinePointerReceiver calls pointer-receiver methods without recovery
+func UnsafeGoroutinePointerReceiver() {
+	s := &server{}
+	go s.ServeUnsafe()           // want "goroutine created without panic recovery"
+	go (&server{}).ServeUnsafe() // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineValueReceiver calls value-receiver methods without recovery
+func UnsafeGoroutineValueReceiver() {
+	var j job
+	go j.RunUnsafe()     // want "goroutine created without panic recovery"
+	go job{}.RunUnsafe() // want "goroutine created without panic recovery"
+}
@@ -0,0 +1,71 @@
+package recovercheck
+
+import "log"
+
+func releaseResources() {}
+
+func logRecovered(prefix string, values ...interface{}) {
+	if r := recover(); r != nil {
+		log.Println(append([]interface{}{prefix, r}, values...)...)
+	}
+}
+
+// SafeSecondDeferRecovers recovers in its second deferred function only
+func SafeSecondDeferRecovers() {
+	go func() {
+		defer releaseResources()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeLastOfSeveralDefers recovers in the last of several deferred calls
+func SafeLastOfSeveralDefers() {
+	go func() {
+		defer releaseResources()
+		defer func() {
+			releaseResources()
+		}()
+		defer releaseResources()
+		defer logRecovered("worker")
+		panic("oh no")
+	}()
+}
+
+// SafeVariadicDeferredRecovery defers a variadic recovery function with arguments
+func SafeVariadicDeferredRecovery() {
+	values := []interface{}{1, 2}
+	go func() {
+		defer releaseResources()
+		defer logRecovered("worker", values...)
+		panic("oh no")
+	}()
+}
+
+// SafeVariadicDeferredLiteral defers a variadic function literal that recovers
+func SafeVariadicDeferredLiteral() {
+	go func() {
+		defer releaseResources()
+		defer func(values ...interface{}) {
+			if r := recover(); r != nil {
+				log.Println(append(values, r)...)
+			}
+		}(1, 2)
+		panic("oh no")
+	}()
+}
+
+// UnsafeSeveralDefersWithoutRecovery defers several functions, none of which recover
+func UnsafeSeveralDefersWithoutRecovery() {
+	go func() { // want "goroutine created without panic recovery"
+		defer releaseResources()
+		defer func() {
+			releaseResources()
+		}()
+		panic("oh no")
+	}()
+}
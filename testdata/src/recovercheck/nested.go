@@ -0,0 +1,76 @@
+package recovercheck
+
+import "log"
+
+// UnsafeNestedGoroutines has two levels of unrecovered nesting
+func UnsafeNestedGoroutines() {
+	go func() { // want "^goroutine created without panic recovery"
+		go func() { // want "nested goroutine created without panic recovery inside an unrecovered goroutine"
+			go func() { // want "nested goroutine created without panic recovery inside an unrecovered goroutine"
+				panic("innermost")
+			}()
+			panic("inner")
+		}()
+		panic("outer")
+	}()
+}
+
+// SafeOuterUnsafeInnerGoroutine recovers in the outer goroutine only
+func SafeOuterUnsafeInnerGoroutine() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		go func() { // want "^goroutine created without panic recovery"
+			panic("inner")
+		}()
+		panic("outer")
+	}()
+}
+
+// UnsafeOuterSafeInnerGoroutine recovers in the inner goroutine only, which
+// doesn't protect the outer one
+func UnsafeOuterSafeInnerGoroutine() {
+	go func() { // want "^goroutine created without panic recovery"
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Println("Recovered from panic:", r)
+				}
+			}()
+			panic("inner")
+		}()
+		panic("outer")
+	}()
+}
+
+// UnsafeRecoverInUndeferredLiteral calls recover() from a function literal
+// that is never deferred
+func UnsafeRecoverInUndeferredLiteral() {
+	go func() { // want "^goroutine created without panic recovery"
+		logPanic := func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}
+		_ = logPanic
+		panic("oh no")
+	}()
+}
+
+// UnsafeRecoverInNestedLiteral calls recover() from a literal inside the
+// deferred function rather than from the deferred function itself
+func UnsafeRecoverInNestedLiteral() {
+	go func() { // want "^goroutine created without panic recovery"
+		defer func() {
+			func() {
+				if r := recover(); r != nil {
+					log.Println("Recovered from panic:", r)
+				}
+			}()
+		}()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,83 @@
+package recovercheck
+
+import (
+	"log"
+	"sync"
+)
+
+func recoveringWork() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+func recoveringCompute() int {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// SafeGoroutineWithOnceFunc runs a once-wrapped function that recovers
+func SafeGoroutineWithOnceFunc() {
+	f := sync.OnceFunc(recoveringWork)
+	go f()
+}
+
+// SafeGoroutineWithOnceFuncLiteral wraps a recovering function literal
+func SafeGoroutineWithOnceFuncLiteral() {
+	go sync.OnceFunc(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	})()
+}
+
+// SafeGoroutineWithOnceValue runs a once-wrapped computation that recovers
+func SafeGoroutineWithOnceValue() {
+	compute := sync.OnceValue(recoveringCompute)
+	go compute()
+}
+
+// SafeGoroutineWithInstantiatedOnceValue instantiates sync.OnceValue explicitly
+func SafeGoroutineWithInstantiatedOnceValue() {
+	compute := sync.OnceValue[int](recoveringCompute)
+	go compute()
+}
+
+// UnsafeGoroutineWithOnceFunc runs a once-wrapped function without recovery,
+// whose panic the wrapper re-raises
+func UnsafeGoroutineWithOnceFunc() {
+	f := sync.OnceFunc(risky)
+	go f() // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineWithOnceValues runs a once-wrapped literal without recovery
+func UnsafeGoroutineWithOnceValues() {
+	load := sync.OnceValues(func() (int, error) {
+		panic("oh no")
+	})
+	go load() // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineDeferringOnceFunc defers a once-wrapped recovery handler,
+// whose recover isn't called directly by the deferred function
+func UnsafeGoroutineDeferringOnceFunc() {
+	go func() { // want "goroutine created without panic recovery"
+		handle := sync.OnceFunc(func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		})
+		defer handle()
+		panic("oh no")
+	}()
+}
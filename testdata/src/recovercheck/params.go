@@ -0,0 +1,64 @@
+package recovercheck
+
+import (
+	"log"
+	"recovercheck/pkg"
+)
+
+// handlePanicWith recovers and logs the panic with the given logger
+func handlePanicWith(logger *log.Logger) {
+	if r := recover(); r != nil {
+		logger.Println("Recovered from panic:", r)
+	}
+}
+
+// closeWith only closes; its arguments don't make it a recovery function
+func closeWith(logger *log.Logger) {
+	logger.Println("closed")
+}
+
+type reporter struct{}
+
+// recoverTo recovers and reports the panic to a named channel
+func (reporter) recoverTo(channel string, attempts int) {
+	if r := recover(); r != nil {
+		log.Println(channel, attempts, r)
+	}
+}
+
+// SafeGoroutineWithParameterizedHandler defers a recovery function with arguments
+func SafeGoroutineWithParameterizedHandler(logger *log.Logger) {
+	go func() {
+		defer handlePanicWith(logger)
+		panic("x")
+	}()
+
+	go func() {
+		defer handlePanicWith(log.New(log.Writer(), "worker: ", 0))
+		panic("x")
+	}()
+}
+
+// SafeGoroutineWithParameterizedMethod defers a recovering method with arguments
+func SafeGoroutineWithParameterizedMethod(r reporter) {
+	go func() {
+		defer r.recoverTo("alerts", 3)
+		panic("x")
+	}()
+}
+
+// SafeGoroutineWithParameterizedImport defers a recovery function of another package with arguments
+func SafeGoroutineWithParameterizedImport() {
+	go func() {
+		defer pkg.RecoverWith("worker:")
+		panic("x")
+	}()
+}
+
+// UnsafeGoroutineWithParameterizedCleanup defers a function with arguments that doesn't recover
+func UnsafeGoroutineWithParameterizedCleanup(logger *log.Logger) {
+	go func() { // want "goroutine created without panic recovery"
+		defer closeWith(logger)
+		panic("x")
+	}()
+}
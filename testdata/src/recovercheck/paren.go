@@ -0,0 +1,74 @@
+package recovercheck
+
+import "log"
+
+// Task is a named function type goroutines can be converted to
+type Task func()
+
+func recoveringJob() {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic("oh no")
+}
+
+// SafeParenthesizedLiteral starts a parenthesized function literal that recovers
+func SafeParenthesizedLiteral() {
+	go (func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	})()
+}
+
+// UnsafeParenthesizedLiteral starts a parenthesized function literal without recovery
+func UnsafeParenthesizedLiteral() {
+	go (func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	})()
+}
+
+// SafeConvertedFunction starts a recovering function converted to a named function type
+func SafeConvertedFunction() {
+	go (Task(recoveringJob))()
+}
+
+// UnsafeConvertedLiteral starts a function literal converted to a named function type
+func UnsafeConvertedLiteral() {
+	go Task(func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	})()
+}
+
+// SafeParenthesizedDeferredLiteral defers a parenthesized function literal that recovers
+func SafeParenthesizedDeferredLiteral() {
+	go func() {
+		defer (func() { recover() })()
+		panic("oh no")
+	}()
+}
+
+// SafeParenthesizedDeferredHandler defers a parenthesized function literal that logs the panic
+func SafeParenthesizedDeferredHandler() {
+	go func() {
+		defer (func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		})()
+		panic("oh no")
+	}()
+}
+
+// UnsafeParenthesizedDeferredLiteral defers a parenthesized function literal that doesn't recover
+func UnsafeParenthesizedDeferredLiteral() {
+	go func() { // want "goroutine created without panic recovery"
+		defer (func() { log.Println("done") })()
+		panic("oh no")
+	}()
+}
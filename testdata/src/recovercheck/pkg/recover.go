@@ -2,10 +2,47 @@ package pkg
 
 import "log"
 
-func PanicRecover() func() {
-	return func() {
+// PanicRecover recovers from a panic when deferred
+func PanicRecover() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+// NotRecovering only logs when deferred; it never calls recover
+func NotRecovering() {
+	log.Println("done")
+}
+
+// Run recovers from its own panics
+func Run() {
+	defer func() {
 		if r := recover(); r != nil {
 			log.Println("Recovered from panic:", r)
 		}
+	}()
+	panic("oh no")
+}
+
+// RunUnsafe has no panic recovery
+func RunUnsafe() {
+	panic("oh no")
+}
+
+// RecoverWith recovers from a panic when deferred, logging it with the
+// given prefix
+func RecoverWith(prefix string) {
+	if r := recover(); r != nil {
+		log.Println(prefix, r)
 	}
 }
+
+// RunGeneric recovers from its own panics, for any type of value
+func RunGeneric[T any](v T) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	panic(v)
+}
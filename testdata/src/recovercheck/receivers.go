@@ -0,0 +1,50 @@
+package recovercheck
+
+import "log"
+
+type svc struct{}
+
+// recover2 recovers from panics in the goroutines of svc
+func (s *svc) recover2() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+type client struct{}
+
+// recover2 only logs, it doesn't recover
+func (c client) recover2() {
+	log.Println("done")
+}
+
+// recover2 shares its name with the methods and doesn't recover either
+func recover2() {
+	log.Println("done")
+}
+
+// SafeGoroutineDeferredMethod defers the recovering method of svc
+func SafeGoroutineDeferredMethod() {
+	s := &svc{}
+	go func() {
+		defer s.recover2()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineDeferredSameNamedMethod defers the non-recovering method of client
+func UnsafeGoroutineDeferredSameNamedMethod() {
+	var c client
+	go func() { // want "goroutine created without panic recovery"
+		defer c.recover2()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineDeferredSameNamedFunction defers the non-recovering function
+func UnsafeGoroutineDeferredSameNamedFunction() {
+	go func() { // want "goroutine created without panic recovery"
+		defer recover2()
+		panic("oh no")
+	}()
+}
@@ -44,17 +44,34 @@ func SafeGoroutine2() {
 	}()
 }
 
-// SafeGoroutine3 uses a recovery function defined in the same file
+// SafeGoroutine3 defers the closure returned by a recovery function defined in the same file
 func SafeGoroutine3() {
 	go func() {
-		defer sameFileRecover()
+		defer sameFileRecover()()
 		panic("oh no")
 	}()
 }
 
-// SafeGoroutine4 uses a recovery function with any name
+// SafeGoroutine4 defers the closure returned by a recovery function with any name
 func SafeGoroutine4() {
 	go func() {
+		defer anyName()()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineDeferringRecoveryFactory only defers the call that builds the
+// recovering closure, so recover is never called while panicking
+func UnsafeGoroutineDeferringRecoveryFactory() {
+	go func() { // want "goroutine created without panic recovery"
+		defer sameFileRecover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineDeferringAnyNameFactory makes the same mistake with a differently named factory
+func UnsafeGoroutineDeferringAnyNameFactory() {
+	go func() { // want "goroutine created without panic recovery"
 		defer anyName()
 		panic("oh no")
 	}()
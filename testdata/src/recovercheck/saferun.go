@@ -0,0 +1,65 @@
+package recovercheck
+
+import "log"
+
+func risky() {
+	panic("oh no")
+}
+
+// SafeRun runs f, recovering from any panic
+func SafeRun(f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	f()
+}
+
+// SafeRunWith runs f with v, recovering from any panic
+func SafeRunWith[T any](v T, f func(T)) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("Recovered from panic:", r)
+		}
+	}()
+	f(v)
+}
+
+// UnsafeRun runs f without recovery
+func UnsafeRun(f func()) {
+	f()
+}
+
+// UnsafeRunWith runs f with v without recovery
+func UnsafeRunWith[T any](v T, f func(T)) {
+	f(v)
+}
+
+// SafeGoroutineViaSafeRun delegates to a recovering wrapper
+func SafeGoroutineViaSafeRun() {
+	go SafeRun(func() { risky() })
+}
+
+// SafeGoroutineViaGenericSafeRun delegates to a recovering generic wrapper
+func SafeGoroutineViaGenericSafeRun() {
+	go SafeRunWith(1, func(int) { risky() })
+	go SafeRunWith[string]("job", func(string) { risky() })
+}
+
+// UnsafeGoroutineViaUnsafeRun delegates to a wrapper without recovery
+func UnsafeGoroutineViaUnsafeRun() {
+	go UnsafeRun(func() { risky() }) // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineViaGenericUnsafeRun delegates to a generic wrapper without recovery
+func UnsafeGoroutineViaGenericUnsafeRun() {
+	go UnsafeRunWith(1, func(int) { risky() })                // want "goroutine created without panic recovery"
+	go UnsafeRunWith[string]("job", func(string) { risky() }) // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineFromFuncSlice indexes a slice of functions, which is not an instantiation
+func UnsafeGoroutineFromFuncSlice() {
+	jobs := []func(){risky}
+	go jobs[0]() // want `goroutine created without panic recovery \(may run risky, which has no recovery\)`
+}
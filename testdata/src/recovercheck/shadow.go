@@ -0,0 +1,46 @@
+package recovercheck
+
+import "log"
+
+// noopRecover looks like a recovery function but never calls the builtin
+func noopRecover() interface{} {
+	return nil
+}
+
+// logRecover calls the builtin recover
+func logRecover() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+// UnsafeGoroutineWithShadowedRecover defers a local recover that doesn't call the builtin
+func UnsafeGoroutineWithShadowedRecover() {
+	go func() { // want "goroutine created without panic recovery"
+		recover := noopRecover
+		defer recover()
+		panic("oh no")
+	}()
+}
+
+// SafeGoroutineWithShadowedRecover defers a local recover bound to a real recovery function
+func SafeGoroutineWithShadowedRecover() {
+	go func() {
+		recover := logRecover
+		defer recover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineWithShadowedRecoverInDefer calls a local recover inside its deferred function
+func UnsafeGoroutineWithShadowedRecoverInDefer() {
+	go func() { // want "goroutine created without panic recovery"
+		defer func() {
+			recover := func() interface{} { return nil }
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,47 @@
+package recovercheck
+
+import (
+	"errors"
+	"log"
+)
+
+// SafeGoroutineWithTypeSwitch recovers in the tag of a type switch
+func SafeGoroutineWithTypeSwitch() {
+	go func() {
+		defer func() {
+			switch r := recover().(type) {
+			case error:
+				log.Println("Recovered from error:", r)
+			case nil:
+			default:
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic(errors.New("x"))
+	}()
+}
+
+// SafeGoroutineWithUnboundTypeSwitch recovers in a type switch without a binding
+func SafeGoroutineWithUnboundTypeSwitch() {
+	go func() {
+		defer func() {
+			switch recover().(type) {
+			case error:
+				log.Println("Recovered from an error")
+			}
+		}()
+		panic(errors.New("x"))
+	}()
+}
+
+// SafeGoroutineWithTypeAssertion recovers in a comma-ok type assertion
+func SafeGoroutineWithTypeAssertion() {
+	go func() {
+		defer func() {
+			if err, ok := recover().(error); ok {
+				log.Println("Recovered from error:", err)
+			}
+		}()
+		panic(errors.New("x"))
+	}()
+}
@@ -0,0 +1,75 @@
+package recovercheck
+
+import "log"
+
+// UnsafeRecoverAfterReturn returns before its recover() can run
+func UnsafeRecoverAfterReturn() {
+	go func() { // want "goroutine created without panic recovery"
+		defer func() {
+			return
+			recover()
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeRecoverAfterPanic panics in the deferred function before recovering
+func UnsafeRecoverAfterPanic() {
+	go func() { // want "goroutine created without panic recovery"
+		defer func() {
+			panic("cleanup failed")
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeDeferAfterReturn registers its recovery after returning
+func UnsafeDeferAfterReturn() {
+	go func() { // want "goroutine created without panic recovery"
+		return
+		defer func() {
+			recover()
+		}()
+	}()
+}
+
+// UnsafeNamedRecoverAfterReturn defers a function whose recover() is dead code
+func UnsafeNamedRecoverAfterReturn() {
+	go func() { // want "goroutine created without panic recovery"
+		defer recoverAfterReturn()
+		panic("oh no")
+	}()
+}
+
+func recoverAfterReturn() {
+	return
+	recover()
+}
+
+// SafeRecoverAfterConditionalReturn only returns early in a nested block
+func SafeRecoverAfterConditionalReturn() {
+	go func() {
+		defer func() {
+			if false {
+				return
+			}
+			recover()
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeRecoverAtLabel can reach its recover() through the goto
+func SafeRecoverAtLabel() {
+	go func() {
+		defer func() {
+			goto handle
+		handle:
+			recover()
+		}()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"log"
+
+	"github.com/panjf2000/ants/v2"
+	"github.com/sourcegraph/conc/pool"
+	"sched"
+)
+
+func handlePanic() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+func done() {}
+
+// SubmitToAnts hands tasks to an ants pool
+func SubmitToAnts(p *ants.Pool) {
+	_ = p.Submit(func() {
+		defer handlePanic()
+		panic("oh no")
+	})
+
+	_ = p.Submit(func() { // want "goroutine spawned by github.com/panjf2000/ants/v2.Pool.Submit without panic recovery"
+		panic("oh no")
+	})
+}
+
+// GoOnConcPool hands tasks to a conc pool
+func GoOnConcPool() {
+	p := pool.New()
+	p.Go(func() {
+		defer handlePanic()
+		panic("oh no")
+	})
+
+	p.Go(func() { // want "goroutine spawned by github.com/sourcegraph/conc/pool.Pool.Go without panic recovery"
+		panic("oh no")
+	})
+}
+
+// RunWithCallback hands a task to a spawn function whose goroutine runs its
+// second argument, configured as sched.Run:1
+func RunWithCallback() {
+	sched.Run(done, func() {
+		defer handlePanic()
+		panic("oh no")
+	})
+
+	sched.Run(handlePanic, func() { // want "goroutine spawned by sched.Run without panic recovery"
+		panic("oh no")
+	})
+}
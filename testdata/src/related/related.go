@@ -0,0 +1,30 @@
+package related
+
+// Start runs work in an unrecovered goroutine
+func Start() {
+	go work() // want "goroutine created without panic recovery"
+}
+
+type server struct{}
+
+// Serve starts goroutines from a function literal
+func (s *server) Serve() {
+	go func() { // want "goroutine created without panic recovery"
+		go work() // want "nested goroutine created without panic recovery inside an unrecovered goroutine"
+	}()
+}
+
+type worker struct{}
+
+func (w worker) Run() {
+	go work() // want "goroutine created without panic recovery"
+}
+
+// handler is a package-level function value
+var handler = func() {
+	go work() // want "goroutine created without panic recovery"
+}
+
+func work() {
+	panic("oh no")
+}
@@ -0,0 +1,187 @@
+package repanic
+
+import (
+	"errors"
+	"log"
+)
+
+var errExpected = errors.New("expected")
+
+// UnsafeRepanicInIf recovers only to panic again
+func UnsafeRepanicInIf() {
+	go func() { // want "recover immediately re-panics"
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+				panic(r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeRepanicAfterAssign re-panics the recovered value unconditionally
+func UnsafeRepanicAfterAssign() {
+	go func() { // want "recover immediately re-panics"
+		defer func() {
+			r := recover()
+			panic(r)
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeRepanicRecoverCall panics with the result of recover directly
+func UnsafeRepanicRecoverCall() {
+	go func() { // want "recover immediately re-panics"
+		defer func() {
+			panic(recover())
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeFilteredRepanic only re-panics values it doesn't expect
+func SafeFilteredRepanic() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				if err, ok := r.(error); ok && errors.Is(err, errExpected) {
+					log.Println("Recovered from expected panic:", err)
+					return
+				}
+				panic(r)
+			}
+		}()
+		panic(errExpected)
+	}()
+}
+
+// SafeConditionalRepanic re-panics only behind an extra condition
+func SafeConditionalRepanic() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil && r != errExpected {
+				panic(r)
+			}
+		}()
+		panic(errExpected)
+	}()
+}
+
+// SafeRecoverWithoutRepanic handles the recovered value
+func SafeRecoverWithoutRepanic() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeReturnBeforeRepanic returns for expected values before re-panicking
+func SafeReturnBeforeRepanic() {
+	go func() {
+		defer func() {
+			r := recover()
+			if r == errExpected {
+				return
+			}
+			panic(r)
+		}()
+		panic(errExpected)
+	}()
+}
+
+// rethrow panics again with the value it is given
+func rethrow(r any) {
+	log.Println("Rethrowing panic:", r)
+	panic(r)
+}
+
+// rethrowUnexpected only panics for values other than errExpected
+func rethrowUnexpected(r any) {
+	if r == errExpected {
+		return
+	}
+	panic(r)
+}
+
+type handler struct{}
+
+// fail panics with the value it is given
+func (handler) fail(r any) {
+	panic(r)
+}
+
+// UnsafeRethrowHelper recovers only to rethrow through a helper
+func UnsafeRethrowHelper() {
+	go func() { // want "recovered panic is rethrown via rethrow"
+		defer func() {
+			if r := recover(); r != nil {
+				rethrow(r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeRethrowRecoverCall passes the recovered value straight to the helper
+func UnsafeRethrowRecoverCall() {
+	go func() { // want "recovered panic is rethrown via rethrow"
+		defer func() {
+			rethrow(recover())
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeRethrowMethod rethrows through a method of the package
+func UnsafeRethrowMethod(h handler) {
+	go func() { // want `recovered panic is rethrown via handler\.fail`
+		defer func() {
+			r := recover()
+			h.fail(r)
+		}()
+		panic("oh no")
+	}()
+}
+
+// SafeFilteredRethrow only rethrows unexpected values
+func SafeFilteredRethrow() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				rethrowUnexpected(r)
+			}
+		}()
+		panic(errExpected)
+	}()
+}
+
+// UnreportedExternalRethrow re-panics through log.Panicln, but functions of
+// other packages aren't followed
+func UnreportedExternalRethrow() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Panicln("unexpected panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeParenthesizedRepanic re-panics from a parenthesized deferred literal
+func UnsafeParenthesizedRepanic() {
+	go func() { // want "recover immediately re-panics"
+		defer (func() {
+			if r := recover(); r != nil {
+				panic(r)
+			}
+		})()
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,29 @@
+package reportat
+
+// Task is a named function type goroutines can be converted to
+type Task func()
+
+func work() {
+	panic("oh no")
+}
+
+// Literal starts a function literal
+func Literal() {
+	go func() {
+		work()
+	}()
+}
+
+// ConvertedLiteral starts a function literal whose body opens on a later line
+func ConvertedLiteral() {
+	go Task(
+		func() {
+			work()
+		},
+	)()
+}
+
+// Named starts a named function, reported at the go keyword either way
+func Named() {
+	go work()
+}
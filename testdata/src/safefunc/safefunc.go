@@ -0,0 +1,65 @@
+package safefunc
+
+import (
+	"example.com/safelib"
+)
+
+func work() {
+	panic("oh no")
+}
+
+// SafeGoroutineRunningSafeFunc runs a function trusted to recover
+func SafeGoroutineRunningSafeFunc() {
+	go safelib.Run(func() {
+		panic("oh no")
+	})
+}
+
+// SafeGoroutineDeferringSafeFunc defers a function trusted to recover
+func SafeGoroutineDeferringSafeFunc() {
+	go func() {
+		defer safelib.Recover()
+		work()
+	}()
+}
+
+// SafeGoroutineDeferringSafeMethod defers a method trusted to recover
+func SafeGoroutineDeferringSafeMethod(g *safelib.Guard) {
+	go func() {
+		defer g.Recover()
+		work()
+	}()
+}
+
+// SafeGoroutineWithHelper runs a function of this package that defers a
+// function trusted to recover
+func SafeGoroutineWithHelper() {
+	go recovering()
+}
+
+func recovering() {
+	defer safelib.Recover()
+	work()
+}
+
+// UnsafeGoroutineRunningOtherFunc runs a function of the same library that isn't trusted
+func UnsafeGoroutineRunningOtherFunc() {
+	go safelib.Go(work) // want "goroutine created without panic recovery"
+}
+
+// UnsafeGoroutineDeferringOtherMethod defers a method that isn't trusted
+func UnsafeGoroutineDeferringOtherMethod(g *safelib.Guard) {
+	go func() { // want "goroutine created without panic recovery"
+		defer g.Close()
+		work()
+	}()
+}
+
+// UnsafeGoroutineCallingSafeFunc calls a trusted function without deferring
+// it, which recovers nothing
+func UnsafeGoroutineCallingSafeFunc() {
+	go func() { // want "goroutine created without panic recovery"
+		safelib.Recover()
+		work()
+	}()
+}
@@ -0,0 +1,8 @@
+package safego
+
+// Run calls fn, reporting any panic to the crash handler installed by the
+// program. That recovery happens outside this package, so recovercheck
+// can't see it.
+func Run(fn func()) {
+	fn()
+}
@@ -0,0 +1,9 @@
+package sched
+
+// Run runs fn in a new goroutine and calls onDone once it returns
+func Run(onDone func(), fn func()) {
+	go func() {
+		fn()
+		onDone()
+	}()
+}
@@ -0,0 +1,99 @@
+package selectloop
+
+import (
+	"context"
+	"time"
+)
+
+func process(int) {}
+
+// WorkerLoop runs a for-select loop that stops when ctx is done
+func WorkerLoop(ctx context.Context, ch <-chan int) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case x := <-ch:
+				process(x)
+			}
+		}
+	}()
+}
+
+// TickerLoop sets up a ticker before looping, which isn't part of the loop
+func TickerLoop(ctx context.Context) {
+	go func() { // want "goroutine created without panic recovery"
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				process(0)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// DeferredTickerLoop has only defers before its loop
+func DeferredTickerLoop(ctx context.Context, done func()) {
+	go func() {
+		defer done()
+		for {
+			select {
+			case _, ok := <-ctx.Done():
+				_ = ok
+				return
+			default:
+				process(0)
+			}
+		}
+	}()
+}
+
+func worker(ctx context.Context, ch <-chan int) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case x := <-ch:
+			process(x)
+		}
+	}
+}
+
+// NamedWorkerLoop runs a declared function that is a for-select loop
+func NamedWorkerLoop(ctx context.Context, ch <-chan int) {
+	go worker(ctx, ch)
+}
+
+// LoopWithoutContext selects without a ctx.Done() case
+func LoopWithoutContext(ch <-chan int, quit <-chan struct{}) {
+	go func() { // want "goroutine created without panic recovery"
+		for {
+			select {
+			case <-quit:
+				return
+			case x := <-ch:
+				process(x)
+			}
+		}
+	}()
+}
+
+// LoopWithWork does work outside the select
+func LoopWithWork(ctx context.Context, ch <-chan int) {
+	go func() { // want "goroutine created without panic recovery"
+		for {
+			process(0)
+			select {
+			case <-ctx.Done():
+				return
+			case x := <-ch:
+				process(x)
+			}
+		}
+	}()
+}
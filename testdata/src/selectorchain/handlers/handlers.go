@@ -0,0 +1,24 @@
+package handlers
+
+import "log"
+
+// Recoverer groups the package's panic handlers
+type Recoverer struct{}
+
+// Recover logs and recovers from a panic
+func (Recoverer) Recover() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+// Cleanup releases resources without recovering
+func (Recoverer) Cleanup() {}
+
+// Registry holds the handlers of the package
+type Registry struct {
+	Panics Recoverer
+}
+
+// Default is the registry used by the package-level helpers
+var Default = Registry{}
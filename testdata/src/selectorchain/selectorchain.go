@@ -0,0 +1,36 @@
+package selectorchain
+
+import "selectorchain/handlers"
+
+type service struct {
+	handlers handlers.Registry
+}
+
+// SafeTwoLevelSelector defers a recovery method reached through a package variable
+func SafeTwoLevelSelector() {
+	go func() {
+		defer handlers.Default.Panics.Recover()
+		panic("oh no")
+	}()
+}
+
+// SafeFieldSelector defers a recovery method reached through nested fields
+func (s *service) SafeFieldSelector() {
+	go func() {
+		defer s.handlers.Panics.Recover()
+		panic("oh no")
+	}()
+}
+
+// UnsafeTwoLevelSelector defers a non-recovering method reached through a package variable
+func UnsafeTwoLevelSelector() {
+	go func() { // want "goroutine created without panic recovery"
+		defer handlers.Default.Panics.Cleanup()
+		panic("oh no")
+	}()
+}
+
+// SafeTwoLevelSelectorGoroutine runs a method that recovers, reached through a package variable
+func SafeTwoLevelSelectorGoroutine() {
+	go handlers.Default.Panics.Recover()
+}
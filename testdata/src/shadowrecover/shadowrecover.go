@@ -0,0 +1,28 @@
+package shadowrecover
+
+import "log"
+
+// recover shadows the builtin for the whole package
+func recover() interface{} {
+	return nil
+}
+
+// UnsafeGoroutineWithPackageRecover calls the package's recover, not the builtin
+func UnsafeGoroutineWithPackageRecover() {
+	go func() { // want "goroutine created without panic recovery"
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeGoroutineDeferringPackageRecover defers the package's recover directly
+func UnsafeGoroutineDeferringPackageRecover() {
+	go func() { // want "goroutine created without panic recovery"
+		defer recover()
+		panic("oh no")
+	}()
+}
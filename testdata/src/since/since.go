@@ -0,0 +1,22 @@
+package since
+
+// OldGoroutine was last changed long ago, so it is suppressed
+func OldGoroutine() {
+	go func() {
+		panic("oh no")
+	}()
+}
+
+// RecentGoroutine was changed recently, so it is reported
+func RecentGoroutine() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
+
+// UnknownGoroutine has no blame information, so it is reported
+func UnknownGoroutine() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,12 @@
+// Package skipgenerated mentions "Code generated ... DO NOT EDIT." outside
+// of a header, which doesn't mark it as generated.
+package skipgenerated
+
+// Start runs work in an unrecovered goroutine
+func Start() {
+	go work() // want "goroutine created without panic recovery"
+}
+
+func work() {
+	panic("oh no")
+}
@@ -0,0 +1,8 @@
+package skiptests
+
+// UnsafeGoroutine is reported because it is not in a test file
+func UnsafeGoroutine() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,10 @@
+package skiptests
+
+import "testing"
+
+// TestUnsafeGoroutine is not reported because SkipTestFiles is set
+func TestUnsafeGoroutine(t *testing.T) {
+	go func() {
+		panic("oh no")
+	}()
+}
@@ -0,0 +1,63 @@
+package spawn
+
+import (
+	"log"
+
+	"pool"
+)
+
+func handlePanic() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+func recoveringTask() {
+	defer handlePanic()
+	panic("oh no")
+}
+
+// runner has a Go method that is not a registered spawn function
+type runner struct{}
+
+func (runner) Go(fn func()) {
+	fn()
+}
+
+// SpawnWithPoolMethod hands tasks to a registered generic method
+func SpawnWithPoolMethod() {
+	var p pool.Pool[int]
+
+	p.Go(func() {
+		defer handlePanic()
+		panic("oh no")
+	})
+	p.Go(recoveringTask)
+
+	p.Go(func() { // want "goroutine spawned by pool.Pool.Go without panic recovery"
+		panic("oh no")
+	})
+}
+
+// SpawnWithPoolFunc hands tasks to a registered generic function
+func SpawnWithPoolFunc() {
+	pool.Go(1, func(int) {
+		defer handlePanic()
+		panic("oh no")
+	})
+
+	pool.Go(1, func(int) { // want "goroutine spawned by pool.Go without panic recovery"
+		panic("oh no")
+	})
+	pool.Go[string]("job", func(string) { // want "goroutine spawned by pool.Go without panic recovery"
+		panic("oh no")
+	})
+}
+
+// UnregisteredGoMethod calls a Go method on an unregistered type
+func UnregisteredGoMethod() {
+	var r runner
+	r.Go(func() {
+		panic("oh no")
+	})
+}
@@ -0,0 +1,34 @@
+package main
+
+import "log"
+
+type server struct{}
+
+// main is a method here, not the program's entry point
+func (server) main() {
+	go work() // want "^goroutine created without panic recovery$"
+}
+
+func start() {
+	go work() // want "^goroutine created without panic recovery$"
+}
+
+func work() {
+	panic("oh no")
+}
+
+func main() {
+	go work() // want "^goroutine created without panic recovery in main; a panic crashes the program$"
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+
+	start()
+	server{}.main()
+}
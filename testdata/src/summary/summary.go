@@ -0,0 +1,26 @@
+package summary
+
+import "golang.org/x/sync/errgroup"
+
+func UnsafeGoroutine() {
+	go func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	}()
+}
+
+func UnsafeErrgroup() {
+	var g errgroup.Group
+	g.Go(func() error { // want "errgroup goroutine created without panic recovery"
+		panic("oh no")
+	})
+	g.Wait()
+}
+
+func SafeGoroutine() {
+	go func() {
+		defer func() {
+			recover()
+		}()
+		panic("oh no")
+	}()
+}
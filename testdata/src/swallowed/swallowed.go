@@ -0,0 +1,75 @@
+package swallowed
+
+import "log"
+
+// BlankRecover discards the recovered value
+func BlankRecover() {
+	go func() { // want "note: goroutine recovery swallows the recovered value"
+		defer func() {
+			_ = recover()
+		}()
+		panic("oh no")
+	}()
+}
+
+// DiscardedVariable binds the recovered value only to discard it
+func DiscardedVariable() {
+	go func() { // want "note: goroutine recovery swallows the recovered value"
+		defer func() {
+			r := recover()
+			_ = r
+		}()
+		panic("oh no")
+	}()
+}
+
+// DiscardedAssertion discards the asserted recovered value
+func DiscardedAssertion() {
+	go func() { // want "note: goroutine recovery swallows the recovered value"
+		defer func() {
+			err, _ := recover().(error)
+			_ = err
+		}()
+		panic("oh no")
+	}()
+}
+
+// LoggedRecover uses the recovered value
+func LoggedRecover() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// ReadInNestedLiteral reads the recovered value in a nested function literal
+func ReadInNestedLiteral() {
+	go func() {
+		defer func() {
+			r := recover()
+			func() {
+				log.Println("Recovered from panic:", r)
+			}()
+		}()
+		panic("oh no")
+	}()
+}
+
+// OuterVariable stores the recovered value in a variable read after the
+// deferred function returns
+func OuterVariable(done chan<- any) {
+	go func() {
+		var recovered any
+		defer func() {
+			done <- recovered
+		}()
+		defer func() {
+			recovered = recover()
+		}()
+		panic("oh no")
+	}()
+}
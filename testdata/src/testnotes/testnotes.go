@@ -0,0 +1,10 @@
+package testnotes
+
+func work() {
+	panic("oh no")
+}
+
+// Start is production code, so its goroutine stays an error
+func Start() {
+	go work() // want "^goroutine created without panic recovery$"
+}
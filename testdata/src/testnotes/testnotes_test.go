@@ -0,0 +1,26 @@
+package testnotes
+
+import "testing"
+
+func TestWork(t *testing.T) {
+	go work() // want `^note: goroutine created without panic recovery \(in test: a panic fails the test instead of crashing the process\)$`
+
+	t.Run("subtest", func(t *testing.T) {
+		go work() // want `^note: goroutine created without panic recovery`
+	})
+}
+
+// startWorkers is a helper without a *testing.T, like production code
+func startWorkers() {
+	go work() // want "^goroutine created without panic recovery$"
+}
+
+func TestHelper(t *testing.T) {
+	startWorkers()
+
+	// A literal without a *testing.T of its own isn't a test function
+	run := func() {
+		go work() // want "^goroutine created without panic recovery$"
+	}
+	run()
+}
@@ -0,0 +1,91 @@
+package trivialbodies
+
+type counter struct {
+	n int
+}
+
+// SendOnly signals completion and does nothing else
+func SendOnly(done chan<- struct{}) {
+	go func() {
+		done <- struct{}{}
+	}()
+}
+
+// Arithmetic only computes with local values before sending the result
+func Arithmetic(results chan<- int, a, b int) {
+	go func() {
+		sum := a + b
+		sum *= 2
+		sum /= 2
+		sum = sum << 1
+		if sum > 10 {
+			sum = 10
+		}
+		results <- sum
+	}()
+}
+
+// ValueField reads a field of a struct value, which can't be nil
+func ValueField(results chan<- int, c counter) {
+	go func() {
+		results <- c.n
+	}()
+}
+
+// Call calls a function, which may panic
+func Call(f func()) {
+	go func() { // want "goroutine created without panic recovery"
+		f()
+	}()
+}
+
+// Index indexes a slice, which may be out of range
+func Index(results chan<- int, values []int, i int) {
+	go func() { // want "goroutine created without panic recovery"
+		results <- values[i]
+	}()
+}
+
+// MapWrite writes to a map, which may be nil
+func MapWrite(m map[string]int) {
+	go func() { // want "goroutine created without panic recovery"
+		m["key"] = 1
+	}()
+}
+
+// TypeAssertion asserts a type, which may not match
+func TypeAssertion(results chan<- int, v any) {
+	go func() { // want "goroutine created without panic recovery"
+		results <- v.(int)
+	}()
+}
+
+// Division divides by a variable, which may be zero
+func Division(results chan<- int, a, b int) {
+	go func() { // want "goroutine created without panic recovery"
+		results <- a / b
+	}()
+}
+
+// PointerField increments a field through a pointer, which may be nil
+func PointerField(c *counter) {
+	go func() { // want "goroutine created without panic recovery"
+		c.n++
+	}()
+}
+
+// Dereference dereferences a pointer, which may be nil
+func Dereference(results chan<- int, p *int) {
+	go func() { // want "goroutine created without panic recovery"
+		results <- *p
+	}()
+}
+
+// NamedFunction runs a named function, whose body isn't inspected
+func NamedFunction(done chan<- struct{}) {
+	go signal(done) // want "goroutine created without panic recovery"
+}
+
+func signal(done chan<- struct{}) {
+	done <- struct{}{}
+}
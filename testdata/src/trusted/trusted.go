@@ -0,0 +1,40 @@
+package trusted
+
+import (
+	"pool"
+	"safego"
+)
+
+func run(fn func()) {
+	fn()
+}
+
+// SafeTrustedSpawner starts a goroutine through a trusted spawner
+func SafeTrustedSpawner() {
+	go safego.Run(func() {
+		panic("oh no")
+	})
+}
+
+// SafeTrustedSpawnFunc calls a spawn function that is also trusted, so its
+// callback need not recover
+func SafeTrustedSpawnFunc() {
+	pool.Go(1, func(int) {
+		panic("oh no")
+	})
+}
+
+// UnsafeUntrustedSpawner starts a goroutine through a function that isn't trusted
+func UnsafeUntrustedSpawner() {
+	go run(func() { // want "goroutine created without panic recovery"
+		panic("oh no")
+	})
+}
+
+// UnsafeSpawnFunc hands a non-recovering callback to a spawn function that isn't trusted
+func UnsafeSpawnFunc() {
+	var p pool.Pool[int]
+	p.Go(func() { // want "goroutine spawned by pool.Pool.Go without panic recovery"
+		panic("oh no")
+	})
+}
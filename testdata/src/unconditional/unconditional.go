@@ -0,0 +1,69 @@
+package unconditional
+
+import "log"
+
+var debug bool
+
+func recoverHandler() {
+	if r := recover(); r != nil {
+		log.Println("Recovered from panic:", r)
+	}
+}
+
+// SafeTopLevelDefer registers recovery unconditionally
+func SafeTopLevelDefer() {
+	go func() {
+		defer recoverHandler()
+		panic("oh no")
+	}()
+}
+
+// SafeTopLevelDeferFuncLit registers an inline recovery unconditionally
+func SafeTopLevelDeferFuncLit() {
+	go func() {
+		if debug {
+			log.Println("starting")
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println("Recovered from panic:", r)
+			}
+		}()
+		panic("oh no")
+	}()
+}
+
+// UnsafeDeferInIf only registers recovery in debug builds
+func UnsafeDeferInIf() {
+	go func() { // want "goroutine recovery is deferred conditionally and may never be registered"
+		if debug {
+			defer recoverHandler()
+		}
+		panic("oh no")
+	}()
+}
+
+// UnsafeDeferInLoop only registers recovery if the loop body runs
+func UnsafeDeferInLoop(items []int) {
+	go func() { // want "goroutine recovery is deferred conditionally and may never be registered"
+		for range items {
+			defer func() {
+				recover()
+			}()
+		}
+		panic("oh no")
+	}()
+}
+
+// worker only registers recovery in debug builds
+func worker() {
+	if debug {
+		defer recoverHandler()
+	}
+	panic("oh no")
+}
+
+// UnsafeNamedWorker runs a named function whose recovery is conditional
+func UnsafeNamedWorker() {
+	go worker() // want "goroutine created without panic recovery"
+}
@@ -0,0 +1,125 @@
+package recovercheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+)
+
+// isTrivialBody checks if a goroutine runs a function literal whose body has
+// no operation that can panic, see canPanic. Named functions aren't
+// inspected.
+func (r *Analyzer) isTrivialBody(fn ast.Expr) bool {
+	funcLit, ok := r.funcValue(fn).(*ast.FuncLit)
+	return ok && !r.canPanic(funcLit.Body)
+}
+
+// canPanic conservatively checks if a function body may panic. Calls,
+// including conversions and builtins, index and slice expressions, which
+// cover map writes, type assertions, pointer dereferences, field accesses
+// through a pointer, integer divisions by a divisor that isn't a non-zero
+// constant and shifts by a count that isn't constant all may panic.
+// Assignments, arithmetic, comparisons, channel receives and sends do not,
+// though a send on a closed channel panics. Without type information every
+// selector but a package-qualified one is assumed to dereference a pointer.
+func (r *Analyzer) canPanic(body *ast.BlockStmt) bool {
+	panics := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Only run when called, and calls may panic anyway
+			return false
+		case ast.Expr:
+			if r.isTypeExpr(n) {
+				// Such as *T or List[int] in a declaration
+				return false
+			}
+		}
+
+		switch n := n.(type) {
+		case *ast.CallExpr, *ast.IndexExpr, *ast.IndexListExpr, *ast.SliceExpr, *ast.StarExpr:
+			panics = true
+		case *ast.TypeAssertExpr:
+			// x.(type) in a type switch doesn't panic
+			panics = n.Type != nil
+		case *ast.SelectorExpr:
+			panics = r.mayDereference(n)
+		case *ast.BinaryExpr:
+			panics = r.mayPanicArithmetic(n.Op, n.X, n.Y)
+		case *ast.AssignStmt:
+			if len(n.Lhs) == 1 && len(n.Rhs) == 1 {
+				switch n.Tok {
+				case token.QUO_ASSIGN:
+					panics = r.mayPanicArithmetic(token.QUO, n.Lhs[0], n.Rhs[0])
+				case token.REM_ASSIGN:
+					panics = r.mayPanicArithmetic(token.REM, n.Lhs[0], n.Rhs[0])
+				case token.SHL_ASSIGN, token.SHR_ASSIGN:
+					panics = r.mayPanicArithmetic(token.SHL, n.Lhs[0], n.Rhs[0])
+				}
+			}
+		}
+		return !panics
+	})
+	return panics
+}
+
+// isTypeExpr checks if expr denotes a type, which is never evaluated
+func (r *Analyzer) isTypeExpr(expr ast.Expr) bool {
+	if r.Pass == nil || r.Pass.TypesInfo == nil {
+		return false
+	}
+	tv, ok := r.Pass.TypesInfo.Types[expr]
+	return ok && tv.IsType()
+}
+
+// mayDereference checks if evaluating a selector may dereference a nil
+// pointer: a field or method selected through a pointer. Package-qualified
+// identifiers are safe.
+func (r *Analyzer) mayDereference(sel *ast.SelectorExpr) bool {
+	if r.Pass == nil || r.Pass.TypesInfo == nil {
+		x, ok := sel.X.(*ast.Ident)
+		return !ok || !r.isImportName(x.Name)
+	}
+	selection, ok := r.Pass.TypesInfo.Selections[sel]
+	if !ok {
+		// A qualified identifier such as pkg.Value
+		return false
+	}
+	return selection.Indirect()
+}
+
+// mayPanicArithmetic checks if the binary operation x op y may panic: an
+// integer division or remainder by a divisor that isn't a non-zero
+// constant, or a shift by a count that isn't constant
+func (r *Analyzer) mayPanicArithmetic(op token.Token, x, y ast.Expr) bool {
+	switch op {
+	case token.QUO, token.REM:
+		if r.Pass != nil && r.Pass.TypesInfo != nil {
+			if t := r.Pass.TypesInfo.TypeOf(x); t != nil {
+				if basic, ok := t.Underlying().(*types.Basic); ok && basic.Info()&types.IsInteger == 0 {
+					return false
+				}
+			}
+		}
+		divisor := r.constantValue(y)
+		return divisor == nil || constant.Sign(divisor) == 0
+	case token.SHL, token.SHR:
+		return r.constantValue(y) == nil
+	}
+	return false
+}
+
+// constantValue returns the value of a constant expression, or nil
+func (r *Analyzer) constantValue(expr ast.Expr) constant.Value {
+	if r.Pass != nil && r.Pass.TypesInfo != nil {
+		return r.Pass.TypesInfo.Types[expr].Value
+	}
+	if lit, ok := expr.(*ast.BasicLit); ok {
+		value := constant.MakeFromLiteral(lit.Value, lit.Kind, 0)
+		if value.Kind() != constant.Unknown {
+			return value
+		}
+	}
+	return nil
+}